@@ -0,0 +1,281 @@
+// Package block implements block header parsing, proof of work and merkle
+// root validation.
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/miguelhun/programmingbitcoin-go/tx"
+)
+
+const TWO_WEEKS = 60 * 60 * 24 * 14
+
+// Block is a block header
+type Block struct {
+	version       uint32
+	previousBlock [32]byte
+	merkleRoot    [32]byte
+	timestamp     uint32
+	bits          [4]byte
+	nonce         [4]byte
+	txHashes      [][]byte
+}
+
+// NewBlock returns a block header. txHashes are the hashes of the
+// transactions in the block, used to validate the merkle root
+func NewBlock(version uint32, previousBlock, merkleRoot [32]byte, timestamp uint32, bits, nonce [4]byte, txHashes [][]byte) *Block {
+	return &Block{
+		version:       version,
+		previousBlock: previousBlock,
+		merkleRoot:    merkleRoot,
+		timestamp:     timestamp,
+		bits:          bits,
+		nonce:         nonce,
+		txHashes:      txHashes,
+	}
+}
+
+func (b Block) Version() uint32 {
+	return b.version
+}
+
+func (b Block) PreviousBlock() [32]byte {
+	return b.previousBlock
+}
+
+func (b Block) MerkleRoot() [32]byte {
+	return b.merkleRoot
+}
+
+func (b Block) Timestamp() uint32 {
+	return b.timestamp
+}
+
+func (b Block) Bits() [4]byte {
+	return b.bits
+}
+
+func (b Block) Nonce() [4]byte {
+	return b.nonce
+}
+
+// SetTxHashes sets the transaction hashes used by ValidateMerkleRoot
+func (b *Block) SetTxHashes(txHashes [][]byte) {
+	b.txHashes = txHashes
+}
+
+// ID is 2 sha256 of the serialized header
+func (b Block) ID() []byte {
+	hash := encoding.Hash256(b.Serialize())
+	return encoding.Reverse(hash[:])
+}
+
+// ParseBlock parses a serialized block header
+func ParseBlock(b []byte) (*Block, error) {
+	blockBuffer := bytes.NewBuffer(b)
+
+	buf := make([]byte, 4)
+	_, err := io.ReadFull(blockBuffer, buf)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block version: %v", err)
+	}
+	version := binary.LittleEndian.Uint32(buf)
+
+	var prevBlock [32]byte
+	_, err = io.ReadFull(blockBuffer, prevBlock[:])
+	if err != nil {
+		return nil, fmt.Errorf("error getting previous block id: %v", err)
+	}
+	prevBlock = encoding.ReverseByteArr32(prevBlock)
+
+	var merkleRoot [32]byte
+	_, err = io.ReadFull(blockBuffer, merkleRoot[:])
+	if err != nil {
+		return nil, fmt.Errorf("error getting merkleRoot: %v", err)
+	}
+	merkleRoot = encoding.ReverseByteArr32(merkleRoot)
+
+	_, err = io.ReadFull(blockBuffer, buf)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block timestamp: %v", err)
+	}
+	timestamp := binary.LittleEndian.Uint32(buf)
+
+	var bits [4]byte
+	_, err = io.ReadFull(blockBuffer, bits[:])
+	if err != nil {
+		return nil, fmt.Errorf("error getting block bits: %v", err)
+	}
+
+	var nonce [4]byte
+	_, err = io.ReadFull(blockBuffer, nonce[:])
+	if err != nil {
+		return nil, fmt.Errorf("error getting block nonce: %v", err)
+	}
+
+	return &Block{version: version, previousBlock: prevBlock, merkleRoot: merkleRoot, timestamp: timestamp, bits: bits, nonce: nonce}, nil
+}
+
+// Serialize serializes the block header
+func (b Block) Serialize() []byte {
+	version := make([]byte, 4)
+	binary.LittleEndian.PutUint32(version, b.version)
+
+	prevBlock := encoding.ReverseByteArr32(b.previousBlock)
+	merkleRoot := encoding.ReverseByteArr32(b.merkleRoot)
+
+	timestamp := make([]byte, 4)
+	binary.LittleEndian.PutUint32(timestamp, b.timestamp)
+
+	var bits [4]byte = b.bits
+	var nonce [4]byte = b.nonce
+
+	return bytes.Join([][]byte{version, prevBlock[:], merkleRoot[:], timestamp, bits[:], nonce[:]}, []byte{})
+}
+
+// CheckPow checks if the block header hash is below the target difficulty
+func (b Block) CheckPow() bool {
+	blockHash := new(big.Int).SetBytes(b.ID())
+	return blockHash.Cmp(b.Target()) == -1
+}
+
+// Target gets the target number from bits field
+func (b Block) Target() *big.Int {
+	return BitsToTarget(b.bits)
+}
+
+// Difficulty returns the difficulty relative to the lowest possible target
+func (b Block) Difficulty() *big.Int {
+	// difficulty = 0xffff * 256^(0x1d-3) / target
+	exp := big.NewInt(int64(0x1d - 3))
+	num := encoding.FromHex("ffff")
+	mul := new(big.Int).Exp(big.NewInt(256), exp, nil)
+	num.Mul(num, mul)
+
+	return num.Div(num, b.Target())
+}
+
+// BIP9 reports whether the block signals BIP 9 version bits
+func (b Block) BIP9() bool {
+	return b.version>>29 == 1
+}
+
+// BIP91 reports whether the block signals BIP 91
+func (b Block) BIP91() bool {
+	return b.version>>4&1 == 1
+}
+
+// BIP141 reports whether the block signals BIP 141 (segwit)
+func (b Block) BIP141() bool {
+	return b.version>>1&1 == 1
+}
+
+// ValidateMerkleRoot checks the merkle root of txHashes against the header
+func (b Block) ValidateMerkleRoot() bool {
+	if len(b.txHashes) == 0 {
+		return false
+	}
+	merkleRoot := MerkleRoot(b.txHashes)
+	return bytes.Equal(merkleRoot, b.merkleRoot[:])
+}
+
+// VerifyTxs checks the transactions of the block. The first one must be the
+// only coinbase, and the signatures of all the others are checked together
+// on one BatchVerifier
+func (b Block) VerifyTxs(txs []*tx.Tx) (bool, error) {
+	if len(txs) == 0 || !txs[0].IsCoinbase() {
+		return false, nil
+	}
+	for _, t := range txs[1:] {
+		if t.IsCoinbase() {
+			return false, nil
+		}
+	}
+	if verifyTxsBatch(txs[1:]) {
+		return true, nil
+	}
+	// the batch pass takes every signature as valid, so a script that needs
+	// one to fail fails there. Whatever failed, check each transaction on its
+	// own to get the exact answer
+	for _, t := range txs[1:] {
+		valid, err := t.Verify()
+		if err != nil || !valid {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// verifyTxsBatch evaluates every transaction queueing all the signatures on
+// one BatchVerifier, and reports whether everything passed
+func verifyTxsBatch(txs []*tx.Tx) bool {
+	batch := ecc.NewBatchVerifier(0, false)
+	for _, t := range txs {
+		valid, err := t.VerifyBatch(batch)
+		if err != nil || !valid {
+			return false
+		}
+	}
+	return batch.Verify()
+}
+
+// BitsToTarget converts the bits field to the target number
+func BitsToTarget(bits [4]byte) *big.Int {
+	// last byte in bits field is the exponent
+	exponent := bits[len(bits)-1]
+
+	// coefficient are the other 3 bytes interpreted in little endian
+	coefficient := new(big.Int).SetBytes(encoding.Reverse(bits[:len(bits)-1]))
+
+	mul := new(big.Int).Exp(big.NewInt(256), big.NewInt(int64(exponent-3)), nil)
+
+	// target = coefficient * 256^(exponent - 3)
+	target := new(big.Int).Set(coefficient.Mul(coefficient, mul))
+	return target
+}
+
+// TargetToBits converts a target number to the bits field
+func TargetToBits(target *big.Int) [4]byte {
+	rawBytes := make([]byte, 32)
+	rawBytes = target.FillBytes(rawBytes)
+	rawBytes = bytes.TrimLeft(rawBytes, string(byte(0)))
+
+	exponent := 0
+	coefficient := []byte{0x00}
+	if rawBytes[0] > 0x7f {
+		exponent = len(rawBytes) + 1
+		coefficient = append(coefficient, rawBytes[:2]...)
+	} else {
+		exponent = len(rawBytes)
+		coefficient = rawBytes[:3]
+	}
+
+	var newBits [4]byte
+	j := 3
+	for i := 0; i < 3; i++ {
+		newBits[i] = rawBytes[j]
+		j--
+	}
+	newBits[3] = byte(exponent)
+	return newBits
+}
+
+// time differential = (block timestamp of last block in difficulty adjustment period) - (block timestamp of first block in difficulty adjustment period)
+// to calculate new target = previous target * time differential / (2 weeks)
+func CalculateNewBits(previousBits [4]byte, timeDifferential uint32) [4]byte {
+	if timeDifferential > TWO_WEEKS*4 {
+		timeDifferential = TWO_WEEKS * 4
+	} else if timeDifferential < TWO_WEEKS/4 {
+		timeDifferential = TWO_WEEKS / 4
+	}
+	previousTarget := BitsToTarget(previousBits)
+	newTarget := new(big.Int).Mul(previousTarget, big.NewInt(int64(timeDifferential)))
+	newTarget.Div(newTarget, big.NewInt(TWO_WEEKS))
+	return TargetToBits(newTarget)
+}
@@ -1,10 +1,11 @@
-package main
+package block
 
 import (
 	"encoding/hex"
 	"math/big"
 	"testing"
 
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,7 +15,10 @@ func TestParseBlock(t *testing.T) {
 		t.Error("error decoding block")
 	}
 
-	block := parseBlock(rawBlock)
+	block, err := ParseBlock(rawBlock)
+	if err != nil {
+		t.Fatalf("error parsing block: %v", err)
+	}
 
 	var expectedNum uint32 = 0x20000002
 	assert.Equal(t, expectedNum, block.version, "version does not match")
@@ -43,8 +47,11 @@ func TestSerializeBlock(t *testing.T) {
 	if err != nil {
 		t.Error("error decoding block")
 	}
-	block := parseBlock(rawBlock)
-	assert.Equal(t, rawBlock, block.serialize(), "blocks serialized do not match")
+	block, err := ParseBlock(rawBlock)
+	if err != nil {
+		t.Fatalf("error parsing block: %v", err)
+	}
+	assert.Equal(t, rawBlock, block.Serialize(), "blocks serialized do not match")
 }
 
 func TestTarget(t *testing.T) {
@@ -53,9 +60,12 @@ func TestTarget(t *testing.T) {
 		t.Error("error decoding block")
 	}
 
-	block := parseBlock(rawBlock)
-	want := fromHex("13ce9000000000000000000000000000000000000000000")
-	assert.Equal(t, want, block.target(), "targets do not match")
+	block, err := ParseBlock(rawBlock)
+	if err != nil {
+		t.Fatalf("error parsing block: %v", err)
+	}
+	want := encoding.FromHex("13ce9000000000000000000000000000000000000000000")
+	assert.Equal(t, want, block.Target(), "targets do not match")
 }
 
 func TestDifficulty(t *testing.T) {
@@ -64,8 +74,11 @@ func TestDifficulty(t *testing.T) {
 		t.Error("error decoding block")
 	}
 
-	block := parseBlock(rawBlock)
-	assert.Equal(t, big.NewInt(888171856257), block.difficulty(), "difficulty does not match")
+	block, err := ParseBlock(rawBlock)
+	if err != nil {
+		t.Fatalf("error parsing block: %v", err)
+	}
+	assert.Equal(t, big.NewInt(888171856257), block.Difficulty(), "difficulty does not match")
 }
 
 func TestCheckPow(t *testing.T) {
@@ -74,8 +87,11 @@ func TestCheckPow(t *testing.T) {
 		t.Error("error decoding block")
 	}
 
-	block := parseBlock(rawBlock)
-	assert.Equal(t, true, block.checkPow())
+	block, err := ParseBlock(rawBlock)
+	if err != nil {
+		t.Fatalf("error parsing block: %v", err)
+	}
+	assert.Equal(t, true, block.CheckPow())
 }
 
 //func TestCalculateNewBits(t *testing.T) {
@@ -84,6 +100,6 @@ func TestCheckPow(t *testing.T) {
 //	want := [4]byte{0x00, 0x00, 0x15, 0x17}
 //	//want := [4]byte{0x00, 0x15, 0x76, 0x17}
 
-//	fmt.Printf("calculated new bits = %x\n", calculateNewBits(prevBits, timeDifferential))
-//	assert.Equal(t, want, calculateNewBits(prevBits, timeDifferential), "bits do not match")
+//	fmt.Printf("calculated new bits = %x\n", CalculateNewBits(prevBits, timeDifferential))
+//	assert.Equal(t, want, CalculateNewBits(prevBits, timeDifferential), "bits do not match")
 //}
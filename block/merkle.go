@@ -0,0 +1,35 @@
+package block
+
+import "crypto/sha256"
+
+// MerkleParent hashes left and right together
+func MerkleParent(left, right []byte) []byte {
+	combined := make([]byte, 0, len(left)+len(right))
+	combined = append(combined, left...)
+	combined = append(combined, right...)
+	parent := sha256.Sum256(combined)
+	return parent[:]
+}
+
+// MerkleParentLevel returns the level above hashList, duplicating the last
+// hash when the level has an odd number of hashes
+func MerkleParentLevel(hashList [][]byte) [][]byte {
+	if len(hashList)%2 == 1 {
+		hashList = append(hashList, hashList[len(hashList)-1])
+	}
+
+	var parentLevel [][]byte
+	for i := 0; i < len(hashList); i += 2 {
+		parentLevel = append(parentLevel, MerkleParent(hashList[i], hashList[i+1]))
+	}
+	return parentLevel
+}
+
+// MerkleRoot reduces hashList level by level until one hash is left
+func MerkleRoot(hashList [][]byte) []byte {
+	currentHashList := hashList
+	for len(currentHashList) > 1 {
+		currentHashList = MerkleParentLevel(currentHashList)
+	}
+	return currentHashList[0]
+}
@@ -0,0 +1,144 @@
+package ecc
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchVerifier collects (public key, signature, signature hash) tuples and
+// checks all of them at once on a pool of worker goroutines. It is safe to
+// Add from several goroutines
+type BatchVerifier struct {
+	workers     int
+	multiScalar bool
+
+	mu      sync.Mutex
+	entries []batchEntry
+	schnorr []schnorrEntry
+}
+
+type batchEntry struct {
+	pubKey *Point
+	sig    Signature
+	z      *big.Int
+}
+
+type schnorrEntry struct {
+	pubKey *Point
+	sig    SchnorrSignature
+	msg    []byte
+}
+
+// NewBatchVerifier returns an empty verifier. workers <= 0 uses one worker
+// per CPU. With multiScalar the Schnorr signatures given to each worker are
+// checked with a single multi-scalar equation instead of one by one. ECDSA
+// only commits to the x coordinate of R so ECDSA signatures are always
+// checked one by one
+func NewBatchVerifier(workers int, multiScalar bool) *BatchVerifier {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &BatchVerifier{workers: workers, multiScalar: multiScalar}
+}
+
+// Add queues an ECDSA signature of z by pubKey
+func (bv *BatchVerifier) Add(pubKey *Point, sig Signature, z *big.Int) {
+	bv.mu.Lock()
+	bv.entries = append(bv.entries, batchEntry{pubKey: pubKey, sig: sig, z: z})
+	bv.mu.Unlock()
+}
+
+// AddSchnorr queues a BIP 340 signature of msg by the x-only key pubKey
+func (bv *BatchVerifier) AddSchnorr(pubKey *Point, sig SchnorrSignature, msg []byte) {
+	bv.mu.Lock()
+	bv.schnorr = append(bv.schnorr, schnorrEntry{pubKey: pubKey, sig: sig, msg: msg})
+	bv.mu.Unlock()
+}
+
+// Len returns the number of queued signatures
+func (bv *BatchVerifier) Len() int {
+	bv.mu.Lock()
+	defer bv.mu.Unlock()
+	return len(bv.entries) + len(bv.schnorr)
+}
+
+// Reset drops every queued signature
+func (bv *BatchVerifier) Reset() {
+	bv.mu.Lock()
+	bv.entries = nil
+	bv.schnorr = nil
+	bv.mu.Unlock()
+}
+
+// Verify returns true if every queued signature is valid. Workers stop
+// picking up new signatures as soon as one of them fails
+func (bv *BatchVerifier) Verify() bool {
+	bv.mu.Lock()
+	entries := bv.entries
+	schnorr := bv.schnorr
+	bv.mu.Unlock()
+
+	jobs := make([]func() bool, 0, len(entries)+len(schnorr))
+	for _, entry := range entries {
+		entry := entry
+		jobs = append(jobs, func() bool {
+			return entry.pubKey.VerifySignature(entry.sig, entry.z)
+		})
+	}
+	if bv.multiScalar && len(schnorr) > 1 {
+		// one equation per worker
+		chunk := (len(schnorr) + bv.workers - 1) / bv.workers
+		for start := 0; start < len(schnorr); start += chunk {
+			end := start + chunk
+			if end > len(schnorr) {
+				end = len(schnorr)
+			}
+			group := schnorr[start:end]
+			jobs = append(jobs, func() bool {
+				return verifySchnorrBatch(group)
+			})
+		}
+	} else {
+		for _, entry := range schnorr {
+			entry := entry
+			jobs = append(jobs, func() bool {
+				return entry.pubKey != nil && entry.pubKey.VerifySchnorr(entry.sig, entry.msg)
+			})
+		}
+	}
+	return bv.run(jobs)
+}
+
+func (bv *BatchVerifier) run(jobs []func() bool) bool {
+	if len(jobs) == 0 {
+		return true
+	}
+
+	workers := bv.workers
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	var next int64 = -1
+	var failed int32
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&failed) == 0 {
+				i := atomic.AddInt64(&next, 1)
+				if i >= int64(len(jobs)) {
+					return
+				}
+				if !jobs[i]() {
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return failed == 0
+}
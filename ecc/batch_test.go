@@ -0,0 +1,109 @@
+package ecc
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+func TestBatchVerifier(t *testing.T) {
+	var privKeys []*PrivateKey
+	var hashes []*big.Int
+	var sigs []*Signature
+	for i := int64(1); i <= 20; i++ {
+		privKey := NewPrivateKey(big.NewInt(i * 7919))
+		z := new(big.Int).Lsh(big.NewInt(i), 200)
+		privKeys = append(privKeys, privKey)
+		hashes = append(hashes, z)
+		sigs = append(sigs, privKey.Sign(z))
+	}
+
+	testCases := []struct {
+		workers int
+		bad     int // index of the entry signing the wrong hash, -1 for none
+		want    bool
+	}{
+		{1, -1, true},
+		{4, -1, true},
+		{0, -1, true},
+		{4, 0, false},
+		{4, 19, false},
+		{64, 7, false},
+	}
+
+	for _, test := range testCases {
+		batch := NewBatchVerifier(test.workers, false)
+		var wg sync.WaitGroup
+		for i := range privKeys {
+			z := hashes[i]
+			if i == test.bad {
+				z = new(big.Int).Add(z, big.NewInt(1))
+			}
+			wg.Add(1)
+			go func(i int, z *big.Int) {
+				defer wg.Done()
+				batch.Add(privKeys[i].PublicKey(), *sigs[i], z)
+			}(i, z)
+		}
+		wg.Wait()
+
+		if batch.Len() != len(privKeys) {
+			t.Errorf("expected '%v' but got '%v' instead", len(privKeys), batch.Len())
+		}
+		if got := batch.Verify(); got != test.want {
+			t.Errorf("workers %v bad %v: expected '%v' but got '%v' instead", test.workers, test.bad, test.want, got)
+		}
+	}
+
+	empty := NewBatchVerifier(0, false)
+	if !empty.Verify() {
+		t.Error("expected empty batch to verify")
+	}
+}
+
+func TestBatchVerifierSchnorr(t *testing.T) {
+	type signed struct {
+		pubKey *Point
+		sig    *SchnorrSignature
+		msg    []byte
+	}
+	var sigs []signed
+	for i := int64(1); i <= 16; i++ {
+		privKey := NewPrivateKey(big.NewInt(i * 104729))
+		msg := []byte{byte(i), 0xaa, 0xbb}
+		sig, err := privKey.SignSchnorr(msg)
+		if err != nil {
+			t.Fatalf("error signing: %v", err)
+		}
+		sigs = append(sigs, signed{privKey.PublicKey(), sig, msg})
+	}
+
+	testCases := []struct {
+		workers     int
+		multiScalar bool
+		bad         int
+		want        bool
+	}{
+		{4, false, -1, true},
+		{4, true, -1, true},
+		{1, true, -1, true},
+		{3, true, 0, false},
+		{3, true, 15, false},
+		{1, true, 9, false},
+		{4, false, 9, false},
+	}
+
+	for _, test := range testCases {
+		batch := NewBatchVerifier(test.workers, test.multiScalar)
+		for i, s := range sigs {
+			msg := s.msg
+			if i == test.bad {
+				msg = []byte("something else")
+			}
+			batch.AddSchnorr(s.pubKey, *s.sig, msg)
+		}
+		if got := batch.Verify(); got != test.want {
+			t.Errorf("workers %v multiScalar %v bad %v: expected '%v' but got '%v' instead", test.workers, test.multiScalar, test.bad, test.want, got)
+		}
+	}
+}
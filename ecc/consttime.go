@@ -0,0 +1,212 @@
+package ecc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// projectivePoint is a secp256k1 point in homogeneous projective coordinates,
+// where (x, y, z) stands for the affine point (x/z, y/z) and (0, 1, 0) is the
+// point at infinity. It is used with the complete addition formulas of
+// Renes, Costello and Batina (https://eprint.iacr.org/2015/1060), which give
+// the right answer for doubling and infinity without branching, so the
+// sequence of field operations never depends on secret data
+type projectivePoint struct {
+	x, y, z fieldVal
+}
+
+// curveB3 is 3*b for secp256k1's b = 7
+var curveB3 = fieldVal{21, 0, 0, 0}
+
+func (p *projectivePoint) setInfinity() *projectivePoint {
+	*p = projectivePoint{}
+	p.y.setInt(1)
+	return p
+}
+
+func (p *projectivePoint) setAffine(a *affinePoint) *projectivePoint {
+	p.x = a.x
+	p.y = a.y
+	p.z.setInt(1)
+	return p
+}
+
+// add sets p to a + b (algorithm 7 of the paper, for curves with a = 0). It
+// also works when a == b, a or b is infinity, or b = -a
+func (p *projectivePoint) add(a, b *projectivePoint) *projectivePoint {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldVal
+
+	t0.mul(&a.x, &b.x)
+	t1.mul(&a.y, &b.y)
+	t2.mul(&a.z, &b.z)
+	t3.add(&a.x, &a.y)
+	t4.add(&b.x, &b.y)
+	t3.mul(&t3, &t4)
+	t4.add(&t0, &t1)
+	t3.sub(&t3, &t4)
+	t4.add(&a.y, &a.z)
+	x3.add(&b.y, &b.z)
+	t4.mul(&t4, &x3)
+	x3.add(&t1, &t2)
+	t4.sub(&t4, &x3)
+	x3.add(&a.x, &a.z)
+	y3.add(&b.x, &b.z)
+	x3.mul(&x3, &y3)
+	y3.add(&t0, &t2)
+	y3.sub(&x3, &y3)
+	x3.add(&t0, &t0)
+	t0.add(&x3, &t0)
+	t2.mul(&curveB3, &t2)
+	z3.add(&t1, &t2)
+	t1.sub(&t1, &t2)
+	y3.mul(&curveB3, &y3)
+	x3.mul(&t4, &y3)
+	t2.mul(&t3, &t1)
+	x3.sub(&t2, &x3)
+	y3.mul(&y3, &t0)
+	t1.mul(&t1, &z3)
+	y3.add(&t1, &y3)
+	t0.mul(&t0, &t3)
+	z3.mul(&z3, &t4)
+	z3.add(&z3, &t0)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// toAffine converts p to affine coordinates and reports whether p was the
+// point at infinity
+func (p *projectivePoint) toAffine() (affinePoint, bool) {
+	var zinv fieldVal
+	zinv.inverse(&p.z)
+
+	var a affinePoint
+	a.x.mul(&p.x, &zinv)
+	a.y.mul(&p.y, &zinv)
+	return a, p.z.isZero()
+}
+
+// ctEq returns 1 if a == b and 0 otherwise without branching
+func ctEq(a, b uint64) uint64 {
+	x := a ^ b
+	// x | -x has its top bit set unless x == 0
+	return ((x | -x) >> 63) ^ 1
+}
+
+// cmov sets f to a if mask is all ones and leaves it unchanged if mask is 0
+func (f *fieldVal) cmov(a *fieldVal, mask uint64) {
+	f[0] ^= (f[0] ^ a[0]) & mask
+	f[1] ^= (f[1] ^ a[1]) & mask
+	f[2] ^= (f[2] ^ a[2]) & mask
+	f[3] ^= (f[3] ^ a[3]) & mask
+}
+
+func (p *projectivePoint) cmov(a *projectivePoint, mask uint64) {
+	p.x.cmov(&a.x, mask)
+	p.y.cmov(&a.y, mask)
+	p.z.cmov(&a.z, mask)
+}
+
+// lookupG sets p to digit * 2^(4*window) * G, reading every entry of the
+// window so the memory access pattern does not depend on digit
+func (p *projectivePoint) lookupG(window int, digit uint64) {
+	p.setInfinity()
+	var one fieldVal
+	one.setInt(1)
+	for j := 1; j < gWindowSize; j++ {
+		mask := -ctEq(uint64(j), digit)
+		entry := &gTable[window][j]
+		p.x.cmov(&entry.x, mask)
+		p.y.cmov(&entry.y, mask)
+		p.z.cmov(&one, mask)
+	}
+}
+
+// lookup sets p to table[digit], reading every entry of table
+func (p *projectivePoint) lookup(table *[gWindowSize]projectivePoint, digit uint64) {
+	p.setInfinity()
+	for j := 0; j < gWindowSize; j++ {
+		p.cmov(&table[j], -ctEq(uint64(j), digit))
+	}
+}
+
+// scalarBaseMult returns k*G in constant time. It does one table lookup and
+// one complete addition per 4 bit window whatever the value of k, and is the
+// path used for secret scalars (key derivation and signing nonces)
+func scalarBaseMult(k *big.Int) projectivePoint {
+	gTableOnce.Do(precomputeGTable)
+	kb := scalarBytes(k)
+
+	var result, entry projectivePoint
+	result.setInfinity()
+	for i := 0; i < gWindows; i++ {
+		b := uint64(kb[31-i/2])
+		// pick the low or high nibble without branching on the secret
+		shift := uint64(i%2) * 4
+		digit := (b >> shift) & 0x0f
+		entry.lookupG(i, digit)
+		result.add(&result, &entry)
+	}
+	return result
+}
+
+// scalarMult returns k*p in constant time with a fixed 4 bit window: every
+// window costs four doublings, one table lookup and one addition
+func scalarMult(p *affinePoint, k *big.Int) projectivePoint {
+	kb := scalarBytes(k)
+
+	// table[j] = j*p
+	var table [gWindowSize]projectivePoint
+	table[0].setInfinity()
+	table[1].setAffine(p)
+	for j := 2; j < gWindowSize; j++ {
+		table[j].add(&table[j-1], &table[1])
+	}
+
+	var result, entry projectivePoint
+	result.setInfinity()
+	for i := 0; i < 32; i++ {
+		for _, digit := range [2]uint64{uint64(kb[i] >> 4), uint64(kb[i] & 0x0f)} {
+			result.add(&result, &result)
+			result.add(&result, &result)
+			result.add(&result, &result)
+			result.add(&result, &result)
+			entry.lookup(&table, digit)
+			result.add(&result, &entry)
+		}
+	}
+	return result
+}
+
+// s256PointFromProjective converts back to a Point without re-checking that
+// it is on the curve
+func s256PointFromProjective(p *projectivePoint) *Point {
+	a := &FieldElement{num: big.NewInt(0), prime: P}
+	b := &FieldElement{num: big.NewInt(7), prime: P}
+	affine, infinity := p.toAffine()
+	if infinity {
+		var infelement FieldElement
+		return &Point{x: infelement, y: infelement, a: *a, b: *b}
+	}
+	x := FieldElement{num: affine.x.big(), prime: P}
+	y := FieldElement{num: affine.y.big(), prime: P}
+	return &Point{x: x, y: y, a: *a, b: *b}
+}
+
+// ECDH returns sha256 of the compressed sec serialization of secret * pub,
+// the same shared secret as libsecp256k1's default ECDH hash. The
+// multiplication runs in constant time
+func (pp PrivateKey) ECDH(pub *Point) ([]byte, error) {
+	if pub == nil || !pub.isS256() || pub.IsInfinity() {
+		return nil, errors.New("public key is not a secp256k1 point")
+	}
+	pubAffine := pub.toAffine()
+	shared := scalarMult(&pubAffine, pp.secret)
+	point := s256PointFromProjective(&shared)
+	if point.IsInfinity() {
+		return nil, errors.New("shared point is infinity")
+	}
+	hash := sha256.Sum256(point.Sec(true))
+	return hash[:], nil
+}
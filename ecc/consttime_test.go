@@ -0,0 +1,95 @@
+package ecc
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestScalarBaseMultConstTime(t *testing.T) {
+	for _, k := range append(testScalars(), big.NewInt(0), N) {
+		want := G.RmulS256(k)
+		result := scalarBaseMult(k)
+		got := s256PointFromProjective(&result)
+		if got.Ne(*want) {
+			t.Errorf("%x * G: expected '%v' but got '%v' instead", k, want, got)
+		}
+	}
+}
+
+func TestScalarMultConstTime(t *testing.T) {
+	p := G.RmulS256(big.NewInt(1485))
+	affine := p.toAffine()
+	for _, k := range append(testScalars(), big.NewInt(0), N) {
+		want := p.RmulS256(k)
+		result := scalarMult(&affine, k)
+		got := s256PointFromProjective(&result)
+		if got.Ne(*want) {
+			t.Errorf("%x * P: expected '%v' but got '%v' instead", k, want, got)
+		}
+	}
+}
+
+func TestProjectiveAddComplete(t *testing.T) {
+	var p, q, inf, sum projectivePoint
+	p.setAffine(&affinePoint{x: *new(fieldVal).setBig(gx), y: *new(fieldVal).setBig(gy)})
+	q = p
+	q.y.neg(&q.y)
+	inf.setInfinity()
+
+	// P + P
+	sum.add(&p, &p)
+	if got := s256PointFromProjective(&sum); got.Ne(*G.RmulS256(big.NewInt(2))) {
+		t.Errorf("expected 2G but got '%v' instead", got)
+	}
+	// P + infinity
+	sum.add(&p, &inf)
+	if got := s256PointFromProjective(&sum); got.Ne(*G) {
+		t.Errorf("expected G but got '%v' instead", got)
+	}
+	// P + -P
+	sum.add(&p, &q)
+	if got := s256PointFromProjective(&sum); !got.IsInfinity() {
+		t.Errorf("expected infinity but got '%v' instead", got)
+	}
+}
+
+func TestECDH(t *testing.T) {
+	alice := NewPrivateKey(big.NewInt(8675309))
+	bob := NewPrivateKey(new(big.Int).Sub(N, big.NewInt(12345)))
+
+	aliceSecret, err := alice.ECDH(bob.PublicKey())
+	if err != nil {
+		t.Fatalf("error computing shared secret: %v", err)
+	}
+	bobSecret, err := bob.ECDH(alice.PublicKey())
+	if err != nil {
+		t.Fatalf("error computing shared secret: %v", err)
+	}
+	if !bytes.Equal(aliceSecret, bobSecret) {
+		t.Errorf("shared secrets do not match: '%x' '%x'", aliceSecret, bobSecret)
+	}
+
+	var infinity FieldElement
+	if _, err := alice.ECDH(&Point{x: infinity, y: infinity, a: G.a, b: G.b}); err == nil {
+		t.Error("expected error for point at infinity")
+	}
+}
+
+func BenchmarkScalarBaseMultConstTime(b *testing.B) {
+	k := benchmarkScalar()
+	scalarBaseMult(k)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scalarBaseMult(k)
+	}
+}
+
+func BenchmarkScalarMultConstTime(b *testing.B) {
+	k := benchmarkScalar()
+	affine := G.RmulS256(big.NewInt(1485)).toAffine()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scalarMult(&affine, k)
+	}
+}
@@ -0,0 +1,741 @@
+// Package ecc implements finite field and elliptic curve arithmetic for the
+// secp256k1 curve, ECDSA signatures, and private keys.
+package ecc
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+var (
+	twopow256 *big.Int = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
+	twopow32  *big.Int = new(big.Int).Exp(big.NewInt(2), big.NewInt(32), big.NewInt(0))
+	sub       *big.Int = twopow256.Sub(twopow256, twopow32)
+
+	// P is the prime of the secp256k1 field
+	P *big.Int = sub.Sub(sub, big.NewInt(977))
+	// N is the order of the secp256k1 group
+	N *big.Int = encoding.FromHex("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+)
+
+var (
+	// ErrNotInField is returned for numbers outside of 0 to prime - 1
+	ErrNotInField = errors.New("number not in field range")
+	// ErrNotOnCurve is returned for coordinates that are not a point of the
+	// curve
+	ErrNotOnCurve = errors.New("point is not on the curve")
+)
+
+// FieldElement is an element of a finite field of order prime
+type FieldElement struct {
+	num   *big.Int // single finite field element
+	prime *big.Int // field
+}
+
+// NewFieldElement returns ErrNotInField if num is not in the field range.
+// Like the rest of the arithmetic, it doesn't modify its arguments
+func NewFieldElement(num, prime *big.Int) (*FieldElement, error) {
+	// if num < 0 || num >= prime
+	if num.Sign() == -1 || num.Cmp(prime) >= 0 {
+		return nil, fmt.Errorf("%w: %d is not in 0 to %d", ErrNotInField, num, new(big.Int).Sub(prime, big.NewInt(1)))
+	}
+	return &FieldElement{num: num, prime: prime}, nil
+}
+
+// NewS256FieldElement returns an element of the secp256k1 field
+func NewS256FieldElement(num *big.Int) (*FieldElement, error) {
+	return NewFieldElement(num, P)
+}
+
+// Num returns the value of the element
+func (e FieldElement) Num() *big.Int {
+	return e.num
+}
+
+// Prime returns the order of the field
+func (e FieldElement) Prime() *big.Int {
+	return e.prime
+}
+
+func (e FieldElement) Eq(element FieldElement) bool {
+	if e.num.Cmp(element.num) == 0 && e.prime.Cmp(element.prime) == 0 {
+		return true
+	}
+	return false
+}
+
+func (e FieldElement) Ne(element FieldElement) bool {
+	if e.num.Cmp(element.num) != 0 || e.prime.Cmp(element.prime) != 0 {
+		return true
+	}
+	return false
+}
+
+func (e FieldElement) Add(element FieldElement) *FieldElement {
+	if e.prime.Cmp(element.prime) != 0 {
+		panic("ecc: cannot add two numbers in different fields")
+	}
+
+	// (e.num + element.num) % e.prime
+	num := new(big.Int).Add(e.num, element.num)
+	num.Mod(num, e.prime)
+	return &FieldElement{num: num, prime: e.prime}
+}
+
+func (e FieldElement) Sub(element FieldElement) *FieldElement {
+	if e.prime.Cmp(element.prime) != 0 {
+		panic("ecc: cannot subtract two numbers in different fields")
+	}
+
+	// (e.num - element.num) % e.prime
+	num := new(big.Int).Sub(e.num, element.num)
+	num.Mod(num, e.prime)
+	return &FieldElement{num: num, prime: e.prime}
+}
+
+func (e FieldElement) Mul(element FieldElement) *FieldElement {
+	if e.prime.Cmp(element.prime) != 0 {
+		panic("ecc: cannot multiply two numbers in different fields")
+	}
+
+	// (e.num * element.num) % e.prime
+	num := new(big.Int).Mul(e.num, element.num)
+	num.Mod(num, e.prime)
+	return &FieldElement{num: num, prime: e.prime}
+}
+
+func (e FieldElement) Pow(exponent *big.Int) *FieldElement {
+	// (e.num ** exponent) % e.prime
+	num := new(big.Int).Exp(e.num, exponent, e.prime)
+	return &FieldElement{num: num, prime: e.prime}
+}
+
+func (e FieldElement) Div(divisor FieldElement) *FieldElement {
+	if e.prime.Cmp(divisor.prime) != 0 {
+		panic("ecc: cannot divide two numbers in different fields")
+	}
+
+	// divpow := divisor.pow(e.prime - 2)
+	// num := mod((e.mul(*divpow).num), e.prime)
+	temp := new(big.Int).Set(e.prime)
+	divpow := divisor.Pow(temp.Sub(e.prime, big.NewInt(2)))
+	divres := e.Mul(*divpow)
+	num := divpow.num.Mod(divres.num, e.prime)
+
+	return &FieldElement{num: num, prime: e.prime}
+}
+
+// Sqrt returns the square root of an element of the secp256k1 field
+func (e FieldElement) Sqrt() *FieldElement {
+	exp := new(big.Int).Set(P).Add(P, big.NewInt(1))
+	exp.Div(exp, big.NewInt(4))
+
+	return e.Pow(exp)
+}
+
+func (e FieldElement) String() string {
+	return fmt.Sprintf("FieldElement_%d (%d)", e.prime, e.num)
+}
+
+// IsInf reports whether e is the element used for the point at infinity
+func IsInf(e FieldElement) bool {
+	if e.num == nil && e.prime == nil {
+		return true
+	}
+	return false
+}
+
+// Point is a point on the elliptic curve y^2 = x^3 + ax + b
+type Point struct {
+	x FieldElement
+	y FieldElement
+	a FieldElement
+	b FieldElement
+}
+
+// NewPoint returns ErrNotOnCurve if (x, y) is not on the curve. Passing two
+// zero value elements returns the point at infinity
+func NewPoint(x, y, a, b FieldElement) (*Point, error) {
+	p := &Point{x: x, y: y, a: a, b: b}
+
+	if IsInf(x) && IsInf(y) {
+		var infelement FieldElement
+		return &Point{x: infelement, y: infelement, a: a, b: b}, nil
+	}
+	if IsInf(x) || IsInf(y) {
+		return nil, fmt.Errorf("%w: only one coordinate is infinity", ErrNotOnCurve)
+	}
+
+	squarey := y.Pow(big.NewInt(2))
+	cubex := x.Pow(big.NewInt(3))
+	rights := cubex.Add(*a.Mul(x)).Add(b)
+
+	if squarey.Ne(*rights) {
+		return nil, fmt.Errorf("%w: (%d, %d)", ErrNotOnCurve, x.num, y.num)
+	}
+
+	return p, nil
+}
+
+// NewS256Point returns a point for secp256k1 curve
+func NewS256Point(x, y *big.Int) (*Point, error) {
+	xp, err := NewS256FieldElement(x)
+	if err != nil {
+		return nil, err
+	}
+	yp, err := NewS256FieldElement(y)
+	if err != nil {
+		return nil, err
+	}
+	return newS256PointF(*xp, *yp)
+}
+
+func newS256PointF(x, y FieldElement) (*Point, error) {
+	a := FieldElement{num: big.NewInt(0), prime: P}
+	b := FieldElement{num: big.NewInt(7), prime: P}
+	return NewPoint(x, y, a, b)
+}
+
+// generator point
+var (
+	gx *big.Int = encoding.FromHex("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	gy *big.Int = encoding.FromHex("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8")
+
+	// G is the generator point of secp256k1
+	G *Point = &Point{
+		x: FieldElement{num: gx, prime: P},
+		y: FieldElement{num: gy, prime: P},
+		a: FieldElement{num: big.NewInt(0), prime: P},
+		b: FieldElement{num: big.NewInt(7), prime: P},
+	}
+)
+
+// X returns the x coordinate of the point
+func (p Point) X() FieldElement {
+	return p.x
+}
+
+// Y returns the y coordinate of the point
+func (p Point) Y() FieldElement {
+	return p.y
+}
+
+// IsInfinity reports whether p is the point at infinity
+func (p Point) IsInfinity() bool {
+	return IsInf(p.x) && IsInf(p.y)
+}
+
+func (p Point) Eq(point Point) bool {
+	if p.x.Eq(point.x) && p.y.Eq(point.y) && p.a.Eq(point.a) && p.b.Eq(point.b) {
+		return true
+	}
+	return false
+}
+
+func (p Point) Ne(point Point) bool {
+	if p.x.Ne(point.x) || p.y.Ne(point.y) || p.a.Ne(point.a) || p.b.Ne(point.b) {
+		return true
+	}
+	return false
+}
+
+func (p Point) Add(point Point) *Point {
+	if p.a.Ne(point.a) || p.b.Ne(point.b) {
+		panic("ecc: cannot add points on different curves")
+	}
+
+	if IsInf(p.x) {
+		return &point
+	}
+	if IsInf(point.x) {
+		return &p
+	}
+
+	if p.x.Eq(point.x) && p.y.Ne(point.y) {
+		var infelement FieldElement
+		return &Point{x: infelement, y: infelement, a: p.a, b: p.b}
+	}
+
+	if p.Eq(point) && p.y.num.Sign() == 0 {
+		var infelement FieldElement
+		return &Point{x: infelement, y: infelement, a: p.a, b: p.b}
+	}
+
+	if p.x.Ne(point.x) {
+		// (y2 - y1) / (x2 - x1)
+		slope := point.y.Sub(p.y).Div(*point.x.Sub(p.x))
+
+		// x3 = slope^2 - x1 - x2
+		x := slope.Pow(big.NewInt(2)).Sub(p.x).Sub(point.x)
+
+		// y3 = slope(x1 - x3) - y1
+		y := slope.Mul(*p.x.Sub(*x)).Sub(p.y)
+
+		return &Point{x: *x, y: *y, a: p.a, b: p.b}
+	}
+
+	if p.Eq(point) {
+		three := FieldElement{num: big.NewInt(3), prime: p.x.prime}
+		two := FieldElement{num: big.NewInt(2), prime: p.x.prime}
+
+		// (3x1^2 + a) / (2y1)
+		slope := p.x.Pow(big.NewInt(2)).Mul(three).Add(p.a).Div(*two.Mul(p.y))
+
+		// slope^2 - 2x1
+		x := slope.Pow(big.NewInt(2)).Sub(p.x).Sub(point.x)
+
+		// slope(x1 - x3) - y1
+		y := slope.Mul(*p.x.Sub(*x)).Sub(p.y)
+
+		return &Point{x: *x, y: *y, a: p.a, b: p.b}
+	}
+
+	return nil
+}
+
+// Rmul returns coefficient * p
+func (p Point) Rmul(coefficient *big.Int) *Point {
+	current := &p
+	coef := new(big.Int).Set(coefficient)
+	var infelement FieldElement
+	result := &Point{x: infelement, y: infelement, a: p.a, b: p.b}
+
+	numlen := coefficient.BitLen()
+	for i := 0; i < numlen; i++ {
+		temp := new(big.Int).Set(coef)
+		coefand1 := temp.And(coef, big.NewInt(1))
+		// if (coef & 1) != 0 {
+		if coefand1.Sign() != 0 {
+			result = result.Add(*current)
+		}
+		current = current.Add(*current)
+		//coef = coef >> 1
+		coef.Rsh(coef, 1)
+	}
+
+	return result
+}
+
+// RmulS256 returns coefficient * p with coefficient reduced mod N. Points on
+// secp256k1 use the Jacobian backend in s256.go, with the precomputed table
+// when p is the generator
+func (p Point) RmulS256(coefficient *big.Int) *Point {
+	coefc := new(big.Int).Set(coefficient)
+	coefc.Mod(coefc, N)
+	if !p.isS256() || IsInf(p.x) {
+		return p.Rmul(coefc)
+	}
+
+	var result jacobianPoint
+	if p.isG() {
+		result = scalarBaseMultVartime(coefc)
+	} else {
+		affine := p.toAffine()
+		result = scalarMultVartime(&affine, coefc)
+	}
+	return s256PointFromJacobian(&result)
+}
+
+// VerifySignature checks that s is a valid signature of z for public key p
+func (p Point) VerifySignature(s Signature, z *big.Int) bool {
+	return verifyS256(&p, s, z)
+}
+
+// SEC - Standards for Efficient Cryptography
+// Sec serializes public key in sec format
+func (p Point) Sec(compressed bool) []byte {
+	prefixbuf := make([]byte, 1)
+	xbuf := make([]byte, 32)
+
+	xbuf = p.x.num.FillBytes(xbuf)
+	if compressed {
+		yc := new(big.Int).Set(p.y.num)
+		yc.Mod(yc, big.NewInt(2))
+		// if y is even - prefix 02. Else prefix 03
+		if yc.Sign() == 0 {
+			prefixbuf = big.NewInt(2).FillBytes(prefixbuf)
+		} else {
+			prefixbuf = big.NewInt(3).FillBytes(prefixbuf)
+		}
+	} else {
+		prefixbuf = big.NewInt(4).FillBytes(prefixbuf)
+		ybuf := make([]byte, 32)
+		ybuf = p.y.num.FillBytes(ybuf)
+		return bytes.Join([][]byte{prefixbuf, xbuf, ybuf}, []byte{})
+	}
+
+	return bytes.Join([][]byte{prefixbuf, xbuf}, []byte{})
+}
+
+// ParsePubKey parses a public key in sec format
+func ParsePubKey(secPubKey []byte) (*Point, error) {
+	if len(secPubKey) == 0 {
+		return nil, errors.New("empty public key")
+	}
+	prefix := int(secPubKey[0])
+	if prefix == 4 {
+		if len(secPubKey) != 65 {
+			return nil, errors.New("bad uncompressed public key length")
+		}
+		x := new(big.Int).SetBytes(secPubKey[1:33])
+		y := new(big.Int).SetBytes(secPubKey[33:])
+		point, err := NewS256Point(x, y)
+		if err != nil {
+			return nil, fmt.Errorf("bad public key: %w", err)
+		}
+		return point, nil
+	}
+	if (prefix != 2 && prefix != 3) || len(secPubKey) != 33 {
+		return nil, errors.New("bad public key encoding")
+	}
+
+	x, err := NewS256FieldElement(new(big.Int).SetBytes(secPubKey[1:]))
+	if err != nil {
+		return nil, fmt.Errorf("bad public key: %w", err)
+	}
+	isEven := prefix == 2
+
+	// y^2 = x^3 + 7
+	powr := x.Pow(big.NewInt(3))
+	b := FieldElement{num: big.NewInt(7), prime: P}
+	right := powr.Add(b)
+
+	left := right.Sqrt()
+
+	// -left, which is P - left for left != 0
+	zero := FieldElement{num: big.NewInt(0), prime: P}
+	var even_left, odd_left FieldElement
+	if new(big.Int).Set(left.num).Mod(left.num, big.NewInt(2)).Sign() == 0 {
+		even_left = *left
+		odd_left = *zero.Sub(*left)
+	} else {
+		even_left = *zero.Sub(*left)
+		odd_left = *left
+	}
+
+	var point *Point
+	if isEven {
+		point, err = newS256PointF(*x, even_left)
+	} else {
+		point, err = newS256PointF(*x, odd_left)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad public key: %w", err)
+	}
+	return point, nil
+}
+
+func (p Point) Hash160(compressed bool) []byte {
+	return encoding.Hash160(p.Sec(compressed))
+}
+
+// Address returns the p2pkh address of the public key
+func (p Point) Address(compressed, testnet bool) string {
+	return encoding.H160ToP2PKH(p.Hash160(compressed), testnet)
+}
+
+func (p Point) String() string {
+	if IsInf(p.x) && IsInf(p.y) {
+		return fmt.Sprintf("Point(infinity, infinity)_%d_%d FieldElement(%d)", p.a.num, p.b.num, p.a.prime)
+	}
+	return fmt.Sprintf("Point(%x, %x)_%d_%d FieldElement(%d)", p.x.num, p.y.num, p.a.num, p.b.num, p.a.prime)
+}
+
+// Signature is an ECDSA signature
+type Signature struct {
+	r *big.Int
+	s *big.Int
+}
+
+func NewSignature(r, s *big.Int) *Signature {
+	return &Signature{r: r, s: s}
+}
+
+func (s Signature) R() *big.Int {
+	return s.r
+}
+
+func (s Signature) S() *big.Int {
+	return s.s
+}
+
+func (s Signature) String() string {
+	return fmt.Sprintf("Signature(%x, %x)", s.r, s.s)
+}
+
+// halfN is N / 2, the largest s allowed in a low-S signature
+var halfN *big.Int = new(big.Int).Rsh(N, 1)
+
+// IsLowS reports whether s is at most N/2 (BIP 62/146)
+func (s Signature) IsLowS() bool {
+	return s.s.Cmp(halfN) <= 0
+}
+
+// DER - Distinguished Encoding Rules format
+// Der serializes signature
+func (s Signature) Der() []byte {
+	prepfix := []byte{0x00}
+	marker := []byte{0x02}
+
+	rbytes := new(big.Int).Set(s.r).Bytes()
+	if rbytes[0] >= 0x80 {
+		rbytes = bytes.Join([][]byte{prepfix, rbytes}, []byte{})
+	}
+
+	rlen := []byte{byte(len(rbytes))}
+	result := bytes.Join([][]byte{marker, rlen, rbytes}, []byte{})
+
+	sbytes := new(big.Int).Set(s.s).Bytes()
+	if sbytes[0] >= 0x80 {
+		sbytes = bytes.Join([][]byte{prepfix, sbytes}, []byte{})
+	}
+	slen := []byte{byte(len(sbytes))}
+	result = bytes.Join([][]byte{result, marker, slen, sbytes}, []byte{})
+	marker = []byte{0x30}
+	reslen := []byte{byte(len(result))}
+	return bytes.Join([][]byte{marker, reslen, result}, []byte{})
+}
+
+// ParseSignature parses a DER encoded signature
+func ParseSignature(signature []byte) (*Signature, error) {
+	signatureBuf := bytes.NewBuffer(signature)
+
+	if len(signature) < 8 {
+		return nil, errors.New("Bad signature length")
+	}
+
+	idx := 0
+	if signature[idx] != 0x30 {
+		return nil, errors.New("Bad Signature")
+	}
+	idx++
+	signatureBuf.Next(1)
+
+	siglen := int(signature[idx])
+	if siglen+2 != len(signature) {
+		return nil, errors.New("Bad signature length")
+	}
+	idx++
+	signatureBuf.Next(1)
+
+	marker := signature[idx]
+	if marker != 0x02 {
+		return nil, errors.New("Bad signature: no marker")
+	}
+	idx++
+	signatureBuf.Next(1)
+
+	rlength := int(signature[idx])
+	signatureBuf.Next(1)
+	rbytes := make([]byte, rlength)
+	_, err := signatureBuf.Read(rbytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing R in signature: %v", err)
+	}
+	idx = idx + rlength + 1
+	r := new(big.Int).SetBytes(rbytes)
+
+	if idx+1 >= len(signature) {
+		return nil, errors.New("Bad signature length")
+	}
+	marker = signature[idx]
+	if marker != 0x02 {
+		return nil, errors.New("Bad signature: no marker")
+	}
+	idx++
+	signatureBuf.Next(1)
+
+	slength := int(signature[idx])
+	signatureBuf.Next(1)
+	sbytes := make([]byte, slength)
+	_, err = signatureBuf.Read(sbytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing S in signature: %v", err)
+	}
+	s := new(big.Int).SetBytes(sbytes)
+
+	if len(signature) != rlength+slength+6 {
+		return nil, errors.New("Bad signature length")
+	}
+
+	return &Signature{r: r, s: s}, nil
+}
+
+// ParseDERSignature parses a DER encoded signature enforcing the strict
+// encoding rules of BIP 66: no negative values, no excess padding and exact
+// lengths. signature must not include the sighash type byte
+func ParseDERSignature(signature []byte) (*Signature, error) {
+	// 0x30 [total-length] 0x02 [R-length] [R] 0x02 [S-length] [S]
+	if len(signature) < 8 || len(signature) > 72 {
+		return nil, errors.New("non-canonical signature: bad length")
+	}
+	if signature[0] != 0x30 {
+		return nil, errors.New("non-canonical signature: wrong type")
+	}
+	if int(signature[1]) != len(signature)-2 {
+		return nil, errors.New("non-canonical signature: wrong length marker")
+	}
+
+	rlength := int(signature[3])
+	if 5+rlength >= len(signature) {
+		return nil, errors.New("non-canonical signature: S length misplaced")
+	}
+	slength := int(signature[5+rlength])
+	if rlength+slength+6 != len(signature) {
+		return nil, errors.New("non-canonical signature: R+S length mismatch")
+	}
+
+	if signature[2] != 0x02 {
+		return nil, errors.New("non-canonical signature: R value type mismatch")
+	}
+	if rlength == 0 {
+		return nil, errors.New("non-canonical signature: R length is zero")
+	}
+	if signature[4]&0x80 != 0 {
+		return nil, errors.New("non-canonical signature: R value negative")
+	}
+	if rlength > 1 && signature[4] == 0x00 && signature[5]&0x80 == 0 {
+		return nil, errors.New("non-canonical signature: R value excessively padded")
+	}
+
+	if signature[rlength+4] != 0x02 {
+		return nil, errors.New("non-canonical signature: S value type mismatch")
+	}
+	if slength == 0 {
+		return nil, errors.New("non-canonical signature: S length is zero")
+	}
+	if signature[rlength+6]&0x80 != 0 {
+		return nil, errors.New("non-canonical signature: S value negative")
+	}
+	if slength > 1 && signature[rlength+6] == 0x00 && signature[rlength+7]&0x80 == 0 {
+		return nil, errors.New("non-canonical signature: S value excessively padded")
+	}
+
+	r := new(big.Int).SetBytes(signature[4 : 4+rlength])
+	s := new(big.Int).SetBytes(signature[6+rlength:])
+	return &Signature{r: r, s: s}, nil
+}
+
+// PrivateKey holds a secret and its public key
+type PrivateKey struct {
+	secret *big.Int
+	point  Point // public key
+}
+
+// NewPrivateKey derives the public key for secret. The multiplication runs in
+// constant time
+func NewPrivateKey(secret *big.Int) *PrivateKey {
+	publicKey := scalarBaseMult(secret)
+	return &PrivateKey{secret: secret, point: *s256PointFromProjective(&publicKey)}
+}
+
+// Secret returns the secret scalar of the key
+func (pp PrivateKey) Secret() *big.Int {
+	return pp.secret
+}
+
+// PublicKey returns the public key point
+func (pp PrivateKey) PublicKey() *Point {
+	return &pp.point
+}
+
+// Sign signs z with private key. The nonce k is derived deterministically
+// from the secret and z (RFC 6979) so signing the same z twice gives the same
+// signature. The signature is always low-S
+func (pp PrivateKey) Sign(z *big.Int) *Signature {
+	return pp.SignWithEntropy(z, nil)
+}
+
+// SignWithEntropy signs z like Sign but mixes extraEntropy into the nonce
+// generation as additional data (RFC 6979 section 3.6). The secret and the
+// nonce only go through constant time scalar arithmetic
+func (pp PrivateKey) SignWithEntropy(z *big.Int, extraEntropy []byte) *Signature {
+	k := pp.deterministicK(z, extraEntropy)
+	kG := scalarBaseMult(k)
+
+	var r, secret, kInv, re, s scalar
+	r.setBig(s256PointFromProjective(&kG).x.num)
+	secret.setBig(pp.secret)
+	kInv.setBig(k)
+	kInv.inverse(&kInv)
+
+	// s = (z + r*secret) / k
+	re.mul(&r, &secret)
+	s.setBig(z)
+	s.add(&s, &re)
+	s.mul(&s, &kInv)
+
+	// use low-S so the signature is not malleable (BIP 62/146)
+	var negS scalar
+	negS.neg(&s)
+	s.cmov(&negS, s.isHigh())
+
+	return &Signature{r: r.big(), s: s.big()}
+}
+
+// deterministicK generates the nonce k with HMAC-SHA256 as described in
+// RFC 6979 section 3.2
+func (pp PrivateKey) deterministicK(z *big.Int, extraEntropy []byte) *big.Int {
+	k := make([]byte, 32)
+	v := bytes.Repeat([]byte{0x01}, 32)
+
+	zc := new(big.Int).Set(z)
+	if zc.Cmp(N) >= 0 {
+		zc.Sub(zc, N)
+	}
+	zBytes := zc.FillBytes(make([]byte, 32))
+	var secret scalar
+	secretBytes := secret.setBig(pp.secret).bytes()
+
+	k = hmacSha256(k, v, []byte{0x00}, secretBytes[:], zBytes, extraEntropy)
+	v = hmacSha256(k, v)
+	k = hmacSha256(k, v, []byte{0x01}, secretBytes[:], zBytes, extraEntropy)
+	v = hmacSha256(k, v)
+
+	for {
+		v = hmacSha256(k, v)
+		candidate := new(big.Int).SetBytes(v)
+		if candidate.Sign() > 0 && candidate.Cmp(N) < 0 {
+			return candidate
+		}
+		k = hmacSha256(k, v, []byte{0x00})
+		v = hmacSha256(k, v)
+	}
+}
+
+func hmacSha256(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// Wif serializes the key in wallet import format
+func (pp PrivateKey) Wif(compressed, testnet bool) string {
+	secretBytes := make([]byte, 32)
+	secretBytes = new(big.Int).Set(pp.secret).FillBytes(secretBytes)
+
+	var prefix []byte
+	if testnet {
+		prefix = []byte{0xef}
+	} else {
+		prefix = []byte{0x80}
+	}
+
+	var suffix []byte
+	if compressed {
+		suffix = []byte{0x01}
+	} else {
+		suffix = []byte{}
+	}
+
+	payload := bytes.Join([][]byte{prefix, secretBytes, suffix}, []byte{})
+	return encoding.Base58EncodeChecksum(payload)
+}
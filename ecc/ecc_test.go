@@ -0,0 +1,772 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+// the constructors below fail the test instead of returning an error
+func newTestFieldElement(t *testing.T, num, prime *big.Int) *FieldElement {
+	t.Helper()
+	e, err := NewFieldElement(num, prime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func newTestPoint(t *testing.T, x, y, a, b FieldElement) *Point {
+	t.Helper()
+	p, err := NewPoint(x, y, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func newTestS256Point(t *testing.T, x, y *big.Int) *Point {
+	t.Helper()
+	p, err := NewS256Point(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestNewFieldElement(t *testing.T) {
+	prime := big.NewInt(31)
+
+	cases := []struct {
+		num  *big.Int
+		want error
+	}{
+		{big.NewInt(0), nil},
+		{big.NewInt(30), nil},
+		{big.NewInt(31), ErrNotInField},
+		{big.NewInt(-1), ErrNotInField},
+	}
+
+	for _, test := range cases {
+		_, err := NewFieldElement(test.num, prime)
+		if !errors.Is(err, test.want) {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.want, err)
+		}
+	}
+	if prime.Cmp(big.NewInt(31)) != 0 {
+		t.Errorf("expected '%v' but got '%v' instead\n", 31, prime)
+	}
+}
+
+func TestDifferentFieldsPanic(t *testing.T) {
+	a := FieldElement{num: big.NewInt(2), prime: big.NewInt(31)}
+	b := FieldElement{num: big.NewInt(2), prime: big.NewInt(57)}
+
+	assert.Panics(t, func() { a.Add(b) })
+	assert.Panics(t, func() { a.Sub(b) })
+	assert.Panics(t, func() { a.Mul(b) })
+	assert.Panics(t, func() { a.Div(b) })
+
+	inf := FieldElement{}
+	p := Point{x: inf, y: inf, a: a, b: a}
+	q := Point{x: inf, y: inf, a: b, b: b}
+	assert.Panics(t, func() { p.Add(q) })
+}
+
+func TestNeFieldElement(t *testing.T) {
+	element1 := big.NewInt(2)
+	element2 := big.NewInt(15)
+	prime := big.NewInt(31)
+
+	a := newTestFieldElement(t, element1, prime)
+	b := newTestFieldElement(t, element1, prime)
+	c := newTestFieldElement(t, element2, prime)
+
+	cases := []struct {
+		e1   FieldElement
+		e2   FieldElement
+		want bool
+	}{
+		{*a, *b, false},
+		{*a, *c, true},
+	}
+
+	for _, test := range cases {
+		ne := test.e1.Ne(test.e2)
+		if ne != test.want {
+			t.Errorf("expected '%t' but got '%t' instead\n", test.want, ne)
+		}
+	}
+}
+
+func TestAddFieldElement(t *testing.T) {
+	prime := big.NewInt(31)
+	prime2 := big.NewInt(57)
+
+	element1 := big.NewInt(2)
+	element2 := big.NewInt(15)
+	element3 := big.NewInt(17)
+	element4 := big.NewInt(21)
+	element5 := big.NewInt(44)
+	element6 := big.NewInt(33)
+	element7 := big.NewInt(56)
+	element8 := big.NewInt(52)
+
+	a := newTestFieldElement(t, element1, prime)
+	b := newTestFieldElement(t, element2, prime)
+
+	c := newTestFieldElement(t, element3, prime)
+	d := newTestFieldElement(t, element4, prime)
+
+	e := newTestFieldElement(t, element5, prime2)
+	f := newTestFieldElement(t, element6, prime2)
+
+	g := newTestFieldElement(t, element7, prime2)
+	h := newTestFieldElement(t, element8, prime2)
+
+	cases := []struct {
+		e1   FieldElement
+		e2   FieldElement
+		want FieldElement
+	}{
+		{*a, *b, FieldElement{num: big.NewInt(17), prime: prime}},
+		{*c, *d, FieldElement{num: big.NewInt(7), prime: prime}},
+		{*e, *f, FieldElement{num: big.NewInt(20), prime: prime2}},
+		//{*e, *f, FieldElement{num: 20, prime: prime2}},
+		{*g, *h, FieldElement{num: big.NewInt(51), prime: prime2}},
+		//{*g, *h, FieldElement{num: 51, prime: prime2}},
+	}
+
+	for _, test := range cases {
+		result := test.e1.Add(test.e2)
+		if result.Ne(test.want) {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.want.num, result.num)
+		}
+	}
+}
+
+func TestSubFieldElement(t *testing.T) {
+	prime := big.NewInt(31)
+	prime2 := big.NewInt(57)
+
+	element1 := big.NewInt(29)
+	element2 := big.NewInt(4)
+	element3 := big.NewInt(15)
+	element4 := big.NewInt(30)
+	element5 := big.NewInt(9)
+	element6 := big.NewInt(29)
+
+	a := newTestFieldElement(t, element1, prime)
+	b := newTestFieldElement(t, element2, prime)
+
+	c := newTestFieldElement(t, element3, prime)
+	d := newTestFieldElement(t, element4, prime)
+
+	e := newTestFieldElement(t, element5, prime2)
+	f := newTestFieldElement(t, element6, prime2)
+
+	cases := []struct {
+		e1   FieldElement
+		e2   FieldElement
+		want FieldElement
+	}{
+		//{*a, *b, FieldElement{num: 25, prime: 31}},
+		{*a, *b, FieldElement{num: big.NewInt(25), prime: prime}},
+		//{*c, *d, FieldElement{num: 16, prime: 31}},
+		{*c, *d, FieldElement{num: big.NewInt(16), prime: prime}},
+		//{*e, *f, FieldElement{num: 37, prime: 57}},
+		{*e, *f, FieldElement{num: big.NewInt(37), prime: prime2}},
+	}
+
+	for _, test := range cases {
+		result := test.e1.Sub(test.e2)
+		if result.Ne(test.want) {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.want.num, result.num)
+		}
+	}
+}
+
+func TestMulFieldElement(t *testing.T) {
+	prime := big.NewInt(31)
+	prime2 := big.NewInt(97)
+
+	element1 := big.NewInt(24)
+	element2 := big.NewInt(19)
+	element3 := big.NewInt(95)
+	element4 := big.NewInt(45)
+	element5 := big.NewInt(7)
+	element6 := big.NewInt(31)
+	element7 := big.NewInt(5)
+	element8 := big.NewInt(18)
+
+	a := newTestFieldElement(t, element1, prime)
+	b := newTestFieldElement(t, element2, prime)
+
+	c := newTestFieldElement(t, element3, prime2)
+	d := newTestFieldElement(t, element4, prime2)
+
+	e := newTestFieldElement(t, element5, prime2)
+	f := newTestFieldElement(t, element6, prime2)
+
+	g := newTestFieldElement(t, element7, prime)
+	powresult := g.Pow(big.NewInt(5))
+	h := newTestFieldElement(t, element8, prime)
+
+	cases := []struct {
+		e1   FieldElement
+		e2   FieldElement
+		want FieldElement
+	}{
+		//{*a, *b, FieldElement{num: 22, prime: 31}},
+		{*a, *b, FieldElement{num: big.NewInt(22), prime: prime}},
+		//{*c, *d, FieldElement{num: 7, prime: 97}},
+		{*c, *d, FieldElement{num: big.NewInt(7), prime: prime2}},
+		//{*e, *f, FieldElement{num: 23, prime: 97}},
+		{*e, *f, FieldElement{num: big.NewInt(23), prime: prime2}},
+		//{*powresult, *h, FieldElement{num: 16, prime: 31}},
+		{*powresult, *h, FieldElement{num: big.NewInt(16), prime: prime}},
+	}
+
+	for _, test := range cases {
+		result := test.e1.Mul(test.e2)
+		if result.Ne(test.want) {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.want.num, result.num)
+		}
+	}
+}
+
+func TestPowFieldElement(t *testing.T) {
+	prime := big.NewInt(31)
+
+	element1 := big.NewInt(17)
+	element2 := big.NewInt(5)
+
+	a := newTestFieldElement(t, element1, prime)
+	b := newTestFieldElement(t, element2, prime)
+
+	cases := []struct {
+		e1   FieldElement
+		exp  *big.Int
+		want FieldElement
+	}{
+		{*a, big.NewInt(3), FieldElement{num: big.NewInt(15), prime: prime}},
+		{*b, big.NewInt(5), FieldElement{num: big.NewInt(25), prime: prime}},
+		{*a, big.NewInt(-3), FieldElement{num: big.NewInt(29), prime: prime}},
+	}
+
+	for _, test := range cases {
+		result := test.e1.Pow(test.exp)
+		if result.Ne(test.want) {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.want.num, result.num)
+		}
+	}
+}
+
+func TestDivFieldElement(t *testing.T) {
+	prime := big.NewInt(31)
+
+	element1 := big.NewInt(3)
+	element2 := big.NewInt(24)
+
+	a := newTestFieldElement(t, element1, prime)
+	b := newTestFieldElement(t, element2, prime)
+
+	cases := []struct {
+		e1   FieldElement
+		e2   FieldElement
+		want FieldElement
+	}{
+		//{*a, *b, FieldElement{num: 4, prime: 31}},
+		{*a, *b, FieldElement{num: big.NewInt(4), prime: prime}},
+	}
+
+	for _, test := range cases {
+		result := test.e1.Div(test.e2)
+		if result.Ne(test.want) {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.want.num, result.num)
+		}
+	}
+}
+
+func TestNePoint(t *testing.T) {
+	prime := big.NewInt(98)
+	a := newTestFieldElement(t, big.NewInt(5), prime)
+	b := newTestFieldElement(t, big.NewInt(7), prime)
+
+	x1 := big.NewInt(3)
+	y1 := big.NewInt(7)
+	x2 := big.NewInt(18)
+	y2 := big.NewInt(77)
+	x3 := big.NewInt(2)
+	y3 := big.NewInt(5)
+
+	ap := newTestPoint(t, *newTestFieldElement(t, x1, prime), *newTestFieldElement(t, y1, prime), *a, *b)
+	bp := newTestPoint(t, *newTestFieldElement(t, x2, prime), *newTestFieldElement(t, y2, prime), *a, *b)
+
+	cp := newTestPoint(t, *newTestFieldElement(t, x3, prime), *newTestFieldElement(t, y3, prime), *a, *b)
+	dp := newTestPoint(t, *newTestFieldElement(t, x3, prime), *newTestFieldElement(t, y3, prime), *a, *b)
+
+	cases := []struct {
+		e1   Point
+		e2   Point
+		want bool
+	}{
+		{*ap, *bp, true},
+		{*cp, *dp, false},
+	}
+
+	for _, test := range cases {
+		ne := test.e1.Ne(test.e2)
+		if ne != test.want {
+			t.Errorf("expected '%t' but got '%t' instead\n", test.want, ne)
+		}
+	}
+}
+
+func TestOnCurve(t *testing.T) {
+	prime := big.NewInt(223)
+	a := newTestFieldElement(t, big.NewInt(0), prime)
+	b := newTestFieldElement(t, big.NewInt(7), prime)
+
+	x1 := big.NewInt(192)
+	y1 := big.NewInt(105)
+	x2 := big.NewInt(17)
+	y2 := big.NewInt(56)
+	x3 := big.NewInt(1)
+	y3 := big.NewInt(193)
+
+	x4 := big.NewInt(200)
+	y4 := big.NewInt(119)
+	x5 := big.NewInt(42)
+	y5 := big.NewInt(99)
+
+	validPoints := [][2]*big.Int{
+		{x1, y1},
+		{x2, y2},
+		{x3, y3},
+	}
+
+	invalidPoints := [][2]*big.Int{
+		{x4, y4},
+		{x5, y5},
+	}
+
+	for _, point := range validPoints {
+		x := newTestFieldElement(t, point[0], prime)
+		y := newTestFieldElement(t, point[1], prime)
+		_, err := NewPoint(*x, *y, *a, *b)
+		if err != nil {
+			t.Errorf("point %v should be valid but got '%v'\n", point, err)
+		}
+	}
+
+	for _, point := range invalidPoints {
+		x := newTestFieldElement(t, point[0], prime)
+		y := newTestFieldElement(t, point[1], prime)
+		_, err := NewPoint(*x, *y, *a, *b)
+		if !errors.Is(err, ErrNotOnCurve) {
+			t.Errorf("expected '%v' but got '%v' instead\n", ErrNotOnCurve, err)
+		}
+	}
+
+}
+
+func TestAddPointFiniteField(t *testing.T) {
+	prime := big.NewInt(223)
+	a := newTestFieldElement(t, big.NewInt(0), prime)
+	b := newTestFieldElement(t, big.NewInt(7), prime)
+
+	x1 := big.NewInt(192)
+	y1 := big.NewInt(105)
+	x2 := big.NewInt(17)
+	y2 := big.NewInt(56)
+	x3 := big.NewInt(170)
+	y3 := big.NewInt(142)
+
+	x4 := big.NewInt(170)
+	y4 := big.NewInt(142)
+	x5 := big.NewInt(60)
+	y5 := big.NewInt(139)
+	x6 := big.NewInt(220)
+	y6 := big.NewInt(181)
+
+	x7 := big.NewInt(47)
+	y7 := big.NewInt(71)
+	x8 := big.NewInt(17)
+	y8 := big.NewInt(56)
+	x9 := big.NewInt(215)
+	y9 := big.NewInt(68)
+
+	x10 := big.NewInt(143)
+	y10 := big.NewInt(98)
+	x11 := big.NewInt(76)
+	y11 := big.NewInt(66)
+	x12 := big.NewInt(47)
+	y12 := big.NewInt(71)
+
+	cases := [][6]*big.Int{
+		{x1, y1, x2, y2, x3, y3},
+		{x4, y4, x5, y5, x6, y6},
+		{x7, y7, x8, y8, x9, y9},
+		{x10, y10, x11, y11, x12, y12},
+	}
+
+	for _, test := range cases {
+		x1 := newTestFieldElement(t, test[0], prime)
+		y1 := newTestFieldElement(t, test[1], prime)
+		p1 := newTestPoint(t, *x1, *y1, *a, *b)
+
+		x2 := newTestFieldElement(t, test[2], prime)
+		y2 := newTestFieldElement(t, test[3], prime)
+		p2 := newTestPoint(t, *x2, *y2, *a, *b)
+
+		x3 := newTestFieldElement(t, test[4], prime)
+		y3 := newTestFieldElement(t, test[5], prime)
+		p3 := newTestPoint(t, *x3, *y3, *a, *b)
+
+		sum := p1.Add(*p2)
+		if p3.Ne(*sum) {
+			t.Errorf("expected '%v' but got '%v' instead\n", p3.x.num, *p1.Add(*p2).x.num)
+		}
+	}
+}
+
+func TestRmul(t *testing.T) {
+	prime := big.NewInt(223)
+	a := newTestFieldElement(t, big.NewInt(0), prime)
+	b := newTestFieldElement(t, big.NewInt(7), prime)
+
+	coef := big.NewInt(2)
+	coef2 := big.NewInt(4)
+	coef3 := big.NewInt(8)
+	coef4 := big.NewInt(21)
+
+	x1 := big.NewInt(192)
+	y1 := big.NewInt(105)
+	x2 := big.NewInt(49)
+	y2 := big.NewInt(71)
+
+	x3 := big.NewInt(143)
+	y3 := big.NewInt(98)
+	x4 := big.NewInt(64)
+	y4 := big.NewInt(168)
+
+	x5 := big.NewInt(47)
+	y5 := big.NewInt(71)
+	x6 := big.NewInt(36)
+	y6 := big.NewInt(111)
+
+	x7 := big.NewInt(194)
+	y7 := big.NewInt(51)
+
+	x8 := big.NewInt(116)
+	y8 := big.NewInt(55)
+
+	cases := [][5]*big.Int{
+		{coef, x1, y1, x2, y2},
+		{coef, x3, y3, x4, y4},
+		{coef, x5, y5, x6, y6},
+		{coef2, x5, y5, x7, y7},
+		{coef3, x5, y5, x8, y8},
+		{coef4, x5, y5, nil, nil},
+	}
+
+	for _, test := range cases {
+		x1 := newTestFieldElement(t, test[1], prime)
+		y1 := newTestFieldElement(t, test[2], prime)
+		p1 := newTestPoint(t, *x1, *y1, *a, *b)
+
+		var x2 *FieldElement
+		var y2 *FieldElement
+		var p2 *Point
+
+		if test[3] == nil {
+			var infelement FieldElement
+			p2 = newTestPoint(t, infelement, infelement, *a, *b)
+		} else {
+			x2 = newTestFieldElement(t, test[3], prime)
+			y2 = newTestFieldElement(t, test[4], prime)
+			p2 = newTestPoint(t, *x2, *y2, *a, *b)
+		}
+
+		mul := p1.Rmul(test[0])
+		if p2.Ne(*mul) {
+			t.Errorf("expected '%v' but got '%v' instead\n", p2.x.num, *p1.Rmul(test[0]).x.num)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	if G.RmulS256(N).x.num != nil {
+		t.Errorf("expected '%v' but got '%v' instead\n", nil, G.RmulS256(N).x.num)
+	}
+}
+
+func TestPublicPoint(t *testing.T) {
+	secret := big.NewInt(7)
+	secret2 := big.NewInt(1485)
+	secret3 := new(big.Int).Exp(big.NewInt(2), big.NewInt(128), nil)
+
+	exp := new(big.Int).Exp(big.NewInt(2), big.NewInt(240), nil)
+	exp2 := new(big.Int).Exp(big.NewInt(2), big.NewInt(31), nil)
+	secret4 := exp.Add(exp, exp2)
+
+	points := [][3]*big.Int{
+		{secret, encoding.FromHex("5cbdf0646e5db4eaa398f365f2ea7a0e3d419b7e0330e39ce92bddedcac4f9bc"),
+			encoding.FromHex("6aebca40ba255960a3178d6d861a54dba813d0b813fde7b5a5082628087264da")},
+		{secret2, encoding.FromHex("c982196a7466fbbbb0e27a940b6af926c1a74d5ad07128c82824a11b5398afda"),
+			encoding.FromHex("7a91f9eae64438afb9ce6448a1c133db2d8fb9254e4546b6f001637d50901f55")},
+		{secret3, encoding.FromHex("8f68b9d2f63b5f339239c1ad981f162ee88c5678723ea3351b7b444c9ec4c0da"),
+			encoding.FromHex("662a9f2dba063986de1d90c2b6be215dbbea2cfe95510bfdf23cbf79501fff82")},
+		{secret4, encoding.FromHex("9577ff57c8234558f293df502ca4f09cbc65a6572c842b39b366f21717945116"),
+			encoding.FromHex("10b49c67fa9365ad7b90dab070be339a1daf9052373ec30ffae4f72d5e66d053")},
+	}
+
+	for _, test := range points {
+		point := newTestS256Point(t, test[1], test[2])
+		pubPoint := G.RmulS256(test[0])
+
+		if pubPoint.Ne(*point) {
+			t.Errorf("expected '%v' but got '%v' instead\n", pubPoint.String(), point.String())
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	x1 := encoding.FromHex("887387e452b8eacc4acfde10d9aaf7f6d9a0f975aabb10d006e4da568744d06c")
+	y1 := encoding.FromHex("61de6d95231cd89026e286df3b6ae4a894a3378e393e93a0f45b666329a0ae34")
+	point := newTestS256Point(t, x1, y1)
+
+	cases := []struct {
+		z    *big.Int
+		r    *big.Int
+		s    *big.Int
+		want bool
+	}{
+		{encoding.FromHex("ec208baa0fc1c19f708a9ca96fdeff3ac3f230bb4a7ba4aede4942ad003c0f60"),
+			encoding.FromHex("ac8d1c87e51d0d441be8b3dd5b05c8795b48875dffe00b7ffcfac23010d3a395"),
+			encoding.FromHex("68342ceff8935ededd102dd876ffd6ba72d6a427a3edb13d26eb0781cb423c4"), true},
+		{encoding.FromHex("7c076ff316692a3d7eb3c3bb0f8b1488cf72e1afcd929e29307032997a838a3d"),
+			encoding.FromHex("eff69ef2b1bd93a66ed5219add4fb51e11a840f404876325a1e8ffe0529a2c"),
+			encoding.FromHex("c7207fee197d27c618aea621406f6bf5ef6fca38681d82b2f06fddbdce6feab6"), true},
+	}
+
+	for _, test := range cases {
+		verified := point.VerifySignature(Signature{r: test.r, s: test.s}, test.z)
+		if verified != test.want {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.want, verified)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	randk, _ := rand.Int(rand.Reader, N)
+	privKey := NewPrivateKey(randk)
+
+	randz := new(big.Int).Exp(big.NewInt(2), big.NewInt(256), nil)
+	z, _ := rand.Int(rand.Reader, randz)
+
+	signature := privKey.Sign(z)
+
+	verified := privKey.point.VerifySignature(*signature, z)
+	if verified != true {
+		t.Errorf("expected '%v' but got '%v' instead\n", true, verified)
+	}
+}
+
+func TestSec(t *testing.T) {
+	cases := []struct {
+		coefficient      *big.Int
+		wantUncompressed string
+		wantCompressed   string
+	}{
+		{big.NewInt(997002999),
+			"049d5ca49670cbe4c3bfa84c96a8c87df086c6ea6a24ba6b809c9de234496808d56fa15cc7f3d38cda98dee2419f415b7513dde1301f8643cd9245aea7f3f911f9",
+			"039d5ca49670cbe4c3bfa84c96a8c87df086c6ea6a24ba6b809c9de234496808d5"},
+		{big.NewInt(123),
+			"04a598a8030da6d86c6bc7f2f5144ea549d28211ea58faa70ebf4c1e665c1fe9b5204b5d6f84822c307e4b4a7140737aec23fc63b65b35f86a10026dbd2d864e6b",
+			"03a598a8030da6d86c6bc7f2f5144ea549d28211ea58faa70ebf4c1e665c1fe9b5"},
+		{big.NewInt(42424242),
+			"04aee2e7d843f7430097859e2bc603abcc3274ff8169c1a469fee0f20614066f8e21ec53f40efac47ac1c5211b2123527e0e9b57ede790c4da1e72c91fb7da54a3",
+			"03aee2e7d843f7430097859e2bc603abcc3274ff8169c1a469fee0f20614066f8e"},
+	}
+
+	for _, test := range cases {
+		point := G.RmulS256(test.coefficient)
+		uncompressed := hex.EncodeToString(point.Sec(false))
+		if test.wantUncompressed != uncompressed {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.wantUncompressed, uncompressed)
+		}
+
+		compressed := hex.EncodeToString(point.Sec(true))
+		if test.wantCompressed != compressed {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.wantCompressed, compressed)
+		}
+	}
+}
+
+func TestSignatureDerParse(t *testing.T) {
+	lim1 := new(big.Int).Exp(big.NewInt(2), big.NewInt(256), nil)
+	lim2 := new(big.Int).Exp(big.NewInt(2), big.NewInt(255), nil)
+
+	r1, _ := rand.Int(rand.Reader, lim1)
+	s1, _ := rand.Int(rand.Reader, lim2)
+	r2, _ := rand.Int(rand.Reader, lim1)
+	s2, _ := rand.Int(rand.Reader, lim2)
+
+	cases := []struct {
+		r *big.Int
+		s *big.Int
+	}{
+		{big.NewInt(1), big.NewInt(2)},
+		{r1, s1},
+		{r2, s2},
+	}
+
+	for _, test := range cases {
+		sig := &Signature{r: test.r, s: test.s}
+		der := sig.Der()
+		sig2, err := ParseSignature(der)
+		if err != nil {
+			t.Errorf("error parsing signature '%v'", err)
+		}
+		assert.Equal(t, test.r, sig2.r, "Signature.r does not match")
+		assert.Equal(t, test.s, sig2.s, "Signature.s does not match")
+	}
+}
+
+func TestSignDeterministic(t *testing.T) {
+	cases := []struct {
+		secret  *big.Int
+		message string
+		k       string
+		r       string
+		s       string
+	}{
+		{big.NewInt(1), "Satoshi Nakamoto",
+			"8f8a276c19f4149656b280621e358cce24f5f52542772691ee69063b74f15d15",
+			"934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8",
+			"2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5"},
+		{big.NewInt(1), "All those moments will be lost in time, like tears in rain. Time to die...",
+			"38aa22d72376b4dbc472e06c3ba403ee0a394da63fc58d88686c611aba98d6b3",
+			"8600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b",
+			"547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc21"},
+		{new(big.Int).Sub(N, big.NewInt(1)), "Satoshi Nakamoto",
+			"33a19b60e25fb6f4435af53a3d42d493644827367e6453928554f43e49aa6f90",
+			"fd567d121db66e382991534ada77a6bd3106f0a1098c231e47993447cd6af2d0",
+			"6b39cd0eb1bc8603e159ef5c20a5c8ad685a45b06ce9bebed3f153d10d93bed5"},
+	}
+
+	for _, test := range cases {
+		hash := sha256.Sum256([]byte(test.message))
+		z := new(big.Int).SetBytes(hash[:])
+		privKey := NewPrivateKey(test.secret)
+
+		k := privKey.deterministicK(z, nil)
+		assert.Equal(t, encoding.FromHex(test.k), k, "k does not match")
+
+		signature := privKey.Sign(z)
+		assert.Equal(t, encoding.FromHex(test.r), signature.r, "Signature.r does not match")
+		assert.Equal(t, encoding.FromHex(test.s), signature.s, "Signature.s does not match")
+		assert.True(t, signature.IsLowS(), "signature should be low-S")
+		assert.True(t, privKey.point.VerifySignature(*signature, z))
+	}
+}
+
+func TestSignWithEntropy(t *testing.T) {
+	privKey := NewPrivateKey(big.NewInt(8675309))
+	hash := sha256.Sum256([]byte("Programming Bitcoin!"))
+	z := new(big.Int).SetBytes(hash[:])
+
+	entropy := make([]byte, 32)
+	entropy[0] = 1
+
+	signature := privKey.SignWithEntropy(z, entropy)
+	assert.True(t, privKey.point.VerifySignature(*signature, z))
+	assert.NotEqual(t, privKey.Sign(z).r, signature.r, "extra entropy should change the nonce")
+	assert.Equal(t, signature, privKey.SignWithEntropy(z, entropy), "signatures with same entropy do not match")
+}
+
+func TestParseDERSignature(t *testing.T) {
+	cases := []struct {
+		der   string
+		valid bool
+	}{
+		{"3044022074f3a1d5c77d9d1aad4fa6e4c1c9a5ed18b2e1d10ec9b0ab5b4b7b4c4b5b7b8b02205a7b3c1d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293", true},
+		// R with a leading zero to keep it positive
+		{"3045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed", true},
+		// R negative
+		{"3044022084f3a1d5c77d9d1aad4fa6e4c1c9a5ed18b2e1d10ec9b0ab5b4b7b4c4b5b7b8b02205a7b3c1d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293", false},
+		// R excessively padded
+		{"304502210074f3a1d5c77d9d1aad4fa6e4c1c9a5ed18b2e1d10ec9b0ab5b4b7b4c4b5b7b8b02205a7b3c1d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293", false},
+		// S negative
+		{"3044022074f3a1d5c77d9d1aad4fa6e4c1c9a5ed18b2e1d10ec9b0ab5b4b7b4c4b5b7b8b0220da7b3c1d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293", false},
+		// S excessively padded
+		{"3045022074f3a1d5c77d9d1aad4fa6e4c1c9a5ed18b2e1d10ec9b0ab5b4b7b4c4b5b7b8b0221005a7b3c1d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293", false},
+		// wrong total length
+		{"3045022074f3a1d5c77d9d1aad4fa6e4c1c9a5ed18b2e1d10ec9b0ab5b4b7b4c4b5b7b8b02205a7b3c1d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293", false},
+		// wrong type
+		{"3144022074f3a1d5c77d9d1aad4fa6e4c1c9a5ed18b2e1d10ec9b0ab5b4b7b4c4b5b7b8b02205a7b3c1d8e9f0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293", false},
+		// zero length R
+		{"3006020002020101", false},
+		// trailing garbage
+		{"3044022074f3a1d5c77d9d1aad4fa6e4c1c9a5ed18b2e1d10ec9b0ab5b4b7b4c4b5b7b8b02205a7b3c1d8e9f0a1b2c3d4e5f6071829300", false},
+	}
+
+	for _, test := range cases {
+		der, _ := hex.DecodeString(test.der)
+		sig, err := ParseDERSignature(der)
+		if test.valid {
+			if assert.NoError(t, err, test.der) {
+				assert.Equal(t, der, sig.Der(), "DER serialization does not round trip")
+			}
+		} else {
+			assert.Error(t, err, test.der)
+		}
+	}
+}
+
+func TestIsLowS(t *testing.T) {
+	privKey := NewPrivateKey(big.NewInt(12345))
+	z := new(big.Int).Exp(big.NewInt(2), big.NewInt(200), nil)
+	signature := privKey.Sign(z)
+	assert.True(t, signature.IsLowS())
+
+	highS := NewSignature(signature.r, new(big.Int).Sub(N, signature.s))
+	assert.False(t, highS.IsLowS())
+	// the high-S twin is still a valid ECDSA signature
+	assert.True(t, privKey.point.VerifySignature(*highS, z))
+}
+
+func TestParsePubKey(t *testing.T) {
+	p := P.FillBytes(make([]byte, 32))
+	cases := []struct {
+		sec  []byte
+		want error
+	}{
+		{G.Sec(true), nil},
+		{G.Sec(false), nil},
+		// x = P is not a field element
+		{append([]byte{0x02}, p...), ErrNotInField},
+		{append(append([]byte{0x04}, p...), G.y.num.FillBytes(make([]byte, 32))...), ErrNotInField},
+		// x of G as its y
+		{append(append([]byte{0x04}, G.x.num.FillBytes(make([]byte, 32))...), G.x.num.FillBytes(make([]byte, 32))...), ErrNotOnCurve},
+	}
+
+	for _, test := range cases {
+		point, err := ParsePubKey(test.sec)
+		if !errors.Is(err, test.want) {
+			t.Errorf("expected '%v' but got '%v' instead\n", test.want, err)
+			continue
+		}
+		if err == nil && point.Ne(*G) {
+			t.Errorf("expected '%v' but got '%v' instead\n", G, point)
+		}
+	}
+}
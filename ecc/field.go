@@ -0,0 +1,212 @@
+package ecc
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// fieldVal is an element of the secp256k1 field stored as four 64 bit limbs,
+// least significant limb first. Every operation leaves the value fully
+// reduced mod P and none of them branch on the value, so they can be used
+// with secret data
+type fieldVal [4]uint64
+
+var (
+	fieldP = fieldVal{0xfffffffefffffc2f, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff}
+
+	// P - 2, the exponent used to invert
+	fieldPMinus2 = fieldVal{0xfffffffefffffc2d, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff}
+
+	// (P + 1) / 4, the exponent used for square roots since P = 3 mod 4
+	fieldSqrtExp = fieldVal{0xffffffffbfffff0c, 0xffffffffffffffff, 0xffffffffffffffff, 0x3fffffffffffffff}
+)
+
+// fieldC is 2^256 - P, so 2^256 = fieldC mod P
+const fieldC = 0x1000003d1
+
+// setBig sets f to n mod P
+func (f *fieldVal) setBig(n *big.Int) *fieldVal {
+	var b [32]byte
+	new(big.Int).Mod(n, P).FillBytes(b[:])
+	return f.setBytes(&b)
+}
+
+// setBytes sets f to the big endian number in b, which must be below P
+func (f *fieldVal) setBytes(b *[32]byte) *fieldVal {
+	for i := 0; i < 4; i++ {
+		f[3-i] = uint64(b[i*8])<<56 | uint64(b[i*8+1])<<48 | uint64(b[i*8+2])<<40 | uint64(b[i*8+3])<<32 |
+			uint64(b[i*8+4])<<24 | uint64(b[i*8+5])<<16 | uint64(b[i*8+6])<<8 | uint64(b[i*8+7])
+	}
+	return f
+}
+
+// bytes returns f as 32 big endian bytes
+func (f *fieldVal) bytes() [32]byte {
+	var b [32]byte
+	for i := 0; i < 4; i++ {
+		limb := f[3-i]
+		for j := 0; j < 8; j++ {
+			b[i*8+j] = byte(limb >> (56 - 8*j))
+		}
+	}
+	return b
+}
+
+func (f *fieldVal) big() *big.Int {
+	b := f.bytes()
+	return new(big.Int).SetBytes(b[:])
+}
+
+func (f *fieldVal) setInt(n uint64) *fieldVal {
+	*f = fieldVal{n, 0, 0, 0}
+	return f
+}
+
+func (f *fieldVal) isZero() bool {
+	return f[0]|f[1]|f[2]|f[3] == 0
+}
+
+func (f *fieldVal) equal(g *fieldVal) bool {
+	return (f[0]^g[0])|(f[1]^g[1])|(f[2]^g[2])|(f[3]^g[3]) == 0
+}
+
+func (f *fieldVal) isOdd() bool {
+	return f[0]&1 == 1
+}
+
+// reduce sets f to r + carry*2^256 mod P. The input must be below 2P
+func (f *fieldVal) reduce(r *fieldVal, carry uint64) *fieldVal {
+	var t fieldVal
+	var b uint64
+	t[0], b = bits.Sub64(r[0], fieldP[0], 0)
+	t[1], b = bits.Sub64(r[1], fieldP[1], b)
+	t[2], b = bits.Sub64(r[2], fieldP[2], b)
+	t[3], b = bits.Sub64(r[3], fieldP[3], b)
+
+	// keep r only when it was already below P: subtracting borrowed and
+	// there was no carry out of the top limb
+	mask := -(b &^ carry)
+	f[0] = r[0]&mask | t[0]&^mask
+	f[1] = r[1]&mask | t[1]&^mask
+	f[2] = r[2]&mask | t[2]&^mask
+	f[3] = r[3]&mask | t[3]&^mask
+	return f
+}
+
+func (f *fieldVal) add(a, b *fieldVal) *fieldVal {
+	var r fieldVal
+	var c uint64
+	r[0], c = bits.Add64(a[0], b[0], 0)
+	r[1], c = bits.Add64(a[1], b[1], c)
+	r[2], c = bits.Add64(a[2], b[2], c)
+	r[3], c = bits.Add64(a[3], b[3], c)
+	return f.reduce(&r, c)
+}
+
+func (f *fieldVal) sub(a, b *fieldVal) *fieldVal {
+	var r fieldVal
+	var borrow, c uint64
+	r[0], borrow = bits.Sub64(a[0], b[0], 0)
+	r[1], borrow = bits.Sub64(a[1], b[1], borrow)
+	r[2], borrow = bits.Sub64(a[2], b[2], borrow)
+	r[3], borrow = bits.Sub64(a[3], b[3], borrow)
+
+	// add P back if the subtraction wrapped
+	mask := -borrow
+	f[0], c = bits.Add64(r[0], fieldP[0]&mask, 0)
+	f[1], c = bits.Add64(r[1], fieldP[1]&mask, c)
+	f[2], c = bits.Add64(r[2], fieldP[2]&mask, c)
+	f[3], _ = bits.Add64(r[3], fieldP[3]&mask, c)
+	return f
+}
+
+func (f *fieldVal) neg(a *fieldVal) *fieldVal {
+	var zero fieldVal
+	return f.sub(&zero, a)
+}
+
+func (f *fieldVal) mul(a, b *fieldVal) *fieldVal {
+	var t [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j] = lo
+			carry = hi
+		}
+		t[i+4] = carry
+	}
+	return f.reduce512(&t)
+}
+
+func (f *fieldVal) sqr(a *fieldVal) *fieldVal {
+	return f.mul(a, a)
+}
+
+// reduce512 sets f to the 512 bit number t mod P
+func (f *fieldVal) reduce512(t *[8]uint64) *fieldVal {
+	// t = lo + hi*2^256 = lo + hi*fieldC mod P
+	var r fieldVal
+	var carry uint64
+	for i := 0; i < 4; i++ {
+		hi, lo := bits.Mul64(t[4+i], fieldC)
+		var c uint64
+		lo, c = bits.Add64(lo, t[i], 0)
+		hi += c
+		lo, c = bits.Add64(lo, carry, 0)
+		hi += c
+		r[i] = lo
+		carry = hi
+	}
+
+	// fold the 34 bits left above 2^256 the same way
+	hi, lo := bits.Mul64(carry, fieldC)
+	var c uint64
+	r[0], c = bits.Add64(r[0], lo, 0)
+	r[1], c = bits.Add64(r[1], hi, c)
+	r[2], c = bits.Add64(r[2], 0, c)
+	r[3], c = bits.Add64(r[3], 0, c)
+
+	// if that wrapped, r is now tiny and adding fieldC cannot carry again
+	r[0], c = bits.Add64(r[0], fieldC&-c, 0)
+	r[1], c = bits.Add64(r[1], 0, c)
+	r[2], c = bits.Add64(r[2], 0, c)
+	r[3], _ = bits.Add64(r[3], 0, c)
+
+	return f.reduce(&r, 0)
+}
+
+// pow sets f to a^exp. exp is public so the fixed sequence of squares and
+// multiplications does not leak a
+func (f *fieldVal) pow(a *fieldVal, exp *fieldVal) *fieldVal {
+	base := *a
+	var r fieldVal
+	r.setInt(1)
+	for i := 255; i >= 0; i-- {
+		r.sqr(&r)
+		if (exp[i/64]>>(i%64))&1 == 1 {
+			r.mul(&r, &base)
+		}
+	}
+	*f = r
+	return f
+}
+
+// inverse sets f to a^-1 using Fermat's little theorem
+func (f *fieldVal) inverse(a *fieldVal) *fieldVal {
+	return f.pow(a, &fieldPMinus2)
+}
+
+// sqrt sets f to a square root of a and reports whether a has one
+func (f *fieldVal) sqrt(a *fieldVal) bool {
+	var r, check fieldVal
+	r.pow(a, &fieldSqrtExp)
+	check.sqr(&r)
+	*f = r
+	return check.equal(a)
+}
@@ -0,0 +1,77 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestFieldVal(t *testing.T) {
+	pMinus1 := new(big.Int).Sub(P, big.NewInt(1))
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), pMinus1, new(big.Int).Sub(P, big.NewInt(2))}
+	for i := 0; i < 20; i++ {
+		r, _ := rand.Int(rand.Reader, P)
+		values = append(values, r)
+	}
+
+	for _, a := range values {
+		for _, b := range values {
+			var fa, fb, result fieldVal
+			fa.setBig(a)
+			fb.setBig(b)
+
+			want := new(big.Int).Add(a, b)
+			if result.add(&fa, &fb).big().Cmp(want.Mod(want, P)) != 0 {
+				t.Errorf("%x + %x: expected '%x' but got '%x' instead", a, b, want, result.big())
+			}
+
+			want = new(big.Int).Sub(a, b)
+			if result.sub(&fa, &fb).big().Cmp(want.Mod(want, P)) != 0 {
+				t.Errorf("%x - %x: expected '%x' but got '%x' instead", a, b, want, result.big())
+			}
+
+			want = new(big.Int).Mul(a, b)
+			if result.mul(&fa, &fb).big().Cmp(want.Mod(want, P)) != 0 {
+				t.Errorf("%x * %x: expected '%x' but got '%x' instead", a, b, want, result.big())
+			}
+		}
+
+		if a.Sign() == 0 {
+			continue
+		}
+		var fa, inv fieldVal
+		fa.setBig(a)
+		want := new(big.Int).ModInverse(a, P)
+		if inv.inverse(&fa).big().Cmp(want) != 0 {
+			t.Errorf("1 / %x: expected '%x' but got '%x' instead", a, want, inv.big())
+		}
+
+		var square, root fieldVal
+		square.sqr(&fa)
+		if !root.sqrt(&square) {
+			t.Errorf("%x should have a square root", square.big())
+		}
+		var rootSquared fieldVal
+		if !rootSquared.sqr(&root).equal(&square) {
+			t.Errorf("sqrt(%x) squared does not match", square.big())
+		}
+	}
+}
+
+func TestFieldValBytes(t *testing.T) {
+	want := fromHexBytes("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	var f fieldVal
+	f.setBytes(&want)
+	if f.bytes() != want {
+		t.Errorf("expected '%x' but got '%x' instead", want, f.bytes())
+	}
+	if f.big().Cmp(gx) != 0 {
+		t.Errorf("expected '%x' but got '%x' instead", gx, f.big())
+	}
+}
+
+func fromHexBytes(s string) [32]byte {
+	var b [32]byte
+	new(big.Int).SetBytes(mustHex(s)).FillBytes(b[:])
+	return b
+}
@@ -0,0 +1,435 @@
+package ecc
+
+import (
+	"math/big"
+	"sync"
+)
+
+// affinePoint is a secp256k1 point in affine coordinates. It is only used for
+// precomputed tables and never holds the point at infinity
+type affinePoint struct {
+	x, y fieldVal
+}
+
+// jacobianPoint is a secp256k1 point in Jacobian coordinates, where (x, y, z)
+// stands for the affine point (x/z^2, y/z^3). Adding and doubling need no
+// field inversion, only the final conversion back to affine does. z == 0 is
+// the point at infinity
+type jacobianPoint struct {
+	x, y, z fieldVal
+}
+
+func (p *jacobianPoint) setInfinity() *jacobianPoint {
+	*p = jacobianPoint{}
+	p.x.setInt(1)
+	p.y.setInt(1)
+	return p
+}
+
+func (p *jacobianPoint) isInfinity() bool {
+	return p.z.isZero()
+}
+
+func (p *jacobianPoint) setAffine(a *affinePoint) *jacobianPoint {
+	p.x = a.x
+	p.y = a.y
+	p.z.setInt(1)
+	return p
+}
+
+// toAffine converts p to affine coordinates. p must not be infinity
+func (p *jacobianPoint) toAffine() affinePoint {
+	var zinv, zinv2, zinv3 fieldVal
+	zinv.inverse(&p.z)
+	zinv2.sqr(&zinv)
+	zinv3.mul(&zinv2, &zinv)
+
+	var a affinePoint
+	a.x.mul(&p.x, &zinv2)
+	a.y.mul(&p.y, &zinv3)
+	return a
+}
+
+func (p *jacobianPoint) neg(a *jacobianPoint) *jacobianPoint {
+	p.x = a.x
+	p.y.neg(&a.y)
+	p.z = a.z
+	return p
+}
+
+// double sets p to 2a (dbl-2009-l, valid because the curve has a = 0)
+func (p *jacobianPoint) double(a *jacobianPoint) *jacobianPoint {
+	var aa, bb, cc, d, e, f, t fieldVal
+
+	aa.sqr(&a.x)     // A = X1^2
+	bb.sqr(&a.y)     // B = Y1^2
+	cc.sqr(&bb)      // C = B^2
+	d.add(&a.x, &bb) // D = 2*((X1+B)^2-A-C)
+	d.sqr(&d)
+	d.sub(&d, &aa)
+	d.sub(&d, &cc)
+	d.add(&d, &d)
+	e.add(&aa, &aa) // E = 3*A
+	e.add(&e, &aa)
+	f.sqr(&e) // F = E^2
+
+	var x3, y3, z3 fieldVal
+	x3.sub(&f, &d) // X3 = F-2*D
+	x3.sub(&x3, &d)
+	t.add(&cc, &cc) // 8*C
+	t.add(&t, &t)
+	t.add(&t, &t)
+	y3.sub(&d, &x3) // Y3 = E*(D-X3)-8*C
+	y3.mul(&y3, &e)
+	y3.sub(&y3, &t)
+	z3.mul(&a.y, &a.z) // Z3 = 2*Y1*Z1
+	z3.add(&z3, &z3)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// add sets p to a + b (add-1998-cmo-2)
+func (p *jacobianPoint) add(a, b *jacobianPoint) *jacobianPoint {
+	if a.isInfinity() {
+		*p = *b
+		return p
+	}
+	if b.isInfinity() {
+		*p = *a
+		return p
+	}
+
+	var z1z1, z2z2, u1, u2, s1, s2, h, r fieldVal
+	z1z1.sqr(&a.z)
+	z2z2.sqr(&b.z)
+	u1.mul(&a.x, &z2z2)
+	u2.mul(&b.x, &z1z1)
+	s1.mul(&a.y, &b.z)
+	s1.mul(&s1, &z2z2)
+	s2.mul(&b.y, &a.z)
+	s2.mul(&s2, &z1z1)
+	h.sub(&u2, &u1)
+	r.sub(&s2, &s1)
+
+	if h.isZero() {
+		if r.isZero() {
+			return p.double(a)
+		}
+		return p.setInfinity()
+	}
+
+	var hh, hhh, v, x3, y3, z3 fieldVal
+	hh.sqr(&h)
+	hhh.mul(&hh, &h)
+	v.mul(&u1, &hh)
+
+	x3.sqr(&r) // X3 = r^2-H^3-2*V
+	x3.sub(&x3, &hhh)
+	x3.sub(&x3, &v)
+	x3.sub(&x3, &v)
+	y3.sub(&v, &x3) // Y3 = r*(V-X3)-S1*H^3
+	y3.mul(&y3, &r)
+	s1.mul(&s1, &hhh)
+	y3.sub(&y3, &s1)
+	z3.mul(&a.z, &b.z) // Z3 = Z1*Z2*H
+	z3.mul(&z3, &h)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// addAffine sets p to a + b where b has z = 1 (madd-2004-hmv)
+func (p *jacobianPoint) addAffine(a *jacobianPoint, b *affinePoint) *jacobianPoint {
+	if a.isInfinity() {
+		return p.setAffine(b)
+	}
+
+	var z1z1, u2, s2, h, r fieldVal
+	z1z1.sqr(&a.z)
+	u2.mul(&b.x, &z1z1)
+	s2.mul(&b.y, &a.z)
+	s2.mul(&s2, &z1z1)
+	h.sub(&u2, &a.x)
+	r.sub(&s2, &a.y)
+
+	if h.isZero() {
+		if r.isZero() {
+			return p.double(a)
+		}
+		return p.setInfinity()
+	}
+
+	var hh, hhh, v, x3, y3, z3 fieldVal
+	hh.sqr(&h)
+	hhh.mul(&hh, &h)
+	v.mul(&a.x, &hh)
+
+	x3.sqr(&r)
+	x3.sub(&x3, &hhh)
+	x3.sub(&x3, &v)
+	x3.sub(&x3, &v)
+	y3.sub(&v, &x3)
+	y3.mul(&y3, &r)
+	var t fieldVal
+	t.mul(&a.y, &hhh)
+	y3.sub(&y3, &t)
+	z3.mul(&a.z, &h)
+
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// batchToAffine converts points to affine with a single inversion
+// (Montgomery's trick). None of the points may be infinity
+func batchToAffine(points []jacobianPoint) []affinePoint {
+	if len(points) == 0 {
+		return nil
+	}
+	// prefix[i] = z0 * z1 * ... * zi
+	prefix := make([]fieldVal, len(points))
+	prefix[0] = points[0].z
+	for i := 1; i < len(points); i++ {
+		prefix[i].mul(&prefix[i-1], &points[i].z)
+	}
+
+	var inv fieldVal
+	inv.inverse(&prefix[len(points)-1])
+
+	result := make([]affinePoint, len(points))
+	for i := len(points) - 1; i >= 0; i-- {
+		// zinv = 1/zi = inv * prefix[i-1], then inv becomes 1/(z0...zi-1)
+		var zinv, zinv2, zinv3 fieldVal
+		if i > 0 {
+			zinv.mul(&inv, &prefix[i-1])
+			inv.mul(&inv, &points[i].z)
+		} else {
+			zinv = inv
+		}
+		zinv2.sqr(&zinv)
+		zinv3.mul(&zinv2, &zinv)
+		result[i].x.mul(&points[i].x, &zinv2)
+		result[i].y.mul(&points[i].y, &zinv3)
+	}
+	return result
+}
+
+const (
+	// the generator table splits scalars in 64 windows of 4 bits
+	gWindowBits = 4
+	gWindows    = 256 / gWindowBits
+	gWindowSize = 1 << gWindowBits
+
+	// window width of the wNAF representation used for other points
+	wnafWidth = 5
+)
+
+var (
+	// gTable[i][j] = j * 2^(4i) * G. Entry 0 of each window is unused
+	gTable     [gWindows][gWindowSize]affinePoint
+	gTableOnce sync.Once
+)
+
+func precomputeGTable() {
+	var base jacobianPoint
+	base.setAffine(&affinePoint{x: *new(fieldVal).setBig(gx), y: *new(fieldVal).setBig(gy)})
+
+	points := make([]jacobianPoint, 0, gWindows*(gWindowSize-1))
+	for i := 0; i < gWindows; i++ {
+		acc := base
+		for j := 1; j < gWindowSize; j++ {
+			points = append(points, acc)
+			acc.add(&acc, &base)
+		}
+		// acc is now 16 * base, the base of the next window
+		base = acc
+	}
+
+	affine := batchToAffine(points)
+	for i := 0; i < gWindows; i++ {
+		for j := 1; j < gWindowSize; j++ {
+			gTable[i][j] = affine[i*(gWindowSize-1)+j-1]
+		}
+	}
+}
+
+// scalarBytes returns k mod N as 32 big endian bytes
+func scalarBytes(k *big.Int) [32]byte {
+	var s scalar
+	return s.setBig(k).bytes()
+}
+
+// scalarBaseMultVartime returns k*G using the precomputed generator table.
+// It skips zero windows so its running time depends on k
+func scalarBaseMultVartime(k *big.Int) jacobianPoint {
+	gTableOnce.Do(precomputeGTable)
+	kb := scalarBytes(k)
+
+	var result jacobianPoint
+	result.setInfinity()
+	for i := 0; i < gWindows; i++ {
+		// window i covers bits 4i to 4i+3, counting from the least significant
+		b := kb[31-i/2]
+		digit := b & 0x0f
+		if i%2 == 1 {
+			digit = b >> 4
+		}
+		if digit != 0 {
+			result.addAffine(&result, &gTable[i][digit])
+		}
+	}
+	return result
+}
+
+// wnaf returns the width-w non-adjacent form of k, least significant digit
+// first. Every non-zero digit is odd and below 2^(w-1) in absolute value
+func wnaf(k *big.Int, w uint) []int {
+	kc := new(big.Int).Set(k)
+	digits := make([]int, 0, kc.BitLen()+1)
+	modulus := 1 << w
+	d := new(big.Int)
+	for kc.Sign() > 0 {
+		digit := 0
+		if kc.Bit(0) == 1 {
+			digit = int(kc.Bits()[0] & big.Word(modulus-1))
+			if digit >= modulus/2 {
+				digit -= modulus
+			}
+			kc.Sub(kc, d.SetInt64(int64(digit)))
+		}
+		digits = append(digits, digit)
+		kc.Rsh(kc, 1)
+	}
+	return digits
+}
+
+// scalarMultVartime returns k*p using wNAF. Its running time depends on k so
+// it must only be used with public scalars
+func scalarMultVartime(p *affinePoint, k *big.Int) jacobianPoint {
+	kc := new(big.Int).Mod(k, N)
+	table := oddMultiples(p)
+
+	digits := wnaf(kc, wnafWidth)
+	var result jacobianPoint
+	result.setInfinity()
+	for i := len(digits) - 1; i >= 0; i-- {
+		result.double(&result)
+		result.addWnafDigit(&result, table, digits[i])
+	}
+	return result
+}
+
+// oddMultiples returns p, 3p, 5p, ..., (2^(w-1)-1)p for the wNAF digits
+func oddMultiples(p *affinePoint) []affinePoint {
+	var double jacobianPoint
+	odd := make([]jacobianPoint, 1<<(wnafWidth-2))
+	odd[0].setAffine(p)
+	double.double(&odd[0])
+	for i := 1; i < len(odd); i++ {
+		odd[i].add(&odd[i-1], &double)
+	}
+	return batchToAffine(odd)
+}
+
+// addWnafDigit sets p to a + digit*P where table holds the odd multiples of P
+func (p *jacobianPoint) addWnafDigit(a *jacobianPoint, table []affinePoint, digit int) *jacobianPoint {
+	if digit > 0 {
+		return p.addAffine(a, &table[digit/2])
+	} else if digit < 0 {
+		neg := table[-digit/2]
+		neg.y.neg(&neg.y)
+		return p.addAffine(a, &neg)
+	}
+	*p = *a
+	return p
+}
+
+// multiScalarMultVartime returns the sum of scalars[i]*points[i] with
+// Straus' method: the wNAF digits of every scalar are interleaved so all the
+// terms share the same doublings
+func multiScalarMultVartime(points []affinePoint, scalars []*big.Int) jacobianPoint {
+	tables := make([][]affinePoint, len(points))
+	digits := make([][]int, len(points))
+	maxLen := 0
+	for i := range points {
+		tables[i] = oddMultiples(&points[i])
+		digits[i] = wnaf(new(big.Int).Mod(scalars[i], N), wnafWidth)
+		if len(digits[i]) > maxLen {
+			maxLen = len(digits[i])
+		}
+	}
+
+	var result jacobianPoint
+	result.setInfinity()
+	for bit := maxLen - 1; bit >= 0; bit-- {
+		result.double(&result)
+		for i := range points {
+			if bit < len(digits[i]) {
+				result.addWnafDigit(&result, tables[i], digits[i][bit])
+			}
+		}
+	}
+	return result
+}
+
+// isS256 reports whether p is a point of the secp256k1 curve
+func (p Point) isS256() bool {
+	return p.a.prime != nil && p.a.prime.Cmp(P) == 0 && p.a.num.Sign() == 0 && p.b.num.Cmp(big.NewInt(7)) == 0
+}
+
+// isG reports whether p is the generator point
+func (p Point) isG() bool {
+	return !IsInf(p.x) && p.x.num.Cmp(gx) == 0 && p.y.num.Cmp(gy) == 0
+}
+
+func (p Point) toAffine() affinePoint {
+	var a affinePoint
+	a.x.setBig(p.x.num)
+	a.y.setBig(p.y.num)
+	return a
+}
+
+// s256PointFromJacobian converts back to a Point without re-checking that it
+// is on the curve
+func s256PointFromJacobian(j *jacobianPoint) *Point {
+	a := &FieldElement{num: big.NewInt(0), prime: P}
+	b := &FieldElement{num: big.NewInt(7), prime: P}
+	if j.isInfinity() {
+		var infelement FieldElement
+		return &Point{x: infelement, y: infelement, a: *a, b: *b}
+	}
+	affine := j.toAffine()
+	x := FieldElement{num: affine.x.big(), prime: P}
+	y := FieldElement{num: affine.y.big(), prime: P}
+	return &Point{x: x, y: y, a: *a, b: *b}
+}
+
+// verifyS256 checks an ECDSA signature with the Jacobian backend
+func verifyS256(pub *Point, sig Signature, z *big.Int) bool {
+	if sig.r.Sign() <= 0 || sig.r.Cmp(N) >= 0 || sig.s.Sign() <= 0 || sig.s.Cmp(N) >= 0 {
+		return false
+	}
+	if IsInf(pub.x) {
+		return false
+	}
+	sInv := new(big.Int).ModInverse(sig.s, N)
+
+	// u = z/s, v = r/s
+	u := new(big.Int).Mul(z, sInv)
+	u.Mod(u, N)
+	v := new(big.Int).Mul(sig.r, sInv)
+	v.Mod(v, N)
+
+	uG := scalarBaseMultVartime(u)
+	pubAffine := pub.toAffine()
+	vP := scalarMultVartime(&pubAffine, v)
+
+	var sum jacobianPoint
+	sum.add(&uG, &vP)
+	if sum.isInfinity() {
+		return false
+	}
+	affine := sum.toAffine()
+	x := affine.x.big()
+	return x.Mod(x, N).Cmp(sig.r) == 0
+}
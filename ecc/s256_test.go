@@ -0,0 +1,166 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// verifyAffine is the original affine verification, kept to check the
+// Jacobian backend against it
+func verifyAffine(p Point, s Signature, z *big.Int) bool {
+	sInv := new(big.Int).ModInverse(s.s, N)
+	u := new(big.Int).Mul(z, sInv)
+	u.Mod(u, N)
+	v := new(big.Int).Mul(s.r, sInv)
+	v.Mod(v, N)
+
+	sum := G.Rmul(u).Add(*p.Rmul(v))
+	return s.r.Cmp(sum.x.num) == 0
+}
+
+func testScalars() []*big.Int {
+	scalars := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(2),
+		big.NewInt(15),
+		big.NewInt(16),
+		new(big.Int).Sub(N, big.NewInt(1)),
+		new(big.Int).Exp(big.NewInt(2), big.NewInt(255), nil),
+	}
+	for i := 0; i < 5; i++ {
+		k, _ := rand.Int(rand.Reader, N)
+		scalars = append(scalars, k)
+	}
+	return scalars
+}
+
+func TestScalarBaseMult(t *testing.T) {
+	for _, k := range testScalars() {
+		want := G.Rmul(k)
+		got := G.RmulS256(k)
+		if got.Ne(*want) {
+			t.Errorf("%x * G: expected '%v' but got '%v' instead", k, want, got)
+		}
+	}
+
+	if !G.RmulS256(big.NewInt(0)).IsInfinity() {
+		t.Error("0 * G should be the point at infinity")
+	}
+	if !G.RmulS256(N).IsInfinity() {
+		t.Error("N * G should be the point at infinity")
+	}
+}
+
+func TestScalarMult(t *testing.T) {
+	p := G.RmulS256(big.NewInt(1485))
+	for _, k := range testScalars() {
+		want := p.Rmul(k)
+		got := p.RmulS256(k)
+		if got.Ne(*want) {
+			t.Errorf("%x * P: expected '%v' but got '%v' instead", k, want, got)
+		}
+	}
+}
+
+func TestWnaf(t *testing.T) {
+	for _, k := range testScalars() {
+		digits := wnaf(k, wnafWidth)
+		sum := new(big.Int)
+		for i := len(digits) - 1; i >= 0; i-- {
+			sum.Lsh(sum, 1)
+			sum.Add(sum, big.NewInt(int64(digits[i])))
+			if digits[i]%2 == 0 && digits[i] != 0 {
+				t.Errorf("digit %d of %x is even", digits[i], k)
+			}
+		}
+		if sum.Cmp(k) != 0 {
+			t.Errorf("expected '%x' but got '%x' instead", k, sum)
+		}
+	}
+}
+
+func TestVerifyMatchesAffine(t *testing.T) {
+	for _, secret := range testScalars()[:4] {
+		privKey := NewPrivateKey(secret)
+		z, _ := rand.Int(rand.Reader, N)
+		sig := privKey.Sign(z)
+
+		badZ := new(big.Int).Add(z, big.NewInt(1))
+		for _, msg := range []*big.Int{z, badZ} {
+			want := verifyAffine(privKey.point, *sig, msg)
+			got := privKey.point.VerifySignature(*sig, msg)
+			if got != want {
+				t.Errorf("expected '%v' but got '%v' instead", want, got)
+			}
+		}
+	}
+}
+
+func benchmarkScalar() *big.Int {
+	k, _ := new(big.Int).SetString("c2c1e2f6b3a4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e", 16)
+	return k
+}
+
+func BenchmarkRmulAffine(b *testing.B) {
+	k := benchmarkScalar()
+	for i := 0; i < b.N; i++ {
+		G.Rmul(k)
+	}
+}
+
+func BenchmarkRmulS256Generator(b *testing.B) {
+	k := benchmarkScalar()
+	G.RmulS256(k)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		G.RmulS256(k)
+	}
+}
+
+func BenchmarkRmulS256Point(b *testing.B) {
+	k := benchmarkScalar()
+	p := G.RmulS256(big.NewInt(1485))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.RmulS256(k)
+	}
+}
+
+func BenchmarkVerifyAffine(b *testing.B) {
+	privKey := NewPrivateKey(big.NewInt(8675309))
+	z := benchmarkScalar()
+	sig := privKey.Sign(z)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		verifyAffine(privKey.point, *sig, z)
+	}
+}
+
+func BenchmarkVerifySignature(b *testing.B) {
+	privKey := NewPrivateKey(big.NewInt(8675309))
+	z := benchmarkScalar()
+	sig := privKey.Sign(z)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		privKey.point.VerifySignature(*sig, z)
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	privKey := NewPrivateKey(big.NewInt(8675309))
+	z := benchmarkScalar()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		privKey.Sign(z)
+	}
+}
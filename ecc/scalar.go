@@ -0,0 +1,212 @@
+package ecc
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// scalar is a number mod N stored as four 64 bit limbs, least significant
+// limb first. Like fieldVal, every operation leaves the value fully reduced
+// and none of them branch on the value, so they can be used with secret
+// keys and nonces
+type scalar [4]uint64
+
+var (
+	scalarN = scalar{0xbfd25e8cd0364141, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+
+	// N - 2, the exponent used to invert
+	scalarNMinus2 = scalar{0xbfd25e8cd036413f, 0xbaaedce6af48a03b, 0xfffffffffffffffe, 0xffffffffffffffff}
+
+	// N / 2, the largest low-S value
+	scalarHalfN = scalar{0xdfe92f46681b20a0, 0x5d576e7357a4501d, 0xffffffffffffffff, 0x7fffffffffffffff}
+
+	// 2^512 mod N, which takes a number into Montgomery form
+	scalarR2 = scalar{0x896cf21467d7d140, 0x741496c20e7cf878, 0xe697f5e45bcd07c6, 0x9d671cd581c69bc5}
+)
+
+// scalarNInv is -N^-1 mod 2^64, used by the Montgomery reduction
+const scalarNInv = 0x4b0dff665588b13f
+
+// setBig sets s to n mod N
+func (s *scalar) setBig(n *big.Int) *scalar {
+	// anything that isn't already 256 bits is public, like an oversized z
+	if n.Sign() < 0 || n.BitLen() > 256 {
+		n = new(big.Int).Mod(n, N)
+	}
+	var b [32]byte
+	n.FillBytes(b[:])
+	return s.setBytes(&b)
+}
+
+// setBytes sets s to the big endian number in b mod N
+func (s *scalar) setBytes(b *[32]byte) *scalar {
+	var r scalar
+	for i := 0; i < 4; i++ {
+		r[3-i] = uint64(b[i*8])<<56 | uint64(b[i*8+1])<<48 | uint64(b[i*8+2])<<40 | uint64(b[i*8+3])<<32 |
+			uint64(b[i*8+4])<<24 | uint64(b[i*8+5])<<16 | uint64(b[i*8+6])<<8 | uint64(b[i*8+7])
+	}
+	// 2^256 - 1 is below 2N, so one subtraction is enough
+	return s.reduce(&r, 0)
+}
+
+// bytes returns s as 32 big endian bytes
+func (s *scalar) bytes() [32]byte {
+	var b [32]byte
+	for i := 0; i < 4; i++ {
+		limb := s[3-i]
+		for j := 0; j < 8; j++ {
+			b[i*8+j] = byte(limb >> (56 - 8*j))
+		}
+	}
+	return b
+}
+
+func (s *scalar) big() *big.Int {
+	b := s.bytes()
+	return new(big.Int).SetBytes(b[:])
+}
+
+func (s *scalar) setInt(n uint64) *scalar {
+	*s = scalar{n, 0, 0, 0}
+	return s
+}
+
+func (s *scalar) isZero() bool {
+	return s[0]|s[1]|s[2]|s[3] == 0
+}
+
+// isHigh returns all ones if s is above N / 2 and 0 otherwise
+func (s *scalar) isHigh() uint64 {
+	var b uint64
+	_, b = bits.Sub64(scalarHalfN[0], s[0], 0)
+	_, b = bits.Sub64(scalarHalfN[1], s[1], b)
+	_, b = bits.Sub64(scalarHalfN[2], s[2], b)
+	_, b = bits.Sub64(scalarHalfN[3], s[3], b)
+	return -b
+}
+
+// cmov sets s to a if mask is all ones and leaves it unchanged if mask is 0
+func (s *scalar) cmov(a *scalar, mask uint64) {
+	for i := range s {
+		s[i] = s[i]&^mask | a[i]&mask
+	}
+}
+
+// reduce sets s to r + carry*2^256 mod N. The input must be below 2N
+func (s *scalar) reduce(r *scalar, carry uint64) *scalar {
+	var t scalar
+	var b uint64
+	t[0], b = bits.Sub64(r[0], scalarN[0], 0)
+	t[1], b = bits.Sub64(r[1], scalarN[1], b)
+	t[2], b = bits.Sub64(r[2], scalarN[2], b)
+	t[3], b = bits.Sub64(r[3], scalarN[3], b)
+
+	// keep r only when it was already below N
+	mask := -(b &^ carry)
+	s[0] = r[0]&mask | t[0]&^mask
+	s[1] = r[1]&mask | t[1]&^mask
+	s[2] = r[2]&mask | t[2]&^mask
+	s[3] = r[3]&mask | t[3]&^mask
+	return s
+}
+
+func (s *scalar) add(a, b *scalar) *scalar {
+	var r scalar
+	var c uint64
+	r[0], c = bits.Add64(a[0], b[0], 0)
+	r[1], c = bits.Add64(a[1], b[1], c)
+	r[2], c = bits.Add64(a[2], b[2], c)
+	r[3], c = bits.Add64(a[3], b[3], c)
+	return s.reduce(&r, c)
+}
+
+func (s *scalar) sub(a, b *scalar) *scalar {
+	var r scalar
+	var borrow, c uint64
+	r[0], borrow = bits.Sub64(a[0], b[0], 0)
+	r[1], borrow = bits.Sub64(a[1], b[1], borrow)
+	r[2], borrow = bits.Sub64(a[2], b[2], borrow)
+	r[3], borrow = bits.Sub64(a[3], b[3], borrow)
+
+	// add N back if the subtraction wrapped
+	mask := -borrow
+	s[0], c = bits.Add64(r[0], scalarN[0]&mask, 0)
+	s[1], c = bits.Add64(r[1], scalarN[1]&mask, c)
+	s[2], c = bits.Add64(r[2], scalarN[2]&mask, c)
+	s[3], _ = bits.Add64(r[3], scalarN[3]&mask, c)
+	return s
+}
+
+func (s *scalar) neg(a *scalar) *scalar {
+	var zero scalar
+	return s.sub(&zero, a)
+}
+
+// montMul sets s to a*b/2^256 mod N with Montgomery multiplication. N has
+// no special form like P, so this is simpler than folding the high half
+func (s *scalar) montMul(a, b *scalar) *scalar {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		// t += a[i]*b
+		var carry, c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			lo, c = bits.Add64(lo, t[j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[j] = lo
+			carry = hi
+		}
+		t[4], c = bits.Add64(t[4], carry, 0)
+		t[5] = c
+
+		// t = (t + m*N) / 2^64, where m makes the lowest limb zero
+		m := t[0] * scalarNInv
+		hi, lo := bits.Mul64(m, scalarN[0])
+		_, c = bits.Add64(lo, t[0], 0)
+		carry = hi + c
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, scalarN[j])
+			lo, c = bits.Add64(lo, t[j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[j-1] = lo
+			carry = hi
+		}
+		t[3], c = bits.Add64(t[4], carry, 0)
+		t[4] = t[5] + c
+	}
+	// t is below 2N
+	r := scalar{t[0], t[1], t[2], t[3]}
+	return s.reduce(&r, t[4])
+}
+
+func (s *scalar) mul(a, b *scalar) *scalar {
+	// a*b/2^256 * 2^512/2^256 = a*b
+	var r scalar
+	r.montMul(a, b)
+	return s.montMul(&r, &scalarR2)
+}
+
+// pow sets s to a^exp. exp is public so the fixed sequence of squares and
+// multiplications does not leak a
+func (s *scalar) pow(a *scalar, exp *scalar) *scalar {
+	base := *a
+	var r scalar
+	r.setInt(1)
+	for i := 255; i >= 0; i-- {
+		r.mul(&r, &r)
+		if (exp[i/64]>>(i%64))&1 == 1 {
+			r.mul(&r, &base)
+		}
+	}
+	*s = r
+	return s
+}
+
+// inverse sets s to a^-1 using Fermat's little theorem
+func (s *scalar) inverse(a *scalar) *scalar {
+	return s.pow(a, &scalarNMinus2)
+}
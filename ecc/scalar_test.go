@@ -0,0 +1,74 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestScalar(t *testing.T) {
+	nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+	values := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), nMinus1, halfN, new(big.Int).Add(halfN, big.NewInt(1))}
+	for i := 0; i < 20; i++ {
+		r, _ := rand.Int(rand.Reader, N)
+		values = append(values, r)
+	}
+
+	for _, a := range values {
+		for _, b := range values {
+			var sa, sb, result scalar
+			sa.setBig(a)
+			sb.setBig(b)
+
+			want := new(big.Int).Add(a, b)
+			if result.add(&sa, &sb).big().Cmp(want.Mod(want, N)) != 0 {
+				t.Errorf("%x + %x: expected '%x' but got '%x' instead", a, b, want, result.big())
+			}
+
+			want = new(big.Int).Sub(a, b)
+			if result.sub(&sa, &sb).big().Cmp(want.Mod(want, N)) != 0 {
+				t.Errorf("%x - %x: expected '%x' but got '%x' instead", a, b, want, result.big())
+			}
+
+			want = new(big.Int).Mul(a, b)
+			if result.mul(&sa, &sb).big().Cmp(want.Mod(want, N)) != 0 {
+				t.Errorf("%x * %x: expected '%x' but got '%x' instead", a, b, want, result.big())
+			}
+		}
+
+		var sa, result scalar
+		sa.setBig(a)
+		want := new(big.Int).Neg(a)
+		if result.neg(&sa).big().Cmp(want.Mod(want, N)) != 0 {
+			t.Errorf("-%x: expected '%x' but got '%x' instead", a, want, result.big())
+		}
+		if high := sa.isHigh() != 0; high != (a.Cmp(halfN) > 0) {
+			t.Errorf("%x: expected high '%v' but got '%v' instead", a, a.Cmp(halfN) > 0, high)
+		}
+
+		if a.Sign() == 0 {
+			continue
+		}
+		want = new(big.Int).ModInverse(a, N)
+		if result.inverse(&sa).big().Cmp(want) != 0 {
+			t.Errorf("1 / %x: expected '%x' but got '%x' instead", a, want, result.big())
+		}
+	}
+}
+
+func TestScalarSetBig(t *testing.T) {
+	twoPow256 := new(big.Int).Lsh(big.NewInt(1), 256)
+	values := []*big.Int{
+		N,
+		new(big.Int).Sub(twoPow256, big.NewInt(1)),
+		twoPow256,
+		big.NewInt(-1),
+	}
+	for _, n := range values {
+		var s scalar
+		want := new(big.Int).Mod(n, N)
+		if s.setBig(n).big().Cmp(want) != 0 {
+			t.Errorf("%x mod N: expected '%x' but got '%x' instead", n, want, s.big())
+		}
+	}
+}
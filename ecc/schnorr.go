@@ -0,0 +1,254 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+// SchnorrSignature is a BIP 340 signature. r is the x coordinate of the
+// nonce point R, which always has an even y
+type SchnorrSignature struct {
+	r *big.Int
+	s *big.Int
+}
+
+// NewSchnorrSignature returns a signature from the x coordinate of R and s
+func NewSchnorrSignature(r, s *big.Int) *SchnorrSignature {
+	return &SchnorrSignature{r: r, s: s}
+}
+
+func (sig SchnorrSignature) R() *big.Int {
+	return sig.r
+}
+
+func (sig SchnorrSignature) S() *big.Int {
+	return sig.s
+}
+
+func (sig SchnorrSignature) String() string {
+	return fmt.Sprintf("SchnorrSignature(%x, %x)", sig.r, sig.s)
+}
+
+// Serialize returns the 64 byte encoding r || s
+func (sig SchnorrSignature) Serialize() []byte {
+	result := make([]byte, 64)
+	sig.r.FillBytes(result[:32])
+	sig.s.FillBytes(result[32:])
+	return result
+}
+
+// ParseSchnorrSignature splits a 64 byte signature into r and s. The range
+// of r and s is checked when verifying
+func ParseSchnorrSignature(sig []byte) (*SchnorrSignature, error) {
+	if len(sig) != 64 {
+		return nil, fmt.Errorf("bad schnorr signature length %d", len(sig))
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	return &SchnorrSignature{r: r, s: s}, nil
+}
+
+// XOnly returns the 32 byte x-only serialization of the public key (BIP 340)
+func (p Point) XOnly() []byte {
+	xbuf := make([]byte, 32)
+	p.x.num.FillBytes(xbuf)
+	return xbuf
+}
+
+// ParseXOnlyPubKey returns the point with x coordinate xOnly and even y
+func ParseXOnlyPubKey(xOnly []byte) (*Point, error) {
+	if len(xOnly) != 32 {
+		return nil, fmt.Errorf("bad x-only public key length %d", len(xOnly))
+	}
+	x := new(big.Int).SetBytes(xOnly)
+	point, ok := liftX(x)
+	if !ok {
+		return nil, errors.New("public key is not a valid x coordinate")
+	}
+	return s256PointFromAffine(&point), nil
+}
+
+// liftX returns the point with x coordinate x and even y
+func liftX(x *big.Int) (affinePoint, bool) {
+	var a affinePoint
+	if x.Cmp(P) >= 0 {
+		return a, false
+	}
+	a.x.setBig(x)
+
+	// y^2 = x^3 + 7
+	var c, seven fieldVal
+	c.sqr(&a.x)
+	c.mul(&c, &a.x)
+	c.add(&c, seven.setInt(7))
+	if !a.y.sqrt(&c) {
+		return a, false
+	}
+	if a.y.isOdd() {
+		a.y.neg(&a.y)
+	}
+	return a, true
+}
+
+func s256PointFromAffine(a *affinePoint) *Point {
+	var j jacobianPoint
+	j.setAffine(a)
+	return s256PointFromJacobian(&j)
+}
+
+// schnorrChallenge is e = tagged_hash("BIP0340/challenge", r || P || msg) mod N
+func schnorrChallenge(r, pubX []byte, msg []byte) *big.Int {
+	hash := encoding.TaggedHash("BIP0340/challenge", r, pubX, msg)
+	e := new(big.Int).SetBytes(hash[:])
+	return e.Mod(e, N)
+}
+
+// SignSchnorr signs msg with BIP 340 using fresh auxiliary randomness
+func (pp PrivateKey) SignSchnorr(msg []byte) (*SchnorrSignature, error) {
+	var auxRand [32]byte
+	if _, err := rand.Read(auxRand[:]); err != nil {
+		return nil, fmt.Errorf("error reading auxiliary randomness: %v", err)
+	}
+	return pp.SignSchnorrWithAux(msg, auxRand)
+}
+
+// SignSchnorrWithAux signs msg with BIP 340 using auxRand as the auxiliary
+// randomness mixed into the nonce. The same auxRand gives the same signature
+func (pp PrivateKey) SignSchnorrWithAux(msg []byte, auxRand [32]byte) (*SchnorrSignature, error) {
+	if pp.secret.Sign() <= 0 || pp.secret.Cmp(N) >= 0 {
+		return nil, errors.New("secret is out of range")
+	}
+
+	// the public key commits to an even y, so negate d if P has an odd one
+	var d, negD scalar
+	d.setBig(pp.secret)
+	pub := scalarBaseMult(pp.secret)
+	pubAffine, _ := pub.toAffine()
+	negD.neg(&d)
+	d.cmov(&negD, -(pubAffine.y[0] & 1))
+	pubX := pubAffine.x.bytes()
+
+	dBytes := d.bytes()
+	auxHash := encoding.TaggedHash("BIP0340/aux", auxRand[:])
+	var t [32]byte
+	for i := range t {
+		t[i] = dBytes[i] ^ auxHash[i]
+	}
+
+	nonce := encoding.TaggedHash("BIP0340/nonce", t[:], pubX[:], msg)
+	var k, negK scalar
+	k.setBytes(&nonce)
+	if k.isZero() {
+		return nil, errors.New("nonce is zero")
+	}
+	nonceP := scalarBaseMult(k.big())
+	rAffine, _ := nonceP.toAffine()
+	negK.neg(&k)
+	k.cmov(&negK, -(rAffine.y[0] & 1))
+	rBytes := rAffine.x.bytes()
+
+	var e, s scalar
+	e.setBig(schnorrChallenge(rBytes[:], pubX[:], msg))
+
+	// s = k + e*d
+	s.mul(&e, &d)
+	s.add(&s, &k)
+
+	sig := &SchnorrSignature{r: new(big.Int).SetBytes(rBytes[:]), s: s.big()}
+	if !verifySchnorr(pubX[:], *sig, msg) {
+		return nil, errors.New("created signature does not verify")
+	}
+	return sig, nil
+}
+
+// VerifySchnorr checks a BIP 340 signature of msg. Only the x coordinate of
+// p is used, as if it was parsed from its x-only serialization
+func (p Point) VerifySchnorr(sig SchnorrSignature, msg []byte) bool {
+	if IsInf(p.x) {
+		return false
+	}
+	return verifySchnorr(p.XOnly(), sig, msg)
+}
+
+func verifySchnorr(pubX []byte, sig SchnorrSignature, msg []byte) bool {
+	pub, ok := liftX(new(big.Int).SetBytes(pubX))
+	if !ok {
+		return false
+	}
+	if sig.r.Cmp(P) >= 0 || sig.s.Cmp(N) >= 0 {
+		return false
+	}
+	rBytes := make([]byte, 32)
+	sig.r.FillBytes(rBytes)
+	e := schnorrChallenge(rBytes, pubX, msg)
+
+	// R = s*G - e*P
+	sG := scalarBaseMultVartime(sig.s)
+	eP := scalarMultVartime(&pub, new(big.Int).Sub(N, e))
+	var r jacobianPoint
+	r.add(&sG, &eP)
+	if r.isInfinity() {
+		return false
+	}
+	rAffine := r.toAffine()
+	return !rAffine.y.isOdd() && rAffine.x.big().Cmp(sig.r) == 0
+}
+
+// verifySchnorrBatch checks all the signatures at once with a random linear
+// combination (see "Batch Verification" in BIP 340):
+//
+//	(a_1*s_1 + ... + a_u*s_u)*G = a_1*R_1 + ... + a_u*R_u + a_1*e_1*P_1 + ... + a_u*e_u*P_u
+//
+// a_1 is 1 and the others are random. It fails if any signature is invalid
+// but does not say which one
+func verifySchnorrBatch(entries []schnorrEntry) bool {
+	points := make([]affinePoint, 0, 2*len(entries))
+	scalars := make([]*big.Int, 0, 2*len(entries))
+	sSum := new(big.Int)
+	nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+
+	for i, entry := range entries {
+		sig := entry.sig
+		if entry.pubKey == nil || IsInf(entry.pubKey.x) || sig.r.Cmp(P) >= 0 || sig.s.Cmp(N) >= 0 {
+			return false
+		}
+		pubX := entry.pubKey.XOnly()
+		pub, ok := liftX(new(big.Int).SetBytes(pubX))
+		if !ok {
+			return false
+		}
+		r, ok := liftX(sig.r)
+		if !ok {
+			return false
+		}
+		rBytes := make([]byte, 32)
+		sig.r.FillBytes(rBytes)
+		e := schnorrChallenge(rBytes, pubX, entry.msg)
+
+		a := big.NewInt(1)
+		if i > 0 {
+			var err error
+			a, err = rand.Int(rand.Reader, nMinus1)
+			if err != nil {
+				return false
+			}
+			a.Add(a, big.NewInt(1))
+		}
+
+		sSum.Add(sSum, new(big.Int).Mul(a, sig.s))
+
+		// move the right hand side over: -a*R and -a*e*P
+		negA := new(big.Int).Sub(N, a)
+		points = append(points, r, pub)
+		scalars = append(scalars, negA, new(big.Int).Mul(negA, e))
+	}
+
+	sum := multiScalarMultVartime(points, scalars)
+	sG := scalarBaseMultVartime(sSum)
+	sum.add(&sum, &sG)
+	return sum.isInfinity()
+}
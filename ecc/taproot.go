@@ -0,0 +1,70 @@
+package ecc
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+// taprootTweak is t = tagged_hash("TapTweak", P || merkleRoot). merkleRoot is
+// empty for outputs without a script path
+func taprootTweak(pubX []byte, merkleRoot []byte) (*big.Int, error) {
+	hash := encoding.TaggedHash("TapTweak", pubX, merkleRoot)
+	t := new(big.Int).SetBytes(hash[:])
+	if t.Cmp(N) >= 0 {
+		return nil, errors.New("taproot tweak is out of range")
+	}
+	return t, nil
+}
+
+// TaprootOutputKey returns the BIP 341 output key Q = P + t*G, where P is
+// internalKey with an even y. Its x coordinate goes in the P2TR scriptPubKey
+// and the parity of its y in the control block of script path spends
+func TaprootOutputKey(internalKey *Point, merkleRoot []byte) (*Point, error) {
+	if internalKey == nil || IsInf(internalKey.x) {
+		return nil, errors.New("internal key is infinity")
+	}
+	pubX := internalKey.XOnly()
+	p, ok := liftX(new(big.Int).SetBytes(pubX))
+	if !ok {
+		return nil, errors.New("internal key is not a valid x coordinate")
+	}
+	t, err := taprootTweak(pubX, merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var q, pj jacobianPoint
+	tG := scalarBaseMultVartime(t)
+	pj.setAffine(&p)
+	q.add(&pj, &tG)
+	if q.isInfinity() {
+		return nil, errors.New("taproot output key is infinity")
+	}
+	return s256PointFromJacobian(&q), nil
+}
+
+// TaprootTweak returns the private key of the output key TaprootOutputKey
+// gives for this key, which signs key path spends
+func (pp PrivateKey) TaprootTweak(merkleRoot []byte) (*PrivateKey, error) {
+	if pp.secret.Sign() <= 0 || pp.secret.Cmp(N) >= 0 {
+		return nil, errors.New("secret is out of range")
+	}
+	tweak, err := taprootTweak(pp.point.XOnly(), merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// negate d for an odd y, then d + t
+	var d, negD, t scalar
+	d.setBig(pp.secret)
+	negD.neg(&d)
+	d.cmov(&negD, -uint64(pp.point.y.num.Bit(0)))
+	t.setBig(tweak)
+	d.add(&d, &t)
+	if d.isZero() {
+		return nil, errors.New("tweaked secret is zero")
+	}
+	return NewPrivateKey(d.big()), nil
+}
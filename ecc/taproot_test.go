@@ -0,0 +1,50 @@
+package ecc
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// key path only example from the BIP 341 wallet test vectors
+func TestTaprootOutputKey(t *testing.T) {
+	internalKey, _ := hex.DecodeString("d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d")
+	expected := "53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343"
+
+	pubKey, err := ParseXOnlyPubKey(internalKey)
+	if err != nil {
+		t.Fatalf("error parsing internal key: %v", err)
+	}
+	outputKey, err := TaprootOutputKey(pubKey, nil)
+	if err != nil {
+		t.Fatalf("error tweaking key: %v", err)
+	}
+	if got := hex.EncodeToString(outputKey.XOnly()); got != expected {
+		t.Errorf("expected '%v' but got '%v' instead", expected, got)
+	}
+}
+
+func TestTaprootTweak(t *testing.T) {
+	merkleRoot := make([]byte, 32)
+	merkleRoot[0] = 0x42
+	for _, secret := range testScalars() {
+		privKey := NewPrivateKey(secret)
+		for _, root := range [][]byte{nil, merkleRoot} {
+			tweaked, err := privKey.TaprootTweak(root)
+			if err != nil {
+				t.Fatalf("error tweaking secret: %v", err)
+			}
+			outputKey, err := TaprootOutputKey(privKey.PublicKey(), root)
+			if err != nil {
+				t.Fatalf("error tweaking key: %v", err)
+			}
+			if !tweaked.PublicKey().Eq(*outputKey) {
+				t.Errorf("expected '%v' but got '%v' instead", outputKey, tweaked.PublicKey())
+			}
+		}
+	}
+
+	if _, err := NewPrivateKey(big.NewInt(0)).TaprootTweak(nil); err == nil {
+		t.Errorf("expected error tweaking zero secret")
+	}
+}
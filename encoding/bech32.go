@@ -0,0 +1,225 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const Bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// human readable parts of segwit addresses
+const (
+	MainnetHRP = "bc"
+	TestnetHRP = "tb"
+	RegtestHRP = "bcrt"
+)
+
+// Bech32Variant picks the checksum constant: bech32 (BIP 173) for witness
+// version 0 and bech32m (BIP 350) for the rest
+type Bech32Variant int
+
+const (
+	BECH32 Bech32Variant = iota + 1
+	BECH32M
+)
+
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+
+	bech32MaxLength    = 90
+	bech32ChecksumSize = 6
+)
+
+var ErrBech32Checksum = errors.New("bad bech32 checksum")
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand returns the high bits of every character, a zero and the
+// low bits of every character
+func bech32HRPExpand(hrp string) []byte {
+	result := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		result = append(result, hrp[i]>>5)
+	}
+	result = append(result, 0)
+	for i := 0; i < len(hrp); i++ {
+		result = append(result, hrp[i]&31)
+	}
+	return result
+}
+
+func bech32Checksum(hrp string, data []byte, variant Bech32Variant) []byte {
+	values := append(bech32HRPExpand(hrp), data...)
+	values = append(values, make([]byte, bech32ChecksumSize)...)
+	constant := uint32(bech32Const)
+	if variant == BECH32M {
+		constant = bech32mConst
+	}
+	polymod := bech32Polymod(values) ^ constant
+	checksum := make([]byte, bech32ChecksumSize)
+	for i := range checksum {
+		checksum[i] = byte(polymod>>(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// Bech32Encode encodes 5 bit values in data with hrp and a checksum of variant
+func Bech32Encode(hrp string, data []byte, variant Bech32Variant) (string, error) {
+	if len(hrp) == 0 || len(hrp)+1+len(data)+bech32ChecksumSize > bech32MaxLength {
+		return "", fmt.Errorf("bech32: bad length")
+	}
+	if strings.ToLower(hrp) != hrp {
+		return "", fmt.Errorf("bech32: hrp %q is not lowercase", hrp)
+	}
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	checksum := bech32Checksum(hrp, data, variant)
+	for _, v := range append(append([]byte{}, data...), checksum...) {
+		if v > 31 {
+			return "", fmt.Errorf("bech32: value %d does not fit in 5 bits", v)
+		}
+		sb.WriteByte(Bech32Charset[v])
+	}
+	return sb.String(), nil
+}
+
+// Bech32Decode splits s into its lowercase hrp and 5 bit values and tells
+// which checksum it has
+func Bech32Decode(s string) (string, []byte, Bech32Variant, error) {
+	if len(s) > bech32MaxLength {
+		return "", nil, 0, fmt.Errorf("bech32: %d characters, more than %d", len(s), bech32MaxLength)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 33 || s[i] > 126 {
+			return "", nil, 0, fmt.Errorf("bech32: bad character at %d", i)
+		}
+	}
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, 0, errors.New("bech32: mixed case")
+	}
+	sep := strings.LastIndexByte(lower, '1')
+	if sep < 1 || sep+1+bech32ChecksumSize > len(lower) {
+		return "", nil, 0, errors.New("bech32: bad separator position")
+	}
+	hrp := lower[:sep]
+	data := make([]byte, 0, len(lower)-sep-1)
+	for i := sep + 1; i < len(lower); i++ {
+		v := strings.IndexByte(Bech32Charset, lower[i])
+		if v == -1 {
+			return "", nil, 0, fmt.Errorf("bech32: bad character %q at %d", lower[i], i)
+		}
+		data = append(data, byte(v))
+	}
+
+	var variant Bech32Variant
+	switch bech32Polymod(append(bech32HRPExpand(hrp), data...)) {
+	case bech32Const:
+		variant = BECH32
+	case bech32mConst:
+		variant = BECH32M
+	default:
+		return "", nil, 0, ErrBech32Checksum
+	}
+	return hrp, data[:len(data)-bech32ChecksumSize], variant, nil
+}
+
+// ConvertBits regroups data from fromBits to toBits values. With pad the
+// last group is filled with zeros, without it the leftover bits must be
+// fewer than fromBits and all zero
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	acc, bits := uint32(0), uint(0)
+	maxv := uint32(1)<<toBits - 1
+	var result []byte
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, fmt.Errorf("value %d does not fit in %d bits", v, fromBits)
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			result = append(result, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			result = append(result, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, errors.New("bad padding")
+	}
+	return result, nil
+}
+
+// EncodeSegwitAddress returns the bech32 address of a witness program, or
+// bech32m from version 1 on
+func EncodeSegwitAddress(hrp string, version int, program []byte) (string, error) {
+	if err := checkWitnessProgram(version, program); err != nil {
+		return "", err
+	}
+	variant := BECH32
+	if version > 0 {
+		variant = BECH32M
+	}
+	data, _ := ConvertBits(program, 8, 5, true)
+	return Bech32Encode(hrp, append([]byte{byte(version)}, data...), variant)
+}
+
+// DecodeSegwitAddress returns the witness version and program of an address
+// for hrp
+func DecodeSegwitAddress(hrp, address string) (int, []byte, error) {
+	gotHRP, data, variant, err := Bech32Decode(address)
+	if err != nil {
+		return 0, nil, err
+	}
+	if gotHRP != hrp {
+		return 0, nil, fmt.Errorf("bech32: expected hrp %q but got %q", hrp, gotHRP)
+	}
+	if len(data) == 0 {
+		return 0, nil, errors.New("bech32: no witness version")
+	}
+	version := int(data[0])
+	program, err := ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return 0, nil, fmt.Errorf("bech32: %v", err)
+	}
+	if err := checkWitnessProgram(version, program); err != nil {
+		return 0, nil, err
+	}
+	if (version == 0) != (variant == BECH32) {
+		return 0, nil, fmt.Errorf("bech32: wrong checksum variant for witness version %d", version)
+	}
+	return version, program, nil
+}
+
+// checkWitnessProgram checks the rules of BIP 141 on versions and program
+// sizes
+func checkWitnessProgram(version int, program []byte) error {
+	if version < 0 || version > 16 {
+		return fmt.Errorf("bad witness version %d", version)
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return fmt.Errorf("bad witness program size %d", len(program))
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return fmt.Errorf("bad witness v0 program size %d", len(program))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package encoding
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBech32Decode(t *testing.T) {
+	testCases := []struct {
+		s       string
+		variant Bech32Variant
+	}{
+		// BIP 173 and BIP 350 test vectors
+		{"A12UEL5L", BECH32},
+		{"a12uel5l", BECH32},
+		{"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs", BECH32},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", BECH32},
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", BECH32},
+		{"?1ezyfcl", BECH32},
+		{"A1LQFN3A", BECH32M},
+		{"a1lqfn3a", BECH32M},
+		{"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6", BECH32M},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", BECH32M},
+		{"split1checkupstagehandshakeupstreamerranterredcaperredlc445v", BECH32M},
+		{"?1v759aa", BECH32M},
+	}
+
+	for _, test := range testCases {
+		hrp, data, variant, err := Bech32Decode(test.s)
+		if err != nil || variant != test.variant {
+			t.Errorf("%s: expected '%v' but got '%v' (%v) instead", test.s, test.variant, variant, err)
+			continue
+		}
+		encoded, err := Bech32Encode(hrp, data, variant)
+		if err != nil || encoded != strings.ToLower(test.s) {
+			t.Errorf("expected '%v' but got '%v' (%v) instead", strings.ToLower(test.s), encoded, err)
+		}
+	}
+
+	invalid := []string{
+		"\x201nwldj5",
+		"\x7f1axkwrx",
+		"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx",
+		"pzry9x0s0muk",
+		"1pzry9x0s0muk",
+		"x1b4n0q5v",
+		"li1dgmt3",
+		"A1G7SGD8",
+		"10a06t8",
+		"1qzzfhee",
+		"A12uEL5L",
+	}
+	for _, s := range invalid {
+		if _, _, _, err := Bech32Decode(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestConvertBits(t *testing.T) {
+	program := []byte{0x75, 0x1e, 0x76, 0xe8, 0x19}
+	data, err := ConvertBits(program, 8, 5, true)
+	if err != nil || len(data) != 8 {
+		t.Fatalf("expected '8' values but got '%v' (%v) instead", data, err)
+	}
+	back, err := ConvertBits(data, 5, 8, false)
+	if err != nil || string(back) != string(program) {
+		t.Errorf("expected '%x' but got '%x' (%v) instead", program, back, err)
+	}
+	if _, err := ConvertBits([]byte{0x20}, 5, 8, false); err == nil {
+		t.Errorf("expected an error for a value over 5 bits")
+	}
+}
@@ -0,0 +1,281 @@
+// Package encoding implements the hashing and serialization helpers shared by
+// the rest of the library: hash256/hash160, base58, bech32, varints and byte order
+// utilities.
+package encoding
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ripemd160"
+)
+
+const Base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// MAX_SIZE is the largest length Bitcoin Core reads from a varint, 32 MiB
+const MAX_SIZE = 0x02000000
+
+// readChunkSize is how much ReadBytes allocates ahead of the bytes it has
+// read
+const readChunkSize = 1 << 16
+
+// Hash256 does two rounds of sha256
+func Hash256(input []byte) [32]byte {
+	sum := sha256.Sum256(input)
+	return sha256.Sum256([]byte(sum[:]))
+}
+
+// TaggedHash is sha256(sha256(tag) || sha256(tag) || msgs...) as defined in
+// BIP 340
+func TaggedHash(tag string, msgs ...[]byte) [32]byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	var sum [32]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// Hash160 is sha256 + ripemd160
+func Hash160(input []byte) []byte {
+	h256 := sha256.Sum256(input)
+	h := ripemd160.New()
+	h.Write(h256[:])
+	return h.Sum(nil)
+}
+
+// Base58Encode encodes input in base58, keeping leading zero bytes as '1'
+func Base58Encode(input []byte) string {
+	prefix := ""
+	for _, inbyte := range input {
+		if inbyte == 0 {
+			prefix += "1"
+		} else {
+			break
+		}
+	}
+
+	num := big.NewInt(0).SetBytes(input)
+	result := ""
+	for num.Sign() > 0 {
+		mod := new(big.Int)
+		num, mod = num.DivMod(num, big.NewInt(58), mod)
+		result = string(Base58Alphabet[mod.Int64()]) + result
+	}
+	return prefix + result
+}
+
+// Base58EncodeChecksum appends the first 4 bytes of hash256(input) and
+// encodes the result in base58
+func Base58EncodeChecksum(input []byte) string {
+	sha := Hash256(input)
+	checksum := sha[:4]
+	inp := bytes.Join([][]byte{input, checksum}, []byte{})
+	return Base58Encode(inp)
+}
+
+// ErrBase58Checksum is returned by Base58DecodeChecksum when the last 4
+// bytes don't match the payload
+var ErrBase58Checksum = errors.New("bad base58 checksum")
+
+// Base58Decode decodes a base58 string, the inverse of Base58Encode. Every
+// leading '1' is a zero byte
+func Base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == Base58Alphabet[0] {
+		zeros++
+	}
+
+	num := big.NewInt(0)
+	radix := big.NewInt(58)
+	for i := zeros; i < len(s); i++ {
+		charIdx := strings.IndexByte(Base58Alphabet, s[i])
+		if charIdx == -1 {
+			return nil, fmt.Errorf("base58: bad character %q at %d", s[i], i)
+		}
+		num.Mul(num, radix)
+		num.Add(num, big.NewInt(int64(charIdx)))
+	}
+	return append(make([]byte, zeros), num.Bytes()...), nil
+}
+
+// Base58DecodeChecksum decodes a base58check string, like an address or a
+// wif, verifies the checksum and returns the version byte and the payload
+// that follows it
+func Base58DecodeChecksum(s string) (byte, []byte, error) {
+	combined, err := Base58Decode(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	// a version byte and the checksum
+	if len(combined) < 5 {
+		return 0, nil, fmt.Errorf("base58check: %d bytes is too short", len(combined))
+	}
+	checksum := combined[len(combined)-4:]
+	hash := Hash256(combined[:len(combined)-4])
+	if !bytes.Equal(hash[:4], checksum) {
+		return 0, nil, ErrBase58Checksum
+	}
+	return combined[0], combined[1 : len(combined)-4], nil
+}
+
+// H160ToP2PKH returns the base58 p2pkh address for hash160
+func H160ToP2PKH(hash160 []byte, testnet bool) string {
+	var prefix []byte
+	if testnet {
+		prefix = []byte{0x6f}
+	} else {
+		prefix = []byte{0x00}
+	}
+	pkhash := bytes.Join([][]byte{prefix, hash160}, []byte{})
+	return Base58EncodeChecksum(pkhash)
+}
+
+// H160ToP2SH returns the base58 p2sh address for hash160
+func H160ToP2SH(hash160 []byte, testnet bool) string {
+	var prefix []byte
+	if testnet {
+		prefix = []byte{0xc4}
+	} else {
+		prefix = []byte{0x05}
+	}
+	scriptHash := bytes.Join([][]byte{prefix, hash160}, []byte{})
+	return Base58EncodeChecksum(scriptHash)
+}
+
+// FromHex parses a hex string into a big.Int. It panics on invalid hex and is
+// meant for constants and tests
+func FromHex(s string) *big.Int {
+	if s == "" {
+		return big.NewInt(0)
+	}
+	r, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex: " + s)
+	}
+	return r
+}
+
+// ReadVarint reads a variable length integer from varint
+func ReadVarint(varint io.Reader) (int, error) {
+	var numbuf []byte
+	i := make([]byte, 1)
+	_, err := io.ReadFull(varint, i)
+	if err != nil {
+		return -1, err
+	}
+
+	if i[0] == 0xfd {
+		numbuf = make([]byte, 2)
+		_, err = io.ReadFull(varint, numbuf)
+		if err != nil {
+			return -1, err
+		}
+		return int(binary.LittleEndian.Uint16(numbuf)), nil
+	} else if i[0] == 0xfe {
+		numbuf = make([]byte, 4)
+		_, err = io.ReadFull(varint, numbuf)
+		if err != nil {
+			return -1, err
+		}
+		return int(binary.LittleEndian.Uint32(numbuf)), nil
+	} else if i[0] == 0xff {
+		numbuf = make([]byte, 8)
+		_, err = io.ReadFull(varint, numbuf)
+		if err != nil {
+			return -1, err
+		}
+		return int(binary.LittleEndian.Uint64(numbuf)), nil
+	}
+
+	return int(i[0]), nil
+}
+
+// ReadBytes reads n bytes from r. The buffer grows as the bytes arrive, so a
+// bogus length runs out of input instead of allocating n bytes up front
+func ReadBytes(r io.Reader, n int) ([]byte, error) {
+	if n < 0 || n > MAX_SIZE {
+		return nil, fmt.Errorf("length %d out of range 0 to %d", n, MAX_SIZE)
+	}
+	size := n
+	if size > readChunkSize {
+		size = readChunkSize
+	}
+	result := make([]byte, 0, size)
+	for len(result) < n {
+		size = n - len(result)
+		if size > readChunkSize {
+			size = readChunkSize
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			if err == io.EOF && len(result) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		result = append(result, chunk...)
+	}
+	return result, nil
+}
+
+// EncodeVarint encodes num as a variable length integer
+func EncodeVarint(num int) ([]byte, error) {
+	cmpInt := []byte{0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0}
+	var varintbuf, prefix, encodedRes []byte
+
+	if num < 0xfd {
+		return []byte{byte(num)}, nil
+	} else if num < 0x10000 {
+		varintbuf = make([]byte, 2)
+		prefix = []byte{0xfd}
+		binary.LittleEndian.PutUint16(varintbuf, uint16(num))
+		encodedRes = bytes.Join([][]byte{prefix, varintbuf}, []byte{})
+	} else if num < 0x100000000 {
+		varintbuf = make([]byte, 4)
+		prefix = []byte{0xfe}
+		binary.LittleEndian.PutUint32(varintbuf, uint32(num))
+		encodedRes = bytes.Join([][]byte{prefix, varintbuf}, []byte{})
+	} else if big.NewInt(int64(num)).Cmp(new(big.Int).SetBytes(cmpInt)) == -1 {
+		varintbuf = make([]byte, 8)
+		prefix = []byte{0xff}
+		binary.LittleEndian.PutUint64(varintbuf, uint64(num))
+		encodedRes = bytes.Join([][]byte{prefix, varintbuf}, []byte{})
+	} else {
+		// err value too large
+		return nil, errors.New("integer too large")
+	}
+	return encodedRes, nil
+}
+
+// Reverse returns a reversed copy of element
+func Reverse(element []byte) []byte {
+	reversed := make([]byte, len(element))
+	counter := len(element) - 1
+	for i := 0; i < len(element); i++ {
+		reversed[i] = element[counter]
+		counter--
+	}
+	return reversed
+}
+
+// ReverseByteArr32 returns a reversed copy of byteArr
+func ReverseByteArr32(byteArr [32]byte) [32]byte {
+	var reversed [32]byte
+	counter := 31
+	for i := 0; i < 32; i++ {
+		reversed[i] = byteArr[counter]
+		counter--
+	}
+	return reversed
+}
@@ -0,0 +1,104 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	// Bitcoin Core's base58_encode_decode.json
+	testCases := []struct {
+		hex     string
+		encoded string
+	}{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+		{"572e4794", "3EFU7m"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"10c8511e", "Rt5zm"},
+		{"00000000000000000000", "1111111111"},
+	}
+
+	for _, test := range testCases {
+		b, _ := hex.DecodeString(test.hex)
+		if got := Base58Encode(b); got != test.encoded {
+			t.Errorf("expected '%v' but got '%v' instead", test.encoded, got)
+		}
+		got, err := Base58Decode(test.encoded)
+		if err != nil || !bytes.Equal(got, b) {
+			t.Errorf("expected '%x' but got '%x' (%v) instead", b, got, err)
+		}
+	}
+
+	for _, s := range []string{"0", "1O", "3EFU7mI", "l", "a3g V", "é"} {
+		if _, err := Base58Decode(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestBase58DecodeChecksum(t *testing.T) {
+	testCases := []struct {
+		encoded string
+		version byte
+		payload string
+	}{
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", 0x00, "62e907b15cbf27d5425399ebf6f0fb50ebb88f18"},
+		{"1111111111111111111114oLvT2", 0x00, "0000000000000000000000000000000000000000"},
+		{"3CK4fEwbMP7heJarmU4eqA3sMbVJyEnU3V", 0x05, "748284390f9e263a4b766a75d0633c50426eb875"},
+		{"5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ", 0x80, "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d"},
+	}
+
+	for _, test := range testCases {
+		version, payload, err := Base58DecodeChecksum(test.encoded)
+		if err != nil || version != test.version || hex.EncodeToString(payload) != test.payload {
+			t.Errorf("%s: expected '%x' '%v' but got '%x' '%x' (%v) instead", test.encoded, test.version, test.payload, version, payload, err)
+			continue
+		}
+		if got := Base58EncodeChecksum(append([]byte{version}, payload...)); got != test.encoded {
+			t.Errorf("expected '%v' but got '%v' instead", test.encoded, got)
+		}
+	}
+
+	_, _, err := Base58DecodeChecksum("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb")
+	if !errors.Is(err, ErrBase58Checksum) {
+		t.Errorf("expected '%v' but got '%v' instead", ErrBase58Checksum, err)
+	}
+	for _, s := range []string{"", "1111", "2g", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfN0"} {
+		if _, _, err := Base58DecodeChecksum(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func FuzzBase58(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x00, 0x00, 0x01})
+	f.Add([]byte("simply a long string"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		encoded := Base58Encode(b)
+		decoded, err := Base58Decode(encoded)
+		if err != nil || !bytes.Equal(decoded, b) {
+			t.Fatalf("expected '%x' but got '%x' (%v) instead", b, decoded, err)
+		}
+
+		// the other way around, b read as base58 text
+		if decoded, err := Base58Decode(string(b)); err == nil && Base58Encode(decoded) != string(b) {
+			t.Fatalf("%q: expected it back but got '%v' instead", b, Base58Encode(decoded))
+		}
+
+		withChecksum := Base58EncodeChecksum(append([]byte{0x6f}, b...))
+		version, payload, err := Base58DecodeChecksum(withChecksum)
+		if err != nil || version != 0x6f || !bytes.Equal(payload, b) {
+			t.Fatalf("expected '%x' but got '%x' (%v) instead", b, payload, err)
+		}
+	})
+}
@@ -0,0 +1,326 @@
+// Package hd implements BIP 32 hierarchical deterministic keys and BIP 39
+// mnemonics to back them up.
+package hd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+const (
+	// HARDENED_KEY_START is the first hardened child index, written i' or
+	// ih in paths
+	HARDENED_KEY_START uint32 = 0x80000000
+
+	MIN_SEED_SIZE = 16
+	MAX_SEED_SIZE = 64
+
+	// version, depth, parent fingerprint, child number, chain code and key
+	serializedKeySize = 4 + 1 + 4 + 4 + 32 + 33
+)
+
+// version bytes of serialized keys
+var (
+	xprvVersion = []byte{0x04, 0x88, 0xad, 0xe4}
+	xpubVersion = []byte{0x04, 0x88, 0xb2, 0x1e}
+	tprvVersion = []byte{0x04, 0x35, 0x83, 0x94}
+	tpubVersion = []byte{0x04, 0x35, 0x87, 0xcf}
+)
+
+var (
+	// ErrInvalidChild is returned for the very unlikely indexes that give an
+	// invalid key. BIP 32 says to move on to the next index
+	ErrInvalidChild = errors.New("child key is invalid, use the next index")
+
+	// ErrHardenedFromPublic is returned when deriving a hardened child of a
+	// public key, which needs the private key
+	ErrHardenedFromPublic = errors.New("cannot derive a hardened child from a public key")
+)
+
+// ExtendedKey is a private or public key with the chain code to derive its
+// children and where it sits in the tree
+type ExtendedKey struct {
+	privKey           *ecc.PrivateKey // nil for public keys
+	pubKey            *ecc.Point
+	chainCode         []byte
+	depth             byte
+	parentFingerprint []byte
+	childNumber       uint32
+	testnet           bool
+}
+
+// NewMaster returns the master private key for a 16 to 64 byte seed
+func NewMaster(seed []byte, testnet bool) (*ExtendedKey, error) {
+	if len(seed) < MIN_SEED_SIZE || len(seed) > MAX_SEED_SIZE {
+		return nil, fmt.Errorf("seed is %d bytes, it must be %d to %d", len(seed), MIN_SEED_SIZE, MAX_SEED_SIZE)
+	}
+	i := hmacSha512([]byte("Bitcoin seed"), seed)
+	secret := new(big.Int).SetBytes(i[:32])
+	if secret.Sign() == 0 || secret.Cmp(ecc.N) >= 0 {
+		return nil, errors.New("seed gives an invalid master key")
+	}
+	privKey := ecc.NewPrivateKey(secret)
+	return &ExtendedKey{
+		privKey:           privKey,
+		pubKey:            privKey.PublicKey(),
+		chainCode:         i[32:],
+		parentFingerprint: make([]byte, 4),
+		testnet:           testnet,
+	}, nil
+}
+
+func hmacSha512(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// IsPrivate reports whether the key can derive hardened children and sign
+func (k ExtendedKey) IsPrivate() bool {
+	return k.privKey != nil
+}
+
+// PrivateKey returns the private key, nil for public keys
+func (k ExtendedKey) PrivateKey() *ecc.PrivateKey {
+	return k.privKey
+}
+
+// PublicKey returns the public key point
+func (k ExtendedKey) PublicKey() *ecc.Point {
+	return k.pubKey
+}
+
+// ChainCode returns the 32 byte chain code
+func (k ExtendedKey) ChainCode() []byte {
+	return k.chainCode
+}
+
+// Depth returns how many derivations away from the master key this key is
+func (k ExtendedKey) Depth() byte {
+	return k.depth
+}
+
+// ChildNumber returns the index this key was derived with, 0 for the master
+// key
+func (k ExtendedKey) ChildNumber() uint32 {
+	return k.childNumber
+}
+
+// ParentFingerprint returns the fingerprint of the parent, zeros for the
+// master key
+func (k ExtendedKey) ParentFingerprint() []byte {
+	return k.parentFingerprint
+}
+
+// Fingerprint is the first 4 bytes of the hash160 of the compressed public
+// key
+func (k ExtendedKey) Fingerprint() []byte {
+	return k.pubKey.Hash160(true)[:4]
+}
+
+// Neuter returns the public key of k, which can only derive normal children
+func (k ExtendedKey) Neuter() *ExtendedKey {
+	k.privKey = nil
+	return &k
+}
+
+// Child derives child i. Indexes from HARDENED_KEY_START on are hardened and
+// need a private key
+func (k ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
+	if k.depth == 0xff {
+		return nil, errors.New("key is at the maximum depth")
+	}
+	index := make([]byte, 4)
+	binary.BigEndian.PutUint32(index, i)
+
+	var data []byte
+	if i >= HARDENED_KEY_START {
+		if !k.IsPrivate() {
+			return nil, ErrHardenedFromPublic
+		}
+		data = append([]byte{0x00}, k.privKey.Secret().FillBytes(make([]byte, 32))...)
+	} else {
+		data = k.pubKey.Sec(true)
+	}
+	hash := hmacSha512(k.chainCode, data, index)
+	tweak := new(big.Int).SetBytes(hash[:32])
+	if tweak.Cmp(ecc.N) >= 0 {
+		return nil, ErrInvalidChild
+	}
+
+	child := &ExtendedKey{
+		chainCode:         hash[32:],
+		depth:             k.depth + 1,
+		parentFingerprint: k.Fingerprint(),
+		childNumber:       i,
+		testnet:           k.testnet,
+	}
+	if k.IsPrivate() {
+		// k_i = IL + k_par mod n
+		secret := tweak.Add(tweak, k.privKey.Secret())
+		secret.Mod(secret, ecc.N)
+		if secret.Sign() == 0 {
+			return nil, ErrInvalidChild
+		}
+		child.privKey = ecc.NewPrivateKey(secret)
+		child.pubKey = child.privKey.PublicKey()
+		return child, nil
+	}
+	// K_i = IL*G + K_par
+	if tweak.Sign() == 0 {
+		child.pubKey = k.pubKey
+		return child, nil
+	}
+	child.pubKey = ecc.NewPrivateKey(tweak).PublicKey().Add(*k.pubKey)
+	if child.pubKey.IsInfinity() {
+		return nil, ErrInvalidChild
+	}
+	return child, nil
+}
+
+// Derive follows path, like "m/84'/0'/0'/0/5", from k. Paths starting with m
+// must start at the master key
+func (k ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(path, "m") && k.depth != 0 {
+		return nil, fmt.Errorf("path %s starts at the master key but the key is at depth %d", path, k.depth)
+	}
+	key := &k
+	for _, i := range indexes {
+		key, err = key.Child(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// ParsePath parses a derivation path like "m/84'/0'/0'/0/5" into child
+// indexes. Hardened indexes are marked with ', h or H. The leading m is
+// optional, without it the path is relative to the key it is used on
+func ParsePath(path string) ([]uint32, error) {
+	elements := strings.Split(path, "/")
+	if elements[0] == "m" {
+		elements = elements[1:]
+	}
+	indexes := make([]uint32, 0, len(elements))
+	for _, element := range elements {
+		hardened := false
+		if strings.HasSuffix(element, "'") || strings.HasSuffix(element, "h") || strings.HasSuffix(element, "H") {
+			hardened = true
+			element = element[:len(element)-1]
+		}
+		// no signs or leading zeros, only digits
+		if element == "" || (element[0] == '0' && len(element) > 1) || strings.TrimLeft(element, "0123456789") != "" {
+			return nil, fmt.Errorf("bad derivation path %s", path)
+		}
+		i, err := strconv.ParseUint(element, 10, 32)
+		if err != nil || uint32(i) >= HARDENED_KEY_START {
+			return nil, fmt.Errorf("bad derivation path %s: index %s out of range", path, element)
+		}
+		if hardened {
+			i += uint64(HARDENED_KEY_START)
+		}
+		indexes = append(indexes, uint32(i))
+	}
+	return indexes, nil
+}
+
+// String serializes the key as an xprv/xpub, or tprv/tpub on testnet
+func (k ExtendedKey) String() string {
+	return encoding.Base58EncodeChecksum(k.serialize())
+}
+
+func (k ExtendedKey) serialize() []byte {
+	var version, keyData []byte
+	switch {
+	case k.IsPrivate() && k.testnet:
+		version = tprvVersion
+	case k.IsPrivate():
+		version = xprvVersion
+	case k.testnet:
+		version = tpubVersion
+	default:
+		version = xpubVersion
+	}
+	if k.IsPrivate() {
+		keyData = append([]byte{0x00}, k.privKey.Secret().FillBytes(make([]byte, 32))...)
+	} else {
+		keyData = k.pubKey.Sec(true)
+	}
+
+	childNumber := make([]byte, 4)
+	binary.BigEndian.PutUint32(childNumber, k.childNumber)
+	return bytes.Join([][]byte{version, {k.depth}, k.parentFingerprint, childNumber, k.chainCode, keyData}, []byte{})
+}
+
+// ParseExtendedKey parses an xprv, xpub, tprv or tpub
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	version, payload, err := encoding.Base58DecodeChecksum(s)
+	if err != nil {
+		return nil, fmt.Errorf("bad extended key: %w", err)
+	}
+	b := append([]byte{version}, payload...)
+	if len(b) != serializedKeySize {
+		return nil, fmt.Errorf("bad extended key: %d bytes, expected %d", len(b), serializedKeySize)
+	}
+
+	key := &ExtendedKey{
+		depth:             b[4],
+		parentFingerprint: b[5:9],
+		childNumber:       binary.BigEndian.Uint32(b[9:13]),
+		chainCode:         b[13:45],
+	}
+	var private bool
+	switch {
+	case bytes.Equal(b[:4], xprvVersion):
+		private = true
+	case bytes.Equal(b[:4], tprvVersion):
+		private, key.testnet = true, true
+	case bytes.Equal(b[:4], xpubVersion):
+	case bytes.Equal(b[:4], tpubVersion):
+		key.testnet = true
+	default:
+		return nil, fmt.Errorf("bad extended key: unknown version %x", b[:4])
+	}
+	if key.depth == 0 && (!bytes.Equal(key.parentFingerprint, make([]byte, 4)) || key.childNumber != 0) {
+		return nil, errors.New("bad extended key: master key with a parent")
+	}
+
+	keyData := b[45:]
+	if private {
+		if keyData[0] != 0x00 {
+			return nil, errors.New("bad extended key: private version with a public key")
+		}
+		secret := new(big.Int).SetBytes(keyData[1:])
+		if secret.Sign() == 0 || secret.Cmp(ecc.N) >= 0 {
+			return nil, errors.New("bad extended key: private key out of range")
+		}
+		key.privKey = ecc.NewPrivateKey(secret)
+		key.pubKey = key.privKey.PublicKey()
+		return key, nil
+	}
+	if keyData[0] != 0x02 && keyData[0] != 0x03 {
+		return nil, errors.New("bad extended key: public version without a compressed public key")
+	}
+	key.pubKey, err = ecc.ParsePubKey(keyData)
+	if err != nil {
+		return nil, fmt.Errorf("bad extended key: %v", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,213 @@
+package hd
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+type derivation struct {
+	path string
+	xpub string
+	xprv string
+}
+
+// BIP 32 test vectors 1 to 3
+var bip32Vectors = []struct {
+	seed        string
+	derivations []derivation
+}{
+	{
+		"000102030405060708090a0b0c0d0e0f",
+		[]derivation{
+			{"m", "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8", "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"},
+			{"m/0H", "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw", "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7"},
+			{"m/0H/1", "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs"},
+			{"m/0H/1/2H", "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5", "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM"},
+			{"m/0H/1/2H/2", "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV", "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334"},
+			{"m/0H/1/2H/2/1000000000", "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy", "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76"},
+		},
+	},
+	{
+		"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		[]derivation{
+			{"m", "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB", "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U"},
+			{"m/0", "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH", "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt"},
+			{"m/0/2147483647H", "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a", "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9"},
+			{"m/0/2147483647H/1", "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon", "xprv9zFnWC6h2cLgpmSA46vutJzBcfJ8yaJGg8cX1e5StJh45BBciYTRXSd25UEPVuesF9yog62tGAQtHjXajPPdbRCHuWS6T8XA2ECKADdw4Ef"},
+			{"m/0/2147483647H/1/2147483646H", "xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL", "xprvA1RpRA33e1JQ7ifknakTFpgNXPmW2YvmhqLQYMmrj4xJXXWYpDPS3xz7iAxn8L39njGVyuoseXzU6rcxFLJ8HFsTjSyQbLYnMpCqE2VbFWc"},
+			{"m/0/2147483647H/1/2147483646H/2", "xpub6FnCn6nSzZAw5Tw7cgR9bi15UV96gLZhjDstkXXxvCLsUXBGXPdSnLFbdpq8p9HmGsApME5hQTZ3emM2rnY5agb9rXpVGyy3bdW6EEgAtqt", "xprvA2nrNbFZABcdryreWet9Ea4LvTJcGsqrMzxHx98MMrotbir7yrKCEXw7nadnHM8Dq38EGfSh6dqA9QWTyefMLEcBYJUuekgW4BYPJcr9E7j"},
+		},
+	},
+	{
+		// leading zeros in the private key
+		"4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be",
+		[]derivation{
+			{"m", "xpub661MyMwAqRbcEZVB4dScxMAdx6d4nFc9nvyvH3v4gJL378CSRZiYmhRoP7mBy6gSPSCYk6SzXPTf3ND1cZAceL7SfJ1Z3GC8vBgp2epUt13", "xprv9s21ZrQH143K25QhxbucbDDuQ4naNntJRi4KUfWT7xo4EKsHt2QJDu7KXp1A3u7Bi1j8ph3EGsZ9Xvz9dGuVrtHHs7pXeTzjuxBrCmmhgC6"},
+			{"m/0H", "xpub68NZiKmJWnxxS6aaHmn81bvJeTESw724CRDs6HbuccFQN9Ku14VQrADWgqbhhTHBaohPX4CjNLf9fq9MYo6oDaPPLPxSb7gwQN3ih19Zm4Y", "xprv9uPDJpEQgRQfDcW7BkF7eTya6RPxXeJCqCJGHuCJ4GiRVLzkTXBAJMu2qaMWPrS7AANYqdq6vcBcBUdJCVVFceUvJFjaPdGZ2y9WACViL4L"},
+		},
+	},
+}
+
+func TestBIP32Vectors(t *testing.T) {
+	for _, vector := range bip32Vectors {
+		seed, _ := hex.DecodeString(vector.seed)
+		master, err := NewMaster(seed, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range vector.derivations {
+			key, err := master.Derive(test.path)
+			if err != nil {
+				t.Errorf("%s: %v", test.path, err)
+				continue
+			}
+			if got := key.String(); got != test.xprv {
+				t.Errorf("%s: expected '%v' but got '%v' instead", test.path, test.xprv, got)
+			}
+			if got := key.Neuter().String(); got != test.xpub {
+				t.Errorf("%s: expected '%v' but got '%v' instead", test.path, test.xpub, got)
+			}
+
+			// both parse back to the same key
+			for _, s := range []string{test.xprv, test.xpub} {
+				parsed, err := ParseExtendedKey(s)
+				if err != nil {
+					t.Errorf("%s: %v", s, err)
+					continue
+				}
+				assert.Equal(t, s, parsed.String())
+			}
+		}
+	}
+}
+
+func TestPublicDerivation(t *testing.T) {
+	// m/0H/1/2H/2/1000000000 from the xpub of m/0H/1/2H
+	xpub := bip32Vectors[0].derivations[3].xpub
+	key, err := ParseExtendedKey(xpub)
+	assert.NoError(t, err)
+
+	child, err := key.Derive("2/1000000000")
+	assert.NoError(t, err)
+	if got := child.String(); got != bip32Vectors[0].derivations[5].xpub {
+		t.Errorf("expected '%v' but got '%v' instead", bip32Vectors[0].derivations[5].xpub, got)
+	}
+
+	_, err = key.Child(HARDENED_KEY_START)
+	if !errors.Is(err, ErrHardenedFromPublic) {
+		t.Errorf("expected '%v' but got '%v' instead", ErrHardenedFromPublic, err)
+	}
+	// the path starts at the master key
+	_, err = key.Derive("m/0")
+	assert.Error(t, err)
+}
+
+func TestFingerprint(t *testing.T) {
+	seed, _ := hex.DecodeString(bip32Vectors[0].seed)
+	master, err := NewMaster(seed, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "3442193e", hex.EncodeToString(master.Fingerprint()))
+
+	child, err := master.Child(HARDENED_KEY_START)
+	assert.NoError(t, err)
+	assert.Equal(t, master.Fingerprint(), child.ParentFingerprint())
+	assert.Equal(t, byte(1), child.Depth())
+	assert.Equal(t, HARDENED_KEY_START, child.ChildNumber())
+}
+
+func TestTestnetKeys(t *testing.T) {
+	seed, _ := hex.DecodeString(bip32Vectors[0].seed)
+	master, err := NewMaster(seed, true)
+	assert.NoError(t, err)
+
+	tprv := master.String()
+	tpub := master.Neuter().String()
+	assert.Equal(t, "tprv", tprv[:4])
+	assert.Equal(t, "tpub", tpub[:4])
+	for _, s := range []string{tprv, tpub} {
+		parsed, err := ParseExtendedKey(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, parsed.String())
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	testCases := []struct {
+		path string
+		want []uint32
+	}{
+		{"m", []uint32{}},
+		{"m/84'/0'/0'/0/5", []uint32{HARDENED_KEY_START + 84, HARDENED_KEY_START, HARDENED_KEY_START, 0, 5}},
+		{"m/0h/1H/2147483647", []uint32{HARDENED_KEY_START, HARDENED_KEY_START + 1, 2147483647}},
+		{"0/1", []uint32{0, 1}},
+	}
+
+	for _, test := range testCases {
+		got, err := ParsePath(test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		assert.Equal(t, test.want, got, test.path)
+	}
+
+	for _, path := range []string{"", "m/", "m//1", "m/1/", "m/-1", "m/+1", "m/01", "m/2147483648", "m/1''", "m/x", "n/1", "m/1/m"} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
+
+func TestParseExtendedKeyErrors(t *testing.T) {
+	version, payload, _ := encoding.Base58DecodeChecksum(bip32Vectors[0].derivations[1].xprv)
+	xprv := append([]byte{version}, payload...)
+	version, payload, _ = encoding.Base58DecodeChecksum(bip32Vectors[0].derivations[1].xpub)
+	xpub := append([]byte{version}, payload...)
+
+	// each case changes a valid key and encodes it with a good checksum
+	testCases := []struct {
+		name   string
+		key    []byte
+		change func(b []byte) []byte
+	}{
+		{"short", xprv, func(b []byte) []byte { return b[:77] }},
+		{"long", xprv, func(b []byte) []byte { return append(b, 0) }},
+		{"unknown version", xprv, func(b []byte) []byte { b[3] = 0xff; return b }},
+		{"private version with a public key", xprv, func(b []byte) []byte { return append(b[:45], xpub[45:]...) }},
+		{"public version with a private key", xpub, func(b []byte) []byte { return append(b[:45], xprv[45:]...) }},
+		{"private key of zero", xprv, func(b []byte) []byte { return append(b[:46], make([]byte, 32)...) }},
+		{"private key of n", xprv, func(b []byte) []byte {
+			n, _ := hex.DecodeString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+			return append(b[:46], n...)
+		}},
+		{"bad public key prefix", xpub, func(b []byte) []byte { b[45] = 0x04; return b }},
+		{"x off the curve", xpub, func(b []byte) []byte {
+			x, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000007")
+			return append(b[:46], x...)
+		}},
+		{"x over p", xpub, func(b []byte) []byte {
+			x, _ := hex.DecodeString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc30")
+			return append(b[:46], x...)
+		}},
+		{"master with a parent fingerprint", xprv, func(b []byte) []byte { b[4] = 0; b[9], b[10], b[11], b[12] = 0, 0, 0, 0; return b }},
+		{"master with a child number", xprv, func(b []byte) []byte { b[4] = 0; b[5], b[6], b[7], b[8] = 0, 0, 0, 0; return b }},
+	}
+
+	for _, test := range testCases {
+		b := test.change(append([]byte{}, test.key...))
+		if _, err := ParseExtendedKey(encoding.Base58EncodeChecksum(b)); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+
+	// a bad checksum
+	s := bip32Vectors[0].derivations[0].xprv
+	_, err := ParseExtendedKey(s[:len(s)-1] + "j")
+	if !errors.Is(err, encoding.ErrBase58Checksum) {
+		t.Errorf("expected '%v' but got '%v' instead", encoding.ErrBase58Checksum, err)
+	}
+}
@@ -0,0 +1,142 @@
+package hd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	MIN_ENTROPY_BITS = 128
+	MAX_ENTROPY_BITS = 256
+
+	bitsPerWord      = 11
+	seedIterations   = 2048
+	seedSize         = 64
+	mnemonicSaltBase = "mnemonic"
+)
+
+// english.txt is the BIP 39 English wordlist, sha256
+// 2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda
+//
+//go:embed english.txt
+var englishWords string
+
+var (
+	englishWordList = strings.Fields(englishWords)
+	englishIndex    = make(map[string]int, len(englishWordList))
+)
+
+func init() {
+	for i, word := range englishWordList {
+		englishIndex[word] = i
+	}
+}
+
+// ErrMnemonicChecksum is returned for mnemonics whose last word doesn't
+// match the checksum of the entropy, usually a typo in one of the words
+var ErrMnemonicChecksum = errors.New("bad mnemonic checksum")
+
+// NewEntropy returns bits of random entropy. bits must be a multiple of 32
+// from 128 to 256, which gives 12 to 24 words
+func NewEntropy(bits int) ([]byte, error) {
+	if err := checkEntropyBits(bits); err != nil {
+		return nil, err
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+func checkEntropyBits(bits int) error {
+	if bits < MIN_ENTROPY_BITS || bits > MAX_ENTROPY_BITS || bits%32 != 0 {
+		return fmt.Errorf("entropy is %d bits, it must be a multiple of 32 from %d to %d", bits, MIN_ENTROPY_BITS, MAX_ENTROPY_BITS)
+	}
+	return nil
+}
+
+// NewMnemonic returns the English mnemonic of entropy: the entropy followed
+// by the first bits/32 bits of its sha256, split in 11 bit words
+func NewMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if err := checkEntropyBits(bits); err != nil {
+		return "", err
+	}
+	checksumBits := bits / 32
+	hash := sha256.Sum256(entropy)
+
+	// entropy || checksum as a single number
+	num := new(big.Int).SetBytes(entropy)
+	num.Lsh(num, uint(checksumBits))
+	num.Or(num, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	numWords := (bits + checksumBits) / bitsPerWord
+	words := make([]string, numWords)
+	mask := big.NewInt(1<<bitsPerWord - 1)
+	for i := numWords - 1; i >= 0; i-- {
+		words[i] = englishWordList[new(big.Int).And(num, mask).Int64()]
+		num.Rsh(num, bitsPerWord)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy checks the words and the checksum of mnemonic and
+// returns the entropy it encodes
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words)%3 != 0 || len(words) < 12 || len(words) > 24 {
+		return nil, fmt.Errorf("mnemonic has %d words, it must have 12, 15, 18, 21 or 24", len(words))
+	}
+
+	num := big.NewInt(0)
+	for i, word := range words {
+		index, ok := englishIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("word %d %q is not in the wordlist", i+1, word)
+		}
+		num.Lsh(num, bitsPerWord)
+		num.Or(num, big.NewInt(int64(index)))
+	}
+
+	checksumBits := len(words) * bitsPerWord / 33
+	checksum := new(big.Int).And(num, big.NewInt(1<<checksumBits-1)).Int64()
+	entropy := num.Rsh(num, uint(checksumBits)).FillBytes(make([]byte, checksumBits*4))
+
+	hash := sha256.Sum256(entropy)
+	if int64(hash[0]>>(8-checksumBits)) != checksum {
+		return nil, ErrMnemonicChecksum
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic reports why mnemonic isn't a valid English mnemonic, nil
+// if it is
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// NewSeed validates mnemonic and returns the 64 byte seed for it and an
+// optional passphrase, which NewMaster takes. Both are NFKD normalized and
+// the seed is PBKDF2-HMAC-SHA512 with 2048 iterations and
+// "mnemonic"+passphrase as salt. A different passphrase gives a different,
+// valid, wallet
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	// the words are joined by single spaces, however they were typed
+	password := norm.NFKD.String(strings.Join(strings.Fields(mnemonic), " "))
+	salt := norm.NFKD.String(mnemonicSaltBase + passphrase)
+	return pbkdf2.Key([]byte(password), []byte(salt), seedIterations, seedSize, sha512.New), nil
+}
@@ -0,0 +1,171 @@
+package hd
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBIP39Vectors(t *testing.T) {
+	// the vectors of the reference implementation, all with the passphrase
+	// TREZOR
+	testCases := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			"80808080808080808080808080808080",
+			"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+			"d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+		},
+		{
+			"ffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+			"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+		},
+		{
+			"000000000000000000000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon agent",
+			"035895f2f481b1b0f01fcf8c289c794660b289981a78f8106447707fdd9666ca06da5a9a565181599b79f53b844d8a71dd9f439c52a3d7b3e8a79c906ac845fa",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+			"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+		},
+		{
+			"9e885d952ad362caeb4efe34a8e91bd2",
+			"ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+			"274ddc525802f7c828d8ef7ddbcdc5304e87ac3535913611fbbfa986d0c9e5476c91689f9c8a54fd55bd38606aa6a8595ad213d4c9c9f9aca3fb217069a41028",
+		},
+		{
+			"f585c11aec520db57dd353c69554b21a89b20fb0650966fa0a9d6f74fd989d8f",
+			"void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen patrol group space point ten exist slush involve unfold",
+			"01f5bced59dec48e362f2c45b5de68b9fd6c92c6634f44d6d40aab69056506f0e35524a518034ddc1192e1dacd32c1ed3eaa3c3b131c88ed8e7e54c49a5d0998",
+		},
+	}
+
+	for _, test := range testCases {
+		entropy, _ := hex.DecodeString(test.entropy)
+		mnemonic, err := NewMnemonic(entropy)
+		if err != nil || mnemonic != test.mnemonic {
+			t.Errorf("expected '%v' but got '%v' (%v) instead", test.mnemonic, mnemonic, err)
+		}
+
+		got, err := MnemonicToEntropy(test.mnemonic)
+		if err != nil || hex.EncodeToString(got) != test.entropy {
+			t.Errorf("expected '%v' but got '%x' (%v) instead", test.entropy, got, err)
+		}
+
+		seed, err := NewSeed(test.mnemonic, "TREZOR")
+		if err != nil || hex.EncodeToString(seed) != test.seed {
+			t.Errorf("expected '%v' but got '%x' (%v) instead", test.seed, seed, err)
+		}
+	}
+}
+
+func TestSeedToMasterKey(t *testing.T) {
+	seed, err := NewSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "TREZOR")
+	assert.NoError(t, err)
+	master, err := NewMaster(seed, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "xprv9s21ZrQH143K3h3fDYiay8mocZ3afhfULfb5GX8kCBdno77K4HiA15Tg23wpbeF1pLfs1c5SPmYHrEpTuuRhxMwvKDwqdKiGJS9XFKzUsAF", master.String())
+}
+
+func TestNewSeedNormalization(t *testing.T) {
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+	// extra spaces between the words don't change the seed
+	seed, err := NewSeed(mnemonic, "")
+	assert.NoError(t, err)
+	spaced, err := NewSeed("  legal winner  thank year wave sausage worth useful legal winner thank\tyellow ", "")
+	assert.NoError(t, err)
+	assert.Equal(t, seed, spaced)
+
+	// é typed as a single character or as e and an accent
+	composed, err := NewSeed(mnemonic, "caf\u00e9")
+	assert.NoError(t, err)
+	decomposed, err := NewSeed(mnemonic, "cafe\u0301")
+	assert.NoError(t, err)
+	assert.Equal(t, composed, decomposed)
+	assert.NotEqual(t, seed, composed)
+}
+
+func TestValidateMnemonic(t *testing.T) {
+	testCases := []struct {
+		mnemonic string
+		err      error
+	}{
+		{"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", nil},
+		// the last word doesn't match the checksum
+		{"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", ErrMnemonicChecksum},
+		{"legal winner thank year wave sausage worth useful legal winner thank thank", ErrMnemonicChecksum},
+	}
+	for _, test := range testCases {
+		err := ValidateMnemonic(test.mnemonic)
+		if !errors.Is(err, test.err) {
+			t.Errorf("expected '%v' but got '%v' instead", test.err, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abou",
+		"Abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		strings.Repeat("zoo ", 27),
+	}
+	for _, mnemonic := range invalid {
+		assert.Error(t, ValidateMnemonic(mnemonic), mnemonic)
+	}
+
+	_, err := NewSeed("zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo", "")
+	assert.ErrorIs(t, err, ErrMnemonicChecksum)
+}
+
+func TestNewEntropy(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		entropy, err := NewEntropy(bits)
+		if err != nil {
+			t.Errorf("%d: %v", bits, err)
+			continue
+		}
+		assert.Len(t, entropy, bits/8)
+
+		mnemonic, err := NewMnemonic(entropy)
+		assert.NoError(t, err)
+		assert.Len(t, strings.Fields(mnemonic), bits/32*3)
+		got, err := MnemonicToEntropy(mnemonic)
+		assert.NoError(t, err)
+		assert.Equal(t, entropy, got)
+	}
+
+	for _, bits := range []int{0, 96, 127, 130, 288} {
+		_, err := NewEntropy(bits)
+		assert.Error(t, err, bits)
+	}
+	_, err := NewMnemonic(make([]byte, 15))
+	assert.Error(t, err)
+}
+
+func TestWordList(t *testing.T) {
+	assert.Len(t, englishWordList, 2048)
+	assert.Equal(t, "abandon", englishWordList[0])
+	assert.Equal(t, "zoo", englishWordList[2047])
+	assert.Len(t, englishIndex, 2048)
+}
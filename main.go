@@ -2,13 +2,179 @@ package main
 
 import (
 	"encoding/hex"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
+
+	"github.com/miguelhun/programmingbitcoin-go/block"
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/miguelhun/programmingbitcoin-go/script"
+	"github.com/miguelhun/programmingbitcoin-go/tx"
+	"github.com/miguelhun/programmingbitcoin-go/wire"
 )
 
+const usage = `usage: programmingbitcoin-go <command> [flags] <hex>
+
+commands:
+  envelope  parse a network envelope
+  tx        parse a transaction
+  block     parse a block header
+  address   print the address and wif for a secret
+  trace     run <scriptSig hex> <scriptPubKey hex> step by step
+`
+
 func main() {
-	networkMessage, _ := hex.DecodeString("f9beb4d976657261636b000000000000000000005df6e0e2")
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	testnet := fs.Bool("testnet", false, "use testnet")
+	witness := fs.String("witness", "", "comma separated witness items in hex, for trace")
+	verifyFlags := fs.String("flags", "P2SH,WITNESS,TAPROOT", "comma separated script verify flags, for trace")
+	fs.Parse(os.Args[2:])
+	args := 1
+	if os.Args[1] == "trace" {
+		args = 2
+	}
+	if fs.NArg() != args {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "envelope":
+		err = printEnvelope(fs.Arg(0), *testnet)
+	case "tx":
+		err = printTx(fs.Arg(0))
+	case "block":
+		err = printBlock(fs.Arg(0))
+	case "address":
+		err = printAddress(fs.Arg(0), *testnet)
+	case "trace":
+		err = printTrace(fs.Arg(0), fs.Arg(1), *witness, *verifyFlags)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func printEnvelope(raw string, testnet bool) error {
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return err
+	}
+	envelope, err := wire.ParseNetworkEnvelope(b, testnet)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("command: %s\n", envelope.Command())
+	fmt.Printf("payload: %x\n", envelope.Payload())
+	return nil
+}
+
+func printTx(raw string) error {
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return err
+	}
+	t, err := tx.ParseTx(b)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("id: %x\n", t.ID())
+	if t.HasWitness() {
+		fmt.Printf("wtxid: %x\n", t.WitnessID())
+	}
+	fmt.Printf("version: %d\n", t.Version())
+	for i, txIn := range t.TxIns() {
+		prevTxId := txIn.PrevTxID()
+		fmt.Printf("input %d: %x:%d\n", i, prevTxId[:], txIn.PrevTxIdx())
+		for j, item := range txIn.Witness() {
+			fmt.Printf("  witness %d: %x\n", j, item)
+		}
+	}
+	for i, txOut := range t.TxOuts() {
+		fmt.Printf("output %d: %d %x\n", i, txOut.Value(), txOut.ScriptPubKey().RawSerialize())
+	}
+	fmt.Printf("locktime: %d\n", t.Locktime())
+	return nil
+}
+
+func printBlock(raw string) error {
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return err
+	}
+	blk, err := block.ParseBlock(b)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("id: %x\n", blk.ID())
+	fmt.Printf("version: %d\n", blk.Version())
+	fmt.Printf("target: %064x\n", blk.Target())
+	fmt.Printf("difficulty: %d\n", blk.Difficulty())
+	fmt.Printf("valid pow: %t\n", blk.CheckPow())
+	return nil
+}
+
+func printAddress(secret string, testnet bool) error {
+	privKey := ecc.NewPrivateKey(encoding.FromHex(secret))
+	fmt.Printf("address: %s\n", privKey.PublicKey().Address(true, testnet))
+	fmt.Printf("wif: %s\n", privKey.Wif(true, testnet))
+	return nil
+}
+
+// printTrace runs the scripts without a transaction, so signature checks
+// fail, and prints every step
+func printTrace(scriptSigHex, scriptPubKeyHex, witnessHex, flagNames string) error {
+	scriptSig, err := parseHexScript(scriptSigHex)
+	if err != nil {
+		return err
+	}
+	scriptPubKey, err := parseHexScript(scriptPubKeyHex)
+	if err != nil {
+		return err
+	}
+	var witness [][]byte
+	if witnessHex != "" {
+		for _, item := range strings.Split(witnessHex, ",") {
+			b, err := hex.DecodeString(item)
+			if err != nil {
+				return err
+			}
+			witness = append(witness, b)
+		}
+	}
+	flags, err := script.ParseVerifyFlags(flagNames)
+	if err != nil {
+		return err
+	}
+
+	trace := &script.Trace{}
+	valid, err := script.VerifyScriptDebug(scriptSig, scriptPubKey, witness, nil, flags, trace)
+	fmt.Print(trace)
+	if !valid {
+		fmt.Printf("result: invalid (%v)\n", err)
+		return nil
+	}
+	fmt.Println("result: valid")
+	return nil
+}
 
-	netenvelope := parseNetworkEnvelope(networkMessage, false)
-	fmt.Println(string(netenvelope.command[:]))
-	fmt.Println(string(netenvelope.payload))
+func parseHexScript(s string) (*script.Script, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return script.ParseRawScript(b)
 }
@@ -0,0 +1,80 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+// ErrNoAddress is returned for scripts that have no address, like null data,
+// bare multisig and non-standard scripts
+var ErrNoAddress = errors.New("script has no address")
+
+// Address returns the address that pays to the script, on mainnet or
+// testnet. P2PK scripts return the p2pkh address of their key, like Bitcoin
+// Core does. Use encoding.EncodeSegwitAddress with encoding.RegtestHRP for
+// regtest segwit addresses
+func (sc Script) Address(testnet bool) (string, error) {
+	typ, solutions := sc.Solve()
+	switch typ {
+	case TX_PUBKEY:
+		return encoding.H160ToP2PKH(encoding.Hash160(solutions[0]), testnet), nil
+	case TX_PUBKEYHASH:
+		return encoding.H160ToP2PKH(solutions[0], testnet), nil
+	case TX_SCRIPTHASH:
+		return encoding.H160ToP2SH(solutions[0], testnet), nil
+	case TX_WITNESS_V0_KEYHASH, TX_WITNESS_V0_SCRIPTHASH, TX_WITNESS_V1_TAPROOT, TX_WITNESS_UNKNOWN:
+		hrp := encoding.MainnetHRP
+		if testnet {
+			hrp = encoding.TestnetHRP
+		}
+		version, program, _ := sc.WitnessProgram()
+		return encoding.EncodeSegwitAddress(hrp, version, program)
+	}
+	return "", fmt.Errorf("%w: %v", ErrNoAddress, typ)
+}
+
+// DecodeAddress returns the scriptPubKey an address pays to. It takes base58
+// p2pkh and p2sh addresses and bech32/bech32m segwit addresses of mainnet,
+// testnet and regtest
+func DecodeAddress(address string) (*Script, error) {
+	lower := strings.ToLower(address)
+	for _, hrp := range []string{encoding.MainnetHRP, encoding.TestnetHRP, encoding.RegtestHRP} {
+		if !strings.HasPrefix(lower, hrp+"1") {
+			continue
+		}
+		version, program, err := encoding.DecodeSegwitAddress(hrp, address)
+		if err != nil {
+			return nil, fmt.Errorf("bad address %s: %v", address, err)
+		}
+		return witnessProgramScript(version, program), nil
+	}
+
+	version, hash, err := encoding.Base58DecodeChecksum(address)
+	if err != nil {
+		return nil, fmt.Errorf("bad address %s: %w", address, err)
+	}
+	if len(hash) != 20 {
+		return nil, fmt.Errorf("bad address %s: %d byte hash", address, len(hash))
+	}
+	switch version {
+	case 0x00, 0x6f:
+		return P2PKHScript(hash), nil
+	case 0x05, 0xc4:
+		return P2SHScript(hash), nil
+	}
+	return nil, fmt.Errorf("bad address %s: unknown version %#02x", address, version)
+}
+
+// witnessProgramScript returns the scriptPubKey <version opcode> <program>
+func witnessProgramScript(version int, program []byte) *Script {
+	op := byte(0x00)
+	if version > 0 {
+		op = byte(0x50 + version)
+	}
+	return &Script{
+		cmds: [][]byte{{op}, program},
+	}
+}
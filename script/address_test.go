@@ -0,0 +1,111 @@
+package script
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptAddress(t *testing.T) {
+	testCases := []struct {
+		script  *Script
+		testnet bool
+		want    string
+	}{
+		{P2PKScript(fromHex(genesisPubKey)), false, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+		{P2PKHScript(fromHex("62e907b15cbf27d5425399ebf6f0fb50ebb88f18")), false, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+		{P2SHScript(fromHex("748284390f9e263a4b766a75d0633c50426eb875")), false, "3CK4fEwbMP7heJarmU4eqA3sMbVJyEnU3V"},
+		{P2WPKHScript(fromHex("751e76e8199196d454941c45d1b3a323f1433bd6")), false, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{P2WSHScript(fromHex("1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262")), true, "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7"},
+		{P2TRScript(fromHex("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")), false, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0"},
+		{&Script{raw: fromHex("6002751e")}, false, "bc1sw50qgdz25j"},
+	}
+
+	for _, test := range testCases {
+		got, err := test.script.Address(test.testnet)
+		if err != nil || got != test.want {
+			t.Errorf("expected '%v' but got '%v' (%v) instead", test.want, got, err)
+		}
+	}
+
+	_, err := NullDataScript(nil).Address(false)
+	if !errors.Is(err, ErrNoAddress) {
+		t.Errorf("expected '%v' but got '%v' instead", ErrNoAddress, err)
+	}
+}
+
+func TestDecodeAddress(t *testing.T) {
+	testCases := []struct {
+		address      string
+		scriptPubKey string
+	}{
+		// BIP 350 test vectors
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+		{"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"BC1SW50QGDZ25J", "6002751e"},
+		{"bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", "5210751e76e8199196d454941c45d1b3a323"},
+		{"tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", "0020000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", "5120000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+		// base58
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac"},
+		{"3CK4fEwbMP7heJarmU4eqA3sMbVJyEnU3V", "a914748284390f9e263a4b766a75d0633c50426eb87587"},
+	}
+
+	for _, test := range testCases {
+		script, err := DecodeAddress(test.address)
+		if err != nil {
+			t.Errorf("%s: %v", test.address, err)
+			continue
+		}
+		if got := hex.EncodeToString(script.RawSerialize()); got != test.scriptPubKey {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.address, test.scriptPubKey, got)
+		}
+	}
+
+	invalid := []string{
+		"tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd",
+		"tb1z0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqglt7rf",
+		"BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL",
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh",
+		"tb1q0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq24jc47",
+		"bc1p38j9r5y49hruaue7wxjce0updqjuyyx0kh56v8s25huc6995vvpql3jow4",
+		"BC130XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ7ZWS8R",
+		"bc1pw5dgrnzv",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v8n0nx0muaewav253zgeav",
+		"BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P",
+		"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq47Zagq",
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7v07qwwzcrf",
+		"tb1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vpggkg4j",
+		"bc1gmk9yu",
+		// checksum off by one character
+		"3CK4fEwbMP7heJarmU4eqA3sMbVJyEnU3W",
+		// 0 is not in the alphabet
+		"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfN0",
+		// a wif, not an address
+		"5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ",
+	}
+	for _, address := range invalid {
+		_, err := DecodeAddress(address)
+		assert.Error(t, err, address)
+	}
+
+	// addresses of every type decode back to their script
+	hash := fromHex("751e76e8199196d454941c45d1b3a323f1433bd6")
+	for _, script := range []*Script{P2PKHScript(hash), P2SHScript(hash), P2WPKHScript(hash)} {
+		for _, testnet := range []bool{false, true} {
+			address, err := script.Address(testnet)
+			assert.NoError(t, err)
+			decoded, err := DecodeAddress(address)
+			if err != nil {
+				t.Errorf("%s: %v", address, err)
+				continue
+			}
+			assert.Equal(t, script.RawSerialize(), decoded.RawSerialize(), address)
+		}
+	}
+}
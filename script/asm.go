@@ -0,0 +1,128 @@
+package script
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// String returns the script in Bitcoin Core's ASM notation: opcode names,
+// small numbers in decimal and other pushes in hex. Pushes that read like
+// a number are written with 0x to tell them apart
+func (sc Script) String() string {
+	raw := sc.RawSerialize()
+	instructions, err := parseInstructions(raw)
+	words := make([]string, 0, len(instructions)+1)
+	for _, ins := range instructions {
+		words = append(words, asmWord(ins))
+	}
+	if err != nil {
+		words = append(words, "[error]")
+	}
+	return strings.Join(words, " ")
+}
+
+func asmWord(ins instruction) string {
+	switch {
+	case ins.op == 0x00:
+		return "0"
+	case ins.op == 0x4f:
+		return "-1"
+	case ins.op >= 0x51 && ins.op <= 0x60:
+		return strconv.Itoa(int(ins.op) - 0x50)
+	case ins.op > 0x4e:
+		if name, ok := opcodesNames[ins.op]; ok {
+			return name
+		}
+		return "OP_UNKNOWN"
+	}
+	// numbers ParseASM would push the same way
+	if len(ins.data) <= maxScriptNumLen && isMinimalNum(ins.data) && ins.isMinimalPush() {
+		return strconv.Itoa(decodeNum(ins.data))
+	}
+	data := hex.EncodeToString(ins.data)
+	if isASMNumber(data) {
+		return "0x" + data
+	}
+	return data
+}
+
+// ParseASM parses a script written in ASM, like
+// "OP_DUP OP_HASH160 <20 byte hash hex> OP_EQUALVERIFY OP_CHECKSIG". Words
+// are decimal numbers, opcode names with or without OP_ and data to push in
+// hex, optionally prefixed with 0x. Numbers and data are pushed with the
+// shortest encoding, so the ASM of any script that uses minimal pushes
+// parses back to the same script
+func ParseASM(asm string) (*Script, error) {
+	var raw []byte
+	for _, word := range strings.Fields(asm) {
+		if isASMNumber(word) {
+			n, err := parseASMNumber(word)
+			if err != nil {
+				return nil, err
+			}
+			raw = append(raw, pushNumber(n)...)
+			continue
+		}
+		if op, ok := OpcodeByName(word); ok {
+			raw = append(raw, op)
+			continue
+		}
+		data, err := hex.DecodeString(strings.TrimPrefix(word, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("bad ASM word %q", word)
+		}
+		raw = append(raw, pushMinimal(data)...)
+	}
+	return ParseRawScript(raw)
+}
+
+// isASMNumber reports whether word is written as a decimal number, without
+// leading zeros. Such words are never read as hex
+func isASMNumber(word string) bool {
+	digits := strings.TrimPrefix(word, "-")
+	if digits == "" || (digits[0] == '0' && len(digits) > 1) || (word[0] == '-' && digits == "0") {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseASMNumber reads a decimal word, which must be in the 4 byte script
+// number range
+func parseASMNumber(word string) (int, error) {
+	n, err := strconv.ParseInt(word, 10, 64)
+	if err != nil || n > 0x7fffffff || n < -0x7fffffff {
+		return 0, fmt.Errorf("number %s out of range -2147483647 to 2147483647", word)
+	}
+	return int(n), nil
+}
+
+// pushNumber pushes n with OP_0, OP_1NEGATE or OP_1-OP_16 when it can
+func pushNumber(n int) []byte {
+	switch {
+	case n == 0:
+		return []byte{0x00}
+	case n == -1 || (n >= 1 && n <= 16):
+		return []byte{byte(0x50 + n)}
+	}
+	return pushData(encodeNum(n))
+}
+
+// pushMinimal pushes data the way MINIMALDATA wants it
+func pushMinimal(data []byte) []byte {
+	switch {
+	case len(data) == 0:
+		return []byte{0x00}
+	case len(data) == 1 && data[0] >= 1 && data[0] <= 16:
+		return []byte{0x50 + data[0]}
+	case len(data) == 1 && data[0] == 0x81:
+		return []byte{0x4f}
+	}
+	return pushData(data)
+}
@@ -0,0 +1,122 @@
+package script
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptString(t *testing.T) {
+	testCases := []struct {
+		raw  string
+		want string
+	}{
+		{"76a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac", "OP_DUP OP_HASH160 bc3b654dca7e56b04dca18f2566cdaf02e8d9ada OP_EQUALVERIFY OP_CHECKSIG"},
+		{"0014751e76e8199196d454941c45d1b3a323f1433bd6", "0 751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{"4f00515f60", "-1 0 1 15 16"},
+		// numbers pushed as data
+		{"01110210270340420f", "17 10000 1000000"},
+		{"04deadbeef", "-1874767326"},
+		{"0181" + "01ff", "0x81 -127"},
+		// data that would read as a number
+		{"021200", "0x1200"},
+		{"052147483648", "0x2147483648"},
+		{"6a05deadbeef01", "OP_RETURN deadbeef01"},
+		{"b1b2ba", "OP_CHECKLOCKTIMEVERIFY OP_CHECKSEQUENCEVERIFY OP_CHECKSIGADD"},
+		{"51ff", "1 OP_UNKNOWN"},
+		{"5102ab", "1 [error]"},
+	}
+
+	for _, test := range testCases {
+		script := &Script{raw: fromHex(test.raw)}
+		if got := script.String(); got != test.want {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.raw, test.want, got)
+		}
+	}
+}
+
+func TestParseASM(t *testing.T) {
+	testCases := []struct {
+		asm  string
+		want string
+	}{
+		{"OP_DUP OP_HASH160 bc3b654dca7e56b04dca18f2566cdaf02e8d9ada OP_EQUALVERIFY OP_CHECKSIG", "76a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac"},
+		{"DUP HASH160 0xbc3b654dca7e56b04dca18f2566cdaf02e8d9ada EQUALVERIFY CHECKSIG", "76a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac"},
+		{"0 -1 1 16 17 -17 128 -2147483647", "004f516001110191028000" + "04ffffffff"},
+		{"2147483647", "04ffffff7f"},
+		// out of the 4 byte range, not hex
+		{"2147483648", ""},
+		{"-2147483648", ""},
+		{"99999999999999999999", ""},
+		// minimal pushes for data too
+		{"05 81 00 ''", ""},
+		{"OP_TRUE OP_FALSE OP_NOP2", "5100b1"},
+	}
+
+	for _, test := range testCases {
+		script, err := ParseASM(test.asm)
+		if test.want == "" {
+			assert.Error(t, err, test.asm)
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.asm, err)
+			continue
+		}
+		if got := hex.EncodeToString(script.RawSerialize()); got != test.want {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.asm, test.want, got)
+		}
+	}
+
+	// a word without 0x is read as a number first
+	script, err := ParseASM("05 0x81 81 00")
+	assert.NoError(t, err)
+	assert.Equal(t, "554f01510100", hex.EncodeToString(script.RawSerialize()))
+
+	for _, asm := range []string{"OP_NOTANOPCODE", "abc", "0xzz"} {
+		_, err := ParseASM(asm)
+		assert.Error(t, err, asm)
+	}
+}
+
+func TestASMRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		// random scripts made of minimal pushes and known opcodes
+		var raw []byte
+		for j := r.Intn(20); j > 0; j-- {
+			switch r.Intn(3) {
+			case 0:
+				data := make([]byte, r.Intn(80))
+				r.Read(data)
+				if len(data) > 0 && r.Intn(2) == 0 {
+					data = data[:1]
+				}
+				raw = append(raw, pushMinimal(data)...)
+			case 1:
+				raw = append(raw, pushNumber(r.Intn(1<<20)-1<<19)...)
+			default:
+				op := byte(0x61 + r.Intn(0xba-0x61+1))
+				if _, ok := opcodesNames[op]; ok {
+					raw = append(raw, op)
+				}
+			}
+		}
+
+		asm := (&Script{raw: raw}).String()
+		if strings.Contains(asm, "[error]") {
+			t.Fatalf("%x: bad script", raw)
+		}
+		script, err := ParseASM(asm)
+		if err != nil {
+			t.Fatalf("%s: %v", asm, err)
+		}
+		if !bytes.Equal(script.RawSerialize(), raw) {
+			t.Errorf("%s: expected '%x' but got '%x' instead", asm, raw, script.RawSerialize())
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Step is what happened at one instruction the interpreter read
+type Step struct {
+	Script     []byte // the script being run
+	SigVersion SigVersion
+	Pos        int // byte offset of the instruction in Script
+	Op         byte
+	Data       []byte // pushed by the instruction
+
+	// Executed is false in a branch that is not taken, where only the
+	// conditional opcodes run. Branches says which branch every enclosing
+	// OP_IF takes, innermost last
+	Executed bool
+	Branches []bool
+
+	Stack, AltStack           [][]byte // before the instruction
+	StackAfter, AltStackAfter [][]byte // nil if it failed
+	Err                       error
+}
+
+// StepHook is told about every instruction the interpreter reads
+type StepHook interface {
+	OnStep(step Step)
+}
+
+// StepHookFunc lets a plain function be a StepHook
+type StepHookFunc func(step Step)
+
+func (f StepHookFunc) OnStep(step Step) {
+	f(step)
+}
+
+// EvaluateDebug is Evaluate telling hook about every step
+func (sc Script) EvaluateDebug(input *InputContext, flags VerifyFlags, hook StepHook) (bool, error) {
+	ctx := newExecContext(input, flags, nil)
+	ctx.hook = hook
+	return sc.evaluate(ctx)
+}
+
+// VerifyScriptDebug is VerifyScript telling hook about every step of every
+// script it runs
+func VerifyScriptDebug(scriptSig, scriptPubKey *Script, witness [][]byte, input *InputContext, flags VerifyFlags, hook StepHook) (bool, error) {
+	ctx := newExecContext(input, flags, nil)
+	ctx.hook = hook
+	err := ctx.verify(scriptSig, scriptPubKey, witness)
+	return err == nil, err
+}
+
+// Trace is a StepHook that keeps every step
+type Trace struct {
+	Steps []Step
+}
+
+func (t *Trace) OnStep(step Step) {
+	t.Steps = append(t.Steps, step)
+}
+
+// String prints the steps one per line with the stack they leave, top
+// last. Skipped instructions are marked with a dash and every script run
+// gets a header
+func (t *Trace) String() string {
+	var b strings.Builder
+	for _, step := range t.Steps {
+		// every script starts at 0
+		if step.Pos == 0 {
+			fmt.Fprintf(&b, "%s script %x\n", sigVersionNames[step.SigVersion], step.Script)
+		}
+		mark := " "
+		if !step.Executed {
+			mark = "-"
+		}
+		line := fmt.Sprintf("%s %4d  %-24s ", mark, step.Pos, stepName(step))
+		switch {
+		case step.Err != nil:
+			line += fmt.Sprintf("error: %v", step.Err)
+		case step.Executed:
+			line += fmt.Sprintf("[%s]", formatStack(step.StackAfter))
+			if len(step.AltStackAfter) > 0 {
+				line += fmt.Sprintf(" alt [%s]", formatStack(step.AltStackAfter))
+			}
+		}
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return b.String()
+}
+
+var sigVersionNames = map[SigVersion]string{
+	SIGVERSION_BASE:       "legacy",
+	SIGVERSION_WITNESS_V0: "witness v0",
+	SIGVERSION_TAPROOT:    "taproot",
+	SIGVERSION_TAPSCRIPT:  "tapscript",
+}
+
+func stepName(step Step) string {
+	if step.Op > 0x00 && step.Op <= 0x4e {
+		return fmt.Sprintf("PUSH %x", step.Data)
+	}
+	if name, ok := opcodesNames[step.Op]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", step.Op)
+}
+
+func formatStack(stack [][]byte) string {
+	items := make([]string, len(stack))
+	for i, item := range stack {
+		items[i] = fmt.Sprintf("%x", item)
+		if len(item) == 0 {
+			items[i] = "''"
+		}
+	}
+	return strings.Join(items, " ")
+}
+
+// debugStep starts recording the instruction at pos if there's a hook
+func (ctx *execContext) debugStep(ins instruction, pos int, exec bool, vfExec []bool, stack, altStack [][]byte) *Step {
+	if ctx.hook == nil {
+		return nil
+	}
+	return &Step{
+		Script:     ctx.script,
+		SigVersion: ctx.sigVersion,
+		Pos:        pos,
+		Op:         ins.op,
+		Data:       ins.data,
+		Executed:   exec || (ins.op >= 0x63 && ins.op <= 0x68),
+		Branches:   append([]bool(nil), vfExec...),
+		Stack:      copyStack(stack),
+		AltStack:   copyStack(altStack),
+	}
+}
+
+// endStep tells the hook about step with the stacks it left or its error
+func (ctx *execContext) endStep(step *Step, stack, altStack [][]byte, err error) {
+	if step == nil {
+		return
+	}
+	if err != nil {
+		step.Err = err
+	} else {
+		step.StackAfter = copyStack(stack)
+		step.AltStackAfter = copyStack(altStack)
+	}
+	ctx.hook.OnStep(*step)
+}
+
+func copyStack(stack [][]byte) [][]byte {
+	result := make([][]byte, len(stack))
+	copy(result, stack)
+	return result
+}
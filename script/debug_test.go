@@ -0,0 +1,73 @@
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyScriptDebug(t *testing.T) {
+	// scriptSig: 1 2, scriptPubKey: TOALTSTACK 0 IF RETURN ENDIF FROMALTSTACK 2 EQUALVERIFY
+	scriptSig := &Script{raw: fromHex("5152")}
+	scriptPubKey := &Script{raw: fromHex("6b00636a686c5288")}
+
+	trace := &Trace{}
+	valid, err := VerifyScriptDebug(scriptSig, scriptPubKey, nil, nil, SCRIPT_VERIFY_NONE, trace)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	testCases := []struct {
+		op       byte
+		executed bool
+		stack    [][]byte
+		altStack [][]byte
+	}{
+		{0x51, true, [][]byte{{1}}, [][]byte{}},
+		{0x52, true, [][]byte{{1}, {2}}, [][]byte{}},
+		{0x6b, true, [][]byte{{1}}, [][]byte{{2}}},
+		{0x00, true, [][]byte{{1}, {}}, [][]byte{{2}}},
+		{0x63, true, [][]byte{{1}}, [][]byte{{2}}},
+		{0x6a, false, [][]byte{{1}}, [][]byte{{2}}},
+		{0x68, true, [][]byte{{1}}, [][]byte{{2}}},
+		{0x6c, true, [][]byte{{1}, {2}}, [][]byte{}},
+		{0x52, true, [][]byte{{1}, {2}, {2}}, [][]byte{}},
+		{0x88, true, [][]byte{{1}}, [][]byte{}},
+	}
+	if len(trace.Steps) != len(testCases) {
+		t.Fatalf("expected '%v' steps but got '%v' instead", len(testCases), len(trace.Steps))
+	}
+	for i, test := range testCases {
+		step := trace.Steps[i]
+		if step.Op != test.op || step.Executed != test.executed {
+			t.Errorf("step %d: expected '%x' (%v) but got '%x' (%v) instead", i, test.op, test.executed, step.Op, step.Executed)
+		}
+		assert.Equal(t, test.stack, step.StackAfter, "step %d", i)
+		assert.Equal(t, test.altStack, step.AltStackAfter, "step %d", i)
+	}
+	// the stack before a step is the one the step before left
+	assert.Equal(t, trace.Steps[1].StackAfter, trace.Steps[2].Stack)
+	assert.Equal(t, []bool{false}, trace.Steps[5].Branches)
+
+	out := trace.String()
+	if !strings.Contains(out, "legacy script 6b00636a686c5288") || !strings.Contains(out, "-    3  OP_RETURN\n") {
+		t.Errorf("unexpected trace:\n%s", out)
+	}
+}
+
+func TestVerifyScriptDebugError(t *testing.T) {
+	var last Step
+	hook := StepHookFunc(func(step Step) {
+		last = step
+	})
+	_, err := VerifyScriptDebug(&Script{raw: []byte{}}, &Script{raw: fromHex("515288")}, nil, nil, SCRIPT_VERIFY_NONE, hook)
+	assert.ErrorIs(t, err, ErrEqualVerify)
+
+	var scriptErr *ScriptError
+	if !errors.As(last.Err, &scriptErr) || scriptErr.Pos != 2 {
+		t.Errorf("expected '%v' but got '%v' instead", ErrEqualVerify, last.Err)
+	}
+	assert.Nil(t, last.StackAfter)
+	assert.Equal(t, [][]byte{{1}, {2}}, last.Stack)
+}
@@ -0,0 +1,90 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons a script fails, named after Bitcoin Core's script errors. The
+// interpreter wraps them, often in a *ScriptError, so match them with
+// errors.Is
+var (
+	ErrEvalFalse             = errors.New("script evaluated to false")
+	ErrOpReturn              = errors.New("OP_RETURN was encountered")
+	ErrScriptSize            = errors.New("script is too big")
+	ErrPushSize              = errors.New("push value size limit exceeded")
+	ErrOpCount               = errors.New("operation limit exceeded")
+	ErrStackSize             = errors.New("stack size limit exceeded")
+	ErrSigCount              = errors.New("signature count negative or greater than pubkey count")
+	ErrPubKeyCount           = errors.New("pubkey count negative or limit exceeded")
+	ErrVerify                = errors.New("OP_VERIFY failed")
+	ErrEqualVerify           = errors.New("OP_EQUALVERIFY failed")
+	ErrNumEqualVerify        = errors.New("OP_NUMEQUALVERIFY failed")
+	ErrCheckSigVerify        = errors.New("OP_CHECKSIGVERIFY failed")
+	ErrCheckMultisigVerify   = errors.New("OP_CHECKMULTISIGVERIFY failed")
+	ErrBadOpcode             = errors.New("opcode missing or not understood")
+	ErrDisabledOpcode        = errors.New("attempted to use a disabled opcode")
+	ErrStackUnderflow        = errors.New("operation not valid with the current stack size")
+	ErrAltStackUnderflow     = errors.New("operation not valid with the current altstack size")
+	ErrUnbalancedConditional = errors.New("invalid OP_IF construction")
+	ErrNumOverflow           = errors.New("script number overflow")
+	ErrNegativeLockTime      = errors.New("negative locktime")
+	ErrUnsatisfiedLockTime   = errors.New("locktime requirement not satisfied")
+
+	ErrSigHashType      = errors.New("signature hash type missing or not understood")
+	ErrSigDer           = errors.New("non-canonical DER signature")
+	ErrMinimalData      = errors.New("data push larger than necessary")
+	ErrSigPushOnly      = errors.New("only push operators allowed in signatures")
+	ErrSigHighS         = errors.New("non-canonical signature: S value is unnecessarily high")
+	ErrSigNullDummy     = errors.New("dummy CHECKMULTISIG argument must be zero")
+	ErrPubKeyType       = errors.New("public key is neither compressed or uncompressed")
+	ErrCleanStack       = errors.New("stack size must be exactly one after execution")
+	ErrMinimalIf        = errors.New("OP_IF/NOTIF argument must be minimal")
+	ErrSigNullFail      = errors.New("signature must be zero for failed CHECK(MULTI)SIG operation")
+	ErrSigFindAndDelete = errors.New("signature is found in scriptCode")
+	ErrOpCodeSeparator  = errors.New("using OP_CODESEPARATOR in non-witness script")
+	ErrSigCheck         = errors.New("no signature hash for input")
+
+	ErrDiscourageUpgradableNops           = errors.New("NOPx reserved for soft-fork upgrades")
+	ErrDiscourageUpgradableWitnessProgram = errors.New("witness version reserved for soft-fork upgrades")
+	ErrDiscourageUpgradableTaprootVersion = errors.New("taproot version reserved for soft-fork upgrades")
+	ErrDiscourageOpSuccess                = errors.New("OP_SUCCESSx reserved for soft-fork upgrades")
+	ErrDiscourageUpgradablePubKeyType     = errors.New("public key version reserved for soft-fork upgrades")
+
+	ErrWitnessProgramWrongLength  = errors.New("witness program has incorrect length")
+	ErrWitnessProgramWitnessEmpty = errors.New("witness program was passed an empty witness")
+	ErrWitnessProgramMismatch     = errors.New("witness program hash mismatch")
+	ErrWitnessMalleated           = errors.New("witness requires empty scriptSig")
+	ErrWitnessMalleatedP2SH       = errors.New("witness requires only-redeemscript scriptSig")
+	ErrWitnessUnexpected          = errors.New("witness provided for non-witness script")
+	ErrWitnessPubKeyType          = errors.New("using non-compressed keys in segwit")
+
+	ErrSchnorrSigSize            = errors.New("invalid schnorr signature size")
+	ErrSchnorrSigHashType        = errors.New("invalid schnorr signature hash type")
+	ErrSchnorrSig                = errors.New("invalid schnorr signature")
+	ErrTaprootWrongControlSize   = errors.New("invalid taproot control block size")
+	ErrTapscriptValidationWeight = errors.New("too much signature validation relative to witness weight")
+	ErrTapscriptCheckMultisig    = errors.New("OP_CHECKMULTISIG(VERIFY) is not available in tapscript")
+	ErrTapscriptEmptyPubKey      = errors.New("empty public key in tapscript")
+)
+
+// ScriptError is an error raised by the opcode at byte offset Pos of the
+// script that was running. Err is one of the Err values above, possibly
+// wrapped with more details
+type ScriptError struct {
+	Op  byte
+	Pos int
+	Err error
+}
+
+func (e *ScriptError) Error() string {
+	name, ok := opcodesNames[e.Op]
+	if !ok {
+		name = fmt.Sprintf("0x%02x", e.Op)
+	}
+	return fmt.Sprintf("%s at position %d: %v", name, e.Pos, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,144 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VerifyFlags selects which optional rules VerifyScript enforces. The bit
+// positions match Bitcoin Core's SCRIPT_VERIFY_* flags
+type VerifyFlags uint32
+
+const (
+	SCRIPT_VERIFY_NONE VerifyFlags = 0
+
+	// run the redeem script of P2SH outputs (BIP 16)
+	SCRIPT_VERIFY_P2SH VerifyFlags = 1 << 0
+
+	// signatures must be strict DER with a defined hash type and public keys
+	// must be compressed or uncompressed SEC
+	SCRIPT_VERIFY_STRICTENC VerifyFlags = 1 << 1
+
+	// signatures must be strict DER as described in BIP 66
+	SCRIPT_VERIFY_DERSIG VerifyFlags = 1 << 2
+
+	// signatures must be strict DER and have s <= N/2 (BIP 62/146)
+	SCRIPT_VERIFY_LOW_S VerifyFlags = 1 << 3
+
+	// the extra element OP_CHECKMULTISIG pops must be empty (BIP 147)
+	SCRIPT_VERIFY_NULLDUMMY VerifyFlags = 1 << 4
+
+	// the scriptSig can only push data
+	SCRIPT_VERIFY_SIGPUSHONLY VerifyFlags = 1 << 5
+
+	// pushes and numeric operands must use their shortest encoding
+	SCRIPT_VERIFY_MINIMALDATA VerifyFlags = 1 << 6
+
+	// OP_NOP1 and OP_NOP4-OP_NOP10 fail, so they can be given a meaning
+	SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_NOPS VerifyFlags = 1 << 7
+
+	// the stack must hold a single element once the scripts ran. Needs P2SH
+	// and WITNESS
+	SCRIPT_VERIFY_CLEANSTACK VerifyFlags = 1 << 8
+
+	// OP_CHECKLOCKTIMEVERIFY checks the lock time instead of being OP_NOP2
+	// (BIP 65)
+	SCRIPT_VERIFY_CHECKLOCKTIMEVERIFY VerifyFlags = 1 << 9
+
+	// OP_CHECKSEQUENCEVERIFY checks the relative lock time instead of being
+	// OP_NOP3 (BIP 112)
+	SCRIPT_VERIFY_CHECKSEQUENCEVERIFY VerifyFlags = 1 << 10
+
+	// run witness programs (BIP 141). Needs P2SH
+	SCRIPT_VERIFY_WITNESS VerifyFlags = 1 << 11
+
+	// witness versions and program sizes without a meaning fail
+	SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM VerifyFlags = 1 << 12
+
+	// the argument of OP_IF and OP_NOTIF in segwit v0 scripts must be empty
+	// or exactly 0x01
+	SCRIPT_VERIFY_MINIMALIF VerifyFlags = 1 << 13
+
+	// signatures must be empty if a signature check fails
+	SCRIPT_VERIFY_NULLFAIL VerifyFlags = 1 << 14
+
+	// public keys in segwit v0 scripts must be compressed
+	SCRIPT_VERIFY_WITNESS_PUBKEYTYPE VerifyFlags = 1 << 15
+
+	// OP_CODESEPARATOR and signatures found in the script code fail in
+	// legacy scripts
+	SCRIPT_VERIFY_CONST_SCRIPTCODE VerifyFlags = 1 << 16
+
+	// run segwit v1 programs (BIP 341/342)
+	SCRIPT_VERIFY_TAPROOT VerifyFlags = 1 << 17
+
+	// taproot leaf versions without a meaning fail
+	SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_TAPROOT_VERSION VerifyFlags = 1 << 18
+
+	// tapscripts with an OP_SUCCESSx fail
+	SCRIPT_VERIFY_DISCOURAGE_OP_SUCCESS VerifyFlags = 1 << 19
+
+	// tapscript public keys that are not 32 bytes fail
+	SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_PUBKEYTYPE VerifyFlags = 1 << 20
+)
+
+const (
+	// MANDATORY_SCRIPT_VERIFY_FLAGS are the consensus rules every block
+	// is checked with
+	MANDATORY_SCRIPT_VERIFY_FLAGS = SCRIPT_VERIFY_P2SH | SCRIPT_VERIFY_DERSIG | SCRIPT_VERIFY_NULLDUMMY |
+		SCRIPT_VERIFY_CHECKLOCKTIMEVERIFY | SCRIPT_VERIFY_CHECKSEQUENCEVERIFY | SCRIPT_VERIFY_WITNESS |
+		SCRIPT_VERIFY_TAPROOT
+
+	// STANDARD_SCRIPT_VERIFY_FLAGS add the policy rules transactions must
+	// follow to be relayed
+	STANDARD_SCRIPT_VERIFY_FLAGS = MANDATORY_SCRIPT_VERIFY_FLAGS | SCRIPT_VERIFY_STRICTENC |
+		SCRIPT_VERIFY_LOW_S | SCRIPT_VERIFY_SIGPUSHONLY | SCRIPT_VERIFY_MINIMALDATA |
+		SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_NOPS | SCRIPT_VERIFY_CLEANSTACK |
+		SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM | SCRIPT_VERIFY_MINIMALIF |
+		SCRIPT_VERIFY_NULLFAIL | SCRIPT_VERIFY_WITNESS_PUBKEYTYPE | SCRIPT_VERIFY_CONST_SCRIPTCODE |
+		SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_TAPROOT_VERSION | SCRIPT_VERIFY_DISCOURAGE_OP_SUCCESS |
+		SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_PUBKEYTYPE
+)
+
+var verifyFlagNames = map[string]VerifyFlags{
+	"NONE":                                  SCRIPT_VERIFY_NONE,
+	"P2SH":                                  SCRIPT_VERIFY_P2SH,
+	"STRICTENC":                             SCRIPT_VERIFY_STRICTENC,
+	"DERSIG":                                SCRIPT_VERIFY_DERSIG,
+	"LOW_S":                                 SCRIPT_VERIFY_LOW_S,
+	"NULLDUMMY":                             SCRIPT_VERIFY_NULLDUMMY,
+	"SIGPUSHONLY":                           SCRIPT_VERIFY_SIGPUSHONLY,
+	"MINIMALDATA":                           SCRIPT_VERIFY_MINIMALDATA,
+	"DISCOURAGE_UPGRADABLE_NOPS":            SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_NOPS,
+	"CLEANSTACK":                            SCRIPT_VERIFY_CLEANSTACK,
+	"CHECKLOCKTIMEVERIFY":                   SCRIPT_VERIFY_CHECKLOCKTIMEVERIFY,
+	"CHECKSEQUENCEVERIFY":                   SCRIPT_VERIFY_CHECKSEQUENCEVERIFY,
+	"WITNESS":                               SCRIPT_VERIFY_WITNESS,
+	"DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM": SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM,
+	"MINIMALIF":                             SCRIPT_VERIFY_MINIMALIF,
+	"NULLFAIL":                              SCRIPT_VERIFY_NULLFAIL,
+	"WITNESS_PUBKEYTYPE":                    SCRIPT_VERIFY_WITNESS_PUBKEYTYPE,
+	"CONST_SCRIPTCODE":                      SCRIPT_VERIFY_CONST_SCRIPTCODE,
+	"TAPROOT":                               SCRIPT_VERIFY_TAPROOT,
+	"DISCOURAGE_UPGRADABLE_TAPROOT_VERSION": SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_TAPROOT_VERSION,
+	"DISCOURAGE_OP_SUCCESS":                 SCRIPT_VERIFY_DISCOURAGE_OP_SUCCESS,
+	"DISCOURAGE_UPGRADABLE_PUBKEYTYPE":      SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_PUBKEYTYPE,
+}
+
+// ParseVerifyFlags parses a comma separated list of flag names without the
+// SCRIPT_VERIFY_ prefix, like "P2SH,WITNESS" or "NONE", the way Bitcoin
+// Core's test vectors list them
+func ParseVerifyFlags(s string) (VerifyFlags, error) {
+	flags := SCRIPT_VERIFY_NONE
+	if s == "" {
+		return flags, nil
+	}
+	for _, name := range strings.Split(s, ",") {
+		flag, ok := verifyFlagNames[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown verify flag %q", name)
+		}
+		flags |= flag
+	}
+	return flags, nil
+}
@@ -0,0 +1,682 @@
+package script
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+// SigVersion is the kind of script being run, which picks the signature
+// hash and some of the rules
+type SigVersion int
+
+const (
+	SIGVERSION_BASE SigVersion = iota
+	SIGVERSION_WITNESS_V0
+	SIGVERSION_TAPROOT
+	SIGVERSION_TAPSCRIPT
+)
+
+// Consensus limits. Tapscript drops the script size and opcode count limits
+// and counts signature checks against the witness size instead
+const (
+	MAX_SCRIPT_SIZE          = 10000
+	MAX_SCRIPT_ELEMENT_SIZE  = 520
+	MAX_OPS_PER_SCRIPT       = 201  // opcodes other than pushes and OP_1-OP_16
+	MAX_STACK_SIZE           = 1000 // stack and altstack items together
+	MAX_PUBKEYS_PER_MULTISIG = 20
+)
+
+// instruction is an opcode of a raw script with the data it pushes. end is
+// the offset right after it
+type instruction struct {
+	op   byte
+	data []byte
+	end  int
+}
+
+// parseInstruction reads the instruction at offset i of raw. It fails if a
+// push runs past the end of the script
+func parseInstruction(raw []byte, i int) (instruction, error) {
+	op := raw[i]
+	i++
+	var n int
+	switch {
+	case op >= 0x01 && op <= 0x4b:
+		n = int(op)
+	case op == 0x4c:
+		if i+1 > len(raw) {
+			return instruction{}, errors.New("truncated OP_PUSHDATA1")
+		}
+		n = int(raw[i])
+		i++
+	case op == 0x4d:
+		if i+2 > len(raw) {
+			return instruction{}, errors.New("truncated OP_PUSHDATA2")
+		}
+		n = int(binary.LittleEndian.Uint16(raw[i:]))
+		i += 2
+	case op == 0x4e:
+		if i+4 > len(raw) {
+			return instruction{}, errors.New("truncated OP_PUSHDATA4")
+		}
+		n = int(binary.LittleEndian.Uint32(raw[i:]))
+		i += 4
+	}
+	if n < 0 || n > len(raw)-i {
+		return instruction{}, errors.New("push past end of script")
+	}
+	return instruction{op: op, data: raw[i : i+n], end: i + n}, nil
+}
+
+// parseInstructions splits raw into instructions. If it can't be parsed to
+// the end it returns the instructions before the bad one and an error
+func parseInstructions(raw []byte) ([]instruction, error) {
+	var instructions []instruction
+	for i := 0; i < len(raw); {
+		ins, err := parseInstruction(raw, i)
+		if err != nil {
+			return instructions, err
+		}
+		instructions = append(instructions, ins)
+		i = ins.end
+	}
+	return instructions, nil
+}
+
+// isMinimalPush reports whether a push uses the shortest opcode for its data
+func (ins instruction) isMinimalPush() bool {
+	n := len(ins.data)
+	switch {
+	case n == 0:
+		return ins.op == 0x00
+	case n == 1 && ins.data[0] >= 1 && ins.data[0] <= 16:
+		// OP_1 to OP_16
+		return false
+	case n == 1 && ins.data[0] == 0x81:
+		// OP_1NEGATE
+		return false
+	case n <= 75:
+		return int(ins.op) == n
+	case n <= 0xff:
+		return ins.op == 0x4c
+	case n <= 0xffff:
+		return ins.op == 0x4d
+	}
+	return true
+}
+
+// isMinimalNum reports whether a number has no extra zero bytes
+func isMinimalNum(b []byte) bool {
+	if len(b) == 0 || b[len(b)-1]&0x7f != 0 {
+		return true
+	}
+	// a zero last byte is only needed if the one before sets the sign bit
+	return len(b) > 1 && b[len(b)-2]&0x80 != 0
+}
+
+// pushData serializes a push of data the way Bitcoin Core does when it
+// builds a script from a byte vector
+func pushData(data []byte) []byte {
+	n := len(data)
+	var result []byte
+	switch {
+	case n < 0x4c:
+		result = []byte{byte(n)}
+	case n <= 0xff:
+		result = []byte{0x4c, byte(n)}
+	case n <= 0xffff:
+		result = []byte{0x4d, 0, 0}
+		binary.LittleEndian.PutUint16(result[1:], uint16(n))
+	default:
+		result = []byte{0x4e, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(result[1:], uint32(n))
+	}
+	return append(result, data...)
+}
+
+// findAndDelete removes every pattern found at an instruction boundary of
+// script and returns how many there were
+func findAndDelete(script, pattern []byte) ([]byte, int) {
+	if len(pattern) == 0 {
+		return script, 0
+	}
+	var result []byte
+	found := 0
+	pc, pc2 := 0, 0
+	for {
+		result = append(result, script[pc2:pc]...)
+		for len(script)-pc >= len(pattern) && bytes.Equal(script[pc:pc+len(pattern)], pattern) {
+			pc += len(pattern)
+			found++
+		}
+		pc2 = pc
+		if pc >= len(script) {
+			break
+		}
+		ins, err := parseInstruction(script, pc)
+		if err != nil {
+			break
+		}
+		pc = ins.end
+	}
+	if found == 0 {
+		return script, 0
+	}
+	return append(result, script[pc2:]...), found
+}
+
+// RemoveCodeSeparators returns code without the OP_CODESEPARATORs found at
+// its instruction boundaries, which legacy signatures never commit to
+func RemoveCodeSeparators(code []byte) []byte {
+	code, _ = findAndDelete(code, []byte{0xab})
+	return code
+}
+
+// isPushOnly reports whether raw only has push opcodes, OP_1NEGATE,
+// OP_RESERVED and OP_1 to OP_16 included
+func isPushOnly(raw []byte) bool {
+	instructions, err := parseInstructions(raw)
+	if err != nil {
+		return false
+	}
+	for _, ins := range instructions {
+		if ins.op > 0x60 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPushOnly reports whether the script only pushes data
+func (sc Script) IsPushOnly() bool {
+	return isPushOnly(sc.RawSerialize())
+}
+
+func isP2SH(raw []byte) bool {
+	return len(raw) == 23 && raw[0] == 0xa9 && raw[1] == 0x14 && raw[22] == 0x87
+}
+
+// witnessProgram returns the version and program of a segwit scriptPubKey:
+// a version opcode followed by a single 2 to 40 byte push
+func witnessProgram(raw []byte) (int, []byte, bool) {
+	if len(raw) < 4 || len(raw) > 42 {
+		return 0, nil, false
+	}
+	if raw[0] != 0x00 && (raw[0] < 0x51 || raw[0] > 0x60) {
+		return 0, nil, false
+	}
+	if int(raw[1])+2 != len(raw) {
+		return 0, nil, false
+	}
+	version := 0
+	if raw[0] != 0x00 {
+		version = int(raw[0]) - 0x50
+	}
+	return version, raw[2:], true
+}
+
+// WitnessProgram returns the version and program if the script is a segwit
+// scriptPubKey
+func (sc Script) WitnessProgram() (int, []byte, bool) {
+	return witnessProgram(sc.RawSerialize())
+}
+
+// execContext is what the opcodes need besides the stacks
+type execContext struct {
+	input *InputContext
+	flags VerifyFlags
+	check sigChecker
+	batch *ecc.BatchVerifier
+
+	// the script being run, what kind it is and where the part signatures
+	// commit to starts
+	script        []byte
+	sigVersion    SigVersion
+	codeHashStart int
+
+	// opCount is how many opcodes count against MAX_OPS_PER_SCRIPT so far.
+	// OP_CHECKMULTISIG adds its keys too
+	opCount int
+
+	// tapscript is only set while running a BIP 342 leaf script
+	tapscript *tapscriptContext
+
+	// hook is told about every step if it's set
+	hook StepHook
+}
+
+// newExecContext checks signatures right away, or queues them on batch if it
+// isn't nil
+func newExecContext(input *InputContext, flags VerifyFlags, batch *ecc.BatchVerifier) *execContext {
+	ctx := &execContext{input: input, flags: flags, check: verifyNow, batch: batch}
+	if batch != nil {
+		ctx.check = func(pubKey *ecc.Point, sig *ecc.Signature, z *big.Int) bool {
+			batch.Add(pubKey, *sig, z)
+			return true
+		}
+	}
+	return ctx
+}
+
+// scriptCode is the part of the script signatures commit to: what follows
+// the last OP_CODESEPARATOR run, without sigs and without any other
+// OP_CODESEPARATOR in legacy scripts
+func (ctx *execContext) scriptCode(sigs ...[]byte) ([]byte, error) {
+	code := ctx.script[ctx.codeHashStart:]
+	if ctx.sigVersion != SIGVERSION_BASE {
+		return code, nil
+	}
+	for _, sig := range sigs {
+		var found int
+		code, found = findAndDelete(code, pushData(sig))
+		if found > 0 && ctx.flags&SCRIPT_VERIFY_CONST_SCRIPTCODE != 0 {
+			return nil, ErrSigFindAndDelete
+		}
+	}
+	return RemoveCodeSeparators(code), nil
+}
+
+// numericOperands is how many items from the top of the stack an opcode
+// reads as numbers, which MINIMALDATA wants minimally encoded
+var numericOperands = map[byte]int{
+	0x79: 1, // OP_PICK
+	0x7a: 1, // OP_ROLL
+	0x8b: 1, // OP_1ADD
+	0x8c: 1, // OP_1SUB
+	0x8f: 1, // OP_NEGATE
+	0x90: 1, // OP_ABS
+	0x91: 1, // OP_NOT
+	0x92: 1, // OP_0NOTEQUAL
+	0x93: 2, // OP_ADD
+	0x94: 2, // OP_SUB
+	0x9a: 2, // OP_BOOLAND
+	0x9b: 2, // OP_BOOLOR
+	0x9c: 2, // OP_NUMEQUAL
+	0x9d: 2, // OP_NUMEQUALVERIFY
+	0x9e: 2, // OP_NUMNOTEQUAL
+	0x9f: 2, // OP_LESSTHAN
+	0xa0: 2, // OP_GREATERTHAN
+	0xa1: 2, // OP_LESSTHANOREQUAL
+	0xa2: 2, // OP_GREATERTHANOREQUAL
+	0xa3: 2, // OP_MIN
+	0xa4: 2, // OP_MAX
+	0xa5: 3, // OP_WITHIN
+}
+
+func (ctx *execContext) checkMinimalOperands(op byte, stack [][]byte) error {
+	if ctx.flags&SCRIPT_VERIFY_MINIMALDATA == 0 {
+		return nil
+	}
+	for i := 1; i <= numericOperands[op] && i <= len(stack); i++ {
+		if !isMinimalNum(stack[len(stack)-i]) {
+			return ErrMinimalData
+		}
+	}
+	return nil
+}
+
+// popNum pops a numeric operand, which must be minimally encoded under
+// MINIMALDATA
+func (ctx *execContext) popNum(stack [][]byte) (int, [][]byte, error) {
+	if ctx.flags&SCRIPT_VERIFY_MINIMALDATA != 0 && len(stack) > 0 && !isMinimalNum(stack[len(stack)-1]) {
+		return 0, stack, ErrMinimalData
+	}
+	return popNum(stack)
+}
+
+// popCondition pops the argument of OP_IF or OP_NOTIF. Tapscript, and segwit
+// v0 scripts under MINIMALIF, only take an empty element or 0x01
+func (ctx *execContext) popCondition(stack [][]byte) (bool, [][]byte, error) {
+	if len(stack) < 1 {
+		return false, stack, ErrUnbalancedConditional
+	}
+	top, stack := pop(stack)
+	minimalIf := ctx.sigVersion == SIGVERSION_TAPSCRIPT ||
+		(ctx.sigVersion == SIGVERSION_WITNESS_V0 && ctx.flags&SCRIPT_VERIFY_MINIMALIF != 0)
+	if minimalIf && (len(top) > 1 || (len(top) == 1 && top[0] != 1)) {
+		return false, stack, ErrMinimalIf
+	}
+	return castToBool(top), stack, nil
+}
+
+// executing reports whether the branch every OP_IF in vfExec is in is
+// taken, which is when opcodes run
+func executing(vfExec []bool) bool {
+	for _, taken := range vfExec {
+		if !taken {
+			return false
+		}
+	}
+	return true
+}
+
+func isUpgradableNop(op byte) bool {
+	return op == 0xb0 || (op >= 0xb3 && op <= 0xb9)
+}
+
+// run executes the raw script on stack and returns the stack it leaves. It
+// fails with a *ScriptError telling which opcode failed
+func (ctx *execContext) run(script []byte, stack [][]byte, sigVersion SigVersion) ([][]byte, error) {
+	legacyLimits := sigVersion == SIGVERSION_BASE || sigVersion == SIGVERSION_WITNESS_V0
+	if legacyLimits && len(script) > MAX_SCRIPT_SIZE {
+		return nil, fmt.Errorf("%w: %d bytes", ErrScriptSize, len(script))
+	}
+	instructions, parseErr := parseInstructions(script)
+	ctx.script = script
+	ctx.sigVersion = sigVersion
+	ctx.codeHashStart = 0
+	ctx.opCount = 0
+	altStack := [][]byte{}
+	// whether the OP_IFs the script is in take the branch being read,
+	// innermost last. Only OP_IF, OP_NOTIF, OP_ELSE and OP_ENDIF run in
+	// branches that are not taken, which are still parsed and checked for
+	// disabled opcodes
+	var vfExec []bool
+
+	for pc := 0; pc < len(instructions); pc++ {
+		ins := instructions[pc]
+		op := ins.op
+		pos := 0
+		if pc > 0 {
+			pos = instructions[pc-1].end
+		}
+		exec := executing(vfExec)
+		step := ctx.debugStep(ins, pos, exec, vfExec, stack, altStack)
+		fail := func(err error) ([][]byte, error) {
+			err = &ScriptError{Op: op, Pos: pos, Err: err}
+			ctx.endStep(step, nil, nil, err)
+			return nil, err
+		}
+		// limits and disabled opcodes apply to branches that are not taken
+		// too
+		if len(ins.data) > MAX_SCRIPT_ELEMENT_SIZE {
+			return fail(ErrPushSize)
+		}
+		if legacyLimits && op > 0x60 {
+			ctx.opCount++
+			if ctx.opCount > MAX_OPS_PER_SCRIPT {
+				return fail(ErrOpCount)
+			}
+		}
+		if opcodesDisabled[op] {
+			return fail(ErrDisabledOpcode)
+		}
+		if op == 0xab && sigVersion == SIGVERSION_BASE && ctx.flags&SCRIPT_VERIFY_CONST_SCRIPTCODE != 0 {
+			return fail(ErrOpCodeSeparator)
+		}
+		if !exec && (op < 0x63 || op > 0x68) {
+			ctx.endStep(step, stack, altStack, nil)
+			continue
+		}
+		if op <= 0x4e {
+			if ctx.flags&SCRIPT_VERIFY_MINIMALDATA != 0 && !ins.isMinimalPush() {
+				return fail(ErrMinimalData)
+			}
+			stack = append(stack, ins.data)
+			if len(stack)+len(altStack) > MAX_STACK_SIZE {
+				return fail(ErrStackSize)
+			}
+			ctx.endStep(step, stack, altStack, nil)
+			continue
+		}
+		if err := ctx.checkMinimalOperands(op, stack); err != nil {
+			return fail(err)
+		}
+
+		var err error
+		switch {
+		case op == 0x63 || op == 0x64: // OP_IF, OP_NOTIF
+			// inside a branch that is not taken there's no condition to pop
+			cond := false
+			if exec {
+				cond, stack, err = ctx.popCondition(stack)
+				if err != nil {
+					return fail(err)
+				}
+				if op == 0x64 {
+					cond = !cond
+				}
+			}
+			vfExec = append(vfExec, cond)
+		case op == 0x67: // OP_ELSE switches branch, as many times as it appears
+			if len(vfExec) == 0 {
+				return fail(ErrUnbalancedConditional)
+			}
+			vfExec[len(vfExec)-1] = !vfExec[len(vfExec)-1]
+		case op == 0x68: // OP_ENDIF
+			if len(vfExec) == 0 {
+				return fail(ErrUnbalancedConditional)
+			}
+			vfExec = vfExec[:len(vfExec)-1]
+		case op == 0xab: // OP_CODESEPARATOR
+			ctx.codeHashStart = ins.end
+			if ctx.tapscript != nil {
+				// tapscript signatures commit to the opcode position instead
+				ctx.tapscript.codeSepPos = uint32(pc)
+			}
+		case isUpgradableNop(op) && ctx.flags&SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_NOPS != 0:
+			err = ErrDiscourageUpgradableNops
+		case opcodesFuncs[op] != nil:
+			stack, err = opcodesFuncs[op](stack)
+		case opcodesAltStack[op] != nil:
+			stack, altStack, err = opcodesAltStack[op](stack, altStack)
+		case opcodesContext[op] != nil:
+			stack, err = opcodesContext[op](stack, ctx)
+		default:
+			err = ErrBadOpcode
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if len(stack)+len(altStack) > MAX_STACK_SIZE {
+			return fail(ErrStackSize)
+		}
+		ctx.endStep(step, stack, altStack, nil)
+	}
+
+	if parseErr != nil {
+		pos := 0
+		if len(instructions) > 0 {
+			pos = instructions[len(instructions)-1].end
+		}
+		return nil, &ScriptError{Op: script[pos], Pos: pos, Err: fmt.Errorf("%w: %v", ErrBadOpcode, parseErr)}
+	}
+	if len(vfExec) > 0 {
+		return nil, &ScriptError{Op: 0x63, Pos: len(script), Err: ErrUnbalancedConditional}
+	}
+	return stack, nil
+}
+
+// VerifyScript verifies an input given its scriptSig and witness and the
+// scriptPubKey it spends, the way Bitcoin Core does. input describes the
+// input, which the signature and timelock opcodes check against, and flags
+// selects the rules to enforce: MANDATORY_SCRIPT_VERIFY_FLAGS for consensus,
+// STANDARD_SCRIPT_VERIFY_FLAGS for relay policy
+func VerifyScript(scriptSig, scriptPubKey *Script, witness [][]byte, input *InputContext, flags VerifyFlags) (bool, error) {
+	err := newExecContext(input, flags, nil).verify(scriptSig, scriptPubKey, witness)
+	return err == nil, err
+}
+
+// VerifyScriptBatch is VerifyScript queueing the signatures on batch instead
+// of checking them, taking them all as valid. A success only holds if
+// batch.Verify() succeeds afterwards, and a failure may come from a script
+// that needs a signature to fail, so in both cases run VerifyScript to get
+// the exact answer
+func VerifyScriptBatch(scriptSig, scriptPubKey *Script, witness [][]byte, input *InputContext, flags VerifyFlags, batch *ecc.BatchVerifier) (bool, error) {
+	err := newExecContext(input, flags, batch).verify(scriptSig, scriptPubKey, witness)
+	return err == nil, err
+}
+
+func (ctx *execContext) verify(scriptSigScript, scriptPubKeyScript *Script, witness [][]byte) error {
+	var scriptSig, scriptPubKey []byte
+	if scriptSigScript != nil {
+		scriptSig = scriptSigScript.RawSerialize()
+	}
+	if scriptPubKeyScript != nil {
+		scriptPubKey = scriptPubKeyScript.RawSerialize()
+	}
+	flags := ctx.flags
+
+	if flags&SCRIPT_VERIFY_SIGPUSHONLY != 0 && !isPushOnly(scriptSig) {
+		return ErrSigPushOnly
+	}
+
+	stack, err := ctx.run(scriptSig, [][]byte{}, SIGVERSION_BASE)
+	if err != nil {
+		return err
+	}
+	// P2SH runs the redeem script on the stack the scriptSig left
+	stackCopy := make([][]byte, len(stack))
+	copy(stackCopy, stack)
+
+	stack, err = ctx.run(scriptPubKey, stack, SIGVERSION_BASE)
+	if err != nil {
+		return err
+	}
+	if len(stack) == 0 || !castToBool(stack[len(stack)-1]) {
+		return ErrEvalFalse
+	}
+
+	hadWitness := false
+	if flags&SCRIPT_VERIFY_WITNESS != 0 {
+		if version, program, ok := witnessProgram(scriptPubKey); ok {
+			hadWitness = true
+			if len(scriptSig) != 0 {
+				return ErrWitnessMalleated
+			}
+			if err := ctx.verifyWitnessProgram(witness, version, program, false); err != nil {
+				return err
+			}
+			// the witness leaves a single element
+			stack = stack[:1]
+		}
+	}
+
+	if flags&SCRIPT_VERIFY_P2SH != 0 && isP2SH(scriptPubKey) {
+		if !isPushOnly(scriptSig) {
+			return ErrSigPushOnly
+		}
+		// the scriptPubKey only passes if the scriptSig pushed something
+		var redeemScript []byte
+		redeemScript, stack = pop(stackCopy)
+		stack, err = ctx.run(redeemScript, stack, SIGVERSION_BASE)
+		if err != nil {
+			return err
+		}
+		if len(stack) == 0 || !castToBool(stack[len(stack)-1]) {
+			return ErrEvalFalse
+		}
+
+		if flags&SCRIPT_VERIFY_WITNESS != 0 {
+			if version, program, ok := witnessProgram(redeemScript); ok {
+				hadWitness = true
+				if !bytes.Equal(scriptSig, pushData(redeemScript)) {
+					return ErrWitnessMalleatedP2SH
+				}
+				if err := ctx.verifyWitnessProgram(witness, version, program, true); err != nil {
+					return err
+				}
+				stack = stack[:1]
+			}
+		}
+	}
+
+	if flags&SCRIPT_VERIFY_CLEANSTACK != 0 && len(stack) != 1 {
+		return fmt.Errorf("%w: %d elements left", ErrCleanStack, len(stack))
+	}
+	if flags&SCRIPT_VERIFY_WITNESS != 0 && !hadWitness && len(witness) > 0 {
+		return ErrWitnessUnexpected
+	}
+	return nil
+}
+
+// verifyWitnessProgram runs the witness of a segwit output. Versions and
+// program sizes without a meaning pass, unless flags discourage them
+func (ctx *execContext) verifyWitnessProgram(witness [][]byte, version int, program []byte, isP2SH bool) error {
+	switch {
+	case version == 0 && len(program) == 32:
+		// p2wsh: the last witness item is the witness script
+		if len(witness) == 0 {
+			return ErrWitnessProgramWitnessEmpty
+		}
+		witnessScript := witness[len(witness)-1]
+		scriptHash := sha256.Sum256(witnessScript)
+		if !bytes.Equal(scriptHash[:], program) {
+			return ErrWitnessProgramMismatch
+		}
+		return ctx.executeWitnessScript(witnessScript, witness[:len(witness)-1], SIGVERSION_WITNESS_V0)
+	case version == 0 && len(program) == 20:
+		if len(witness) != 2 {
+			return fmt.Errorf("%w: p2wpkh witness has %d items instead of 2", ErrWitnessProgramMismatch, len(witness))
+		}
+		return ctx.executeWitnessScript(P2PKHScript(program).RawSerialize(), witness, SIGVERSION_WITNESS_V0)
+	case version == 0:
+		return fmt.Errorf("%w: %d bytes", ErrWitnessProgramWrongLength, len(program))
+	case version == 1 && len(program) == 32 && !isP2SH:
+		if ctx.flags&SCRIPT_VERIFY_TAPROOT == 0 {
+			return nil
+		}
+		return ctx.verifyTaproot(program, witness)
+	case ctx.flags&SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM != 0:
+		return fmt.Errorf("%w: version %d", ErrDiscourageUpgradableWitnessProgram, version)
+	}
+	return nil
+}
+
+// executeWitnessScript runs a segwit script on a copy of the witness stack,
+// which must end up with a single true element
+func (ctx *execContext) executeWitnessScript(script []byte, witness [][]byte, sigVersion SigVersion) error {
+	if sigVersion == SIGVERSION_TAPSCRIPT {
+		success, err := hasOpSuccess(script)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrBadOpcode, err)
+		}
+		if success {
+			if ctx.flags&SCRIPT_VERIFY_DISCOURAGE_OP_SUCCESS != 0 {
+				return ErrDiscourageOpSuccess
+			}
+			return nil
+		}
+	}
+
+	if sigVersion == SIGVERSION_TAPSCRIPT && len(witness) > MAX_STACK_SIZE {
+		return fmt.Errorf("%w: %d witness items", ErrStackSize, len(witness))
+	}
+	for _, item := range witness {
+		if len(item) > MAX_SCRIPT_ELEMENT_SIZE {
+			return fmt.Errorf("%w: %d byte witness item", ErrPushSize, len(item))
+		}
+	}
+
+	stack := make([][]byte, len(witness))
+	copy(stack, witness)
+	stack, err := ctx.run(script, stack, sigVersion)
+	if err != nil {
+		return err
+	}
+	if len(stack) != 1 {
+		return fmt.Errorf("%w: witness script left %d elements", ErrCleanStack, len(stack))
+	}
+	if !castToBool(stack[0]) {
+		return ErrEvalFalse
+	}
+	return nil
+}
+
+// serializedWitnessSize is the size of witness serialized in a tx
+func serializedWitnessSize(witness [][]byte) int {
+	count, _ := encoding.EncodeVarint(len(witness))
+	size := len(count)
+	for _, item := range witness {
+		itemLen, _ := encoding.EncodeVarint(len(item))
+		size += len(itemLen) + len(item)
+	}
+	return size
+}
@@ -0,0 +1,308 @@
+package script
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func fromHex(s string) []byte {
+	b, _ := hex.DecodeString(s)
+	return b
+}
+
+func TestFindAndDelete(t *testing.T) {
+	testCases := []struct {
+		script  string
+		pattern string
+		want    string
+		found   int
+	}{
+		{"0302ff03", "0302ff03", "", 1},
+		{"0302ff030302ff03", "0302ff03", "", 2},
+		{"0302ff03", "02", "0302ff03", 0},
+		{"0302ff03", "ff", "0302ff03", 0},
+		{"02feed5169", "feed51", "02feed5169", 0},
+		{"02feed5169", "02feed51", "69", 1},
+		{"516902feed5169", "feed5169", "516902feed5169", 0},
+		{"0003feed", "03feed", "00", 1},
+	}
+
+	for _, test := range testCases {
+		got, found := findAndDelete(fromHex(test.script), fromHex(test.pattern))
+		if hex.EncodeToString(got) != test.want || found != test.found {
+			t.Errorf("%s - %s: expected '%v' (%d) but got '%x' (%d) instead", test.script, test.pattern, test.want, test.found, got, found)
+		}
+	}
+}
+
+func TestRemoveCodeSeparators(t *testing.T) {
+	testCases := []struct {
+		script string
+		want   string
+	}{
+		{"ab", ""},
+		{"51ab", "51"},
+		{"ab51abab52ab", "5152"},
+		// pushed 0xab bytes are data, not opcodes
+		{"02abab", "02abab"},
+		{"ab02abab", "02abab"},
+		{"", ""},
+	}
+
+	for _, test := range testCases {
+		got := RemoveCodeSeparators(fromHex(test.script))
+		if hex.EncodeToString(got) != test.want {
+			t.Errorf("%s: expected '%v' but got '%x' instead", test.script, test.want, got)
+		}
+	}
+}
+
+func TestVerifyScriptFlags(t *testing.T) {
+	z := big.NewInt(0xc0de)
+	key := ecc.NewPrivateKey(big.NewInt(1001))
+	// a well formed signature over something else
+	badSig := append(key.Sign(big.NewInt(0xbad)).Der(), 0x01)
+
+	raw := func(s string) *Script {
+		return &Script{raw: fromHex(s)}
+	}
+	pushes := func(items ...[]byte) *Script {
+		var b []byte
+		for _, item := range items {
+			b = append(b, pushData(item)...)
+		}
+		return &Script{raw: b}
+	}
+	p2sh := func(redeemScript []byte) *Script {
+		return raw("a914" + hex.EncodeToString(encoding.Hash160(redeemScript)) + "87")
+	}
+	p2wsh := func(witnessScript []byte) *Script {
+		hash := sha256.Sum256(witnessScript)
+		return &Script{raw: append([]byte{0x00, 0x20}, hash[:]...)}
+	}
+
+	// single leaf taproot outputs
+	internalKey := ecc.NewPrivateKey(big.NewInt(1234)).PublicKey()
+	tapLeaf := func(leafVersion byte, leaf []byte) (*Script, []byte) {
+		outputKey, err := ecc.TaprootOutputKey(internalKey, TapLeafHash(leafVersion, leaf))
+		if err != nil {
+			t.Fatalf("error tweaking key: %v", err)
+		}
+		controlBlock := append([]byte{leafVersion | byte(outputKey.Y().Num().Bit(0))}, internalKey.XOnly()...)
+		return &Script{raw: append([]byte{0x51, 0x20}, outputKey.XOnly()...)}, controlBlock
+	}
+	opSuccess, opSuccessCB := tapLeaf(TAPROOT_LEAF_TAPSCRIPT, []byte{0x50})
+	unknownLeaf, unknownLeafCB := tapLeaf(0xc2, []byte{0x51})
+	// <33 byte key> OP_CHECKSIG
+	unknownKeyLeaf := append(pushData(key.PublicKey().Sec(true)), 0xac)
+	unknownKey, unknownKeyCB := tapLeaf(TAPROOT_LEAF_TAPSCRIPT, unknownKeyLeaf)
+
+	// <key> OP_CHECKSIG OP_NOT
+	checkSigNot := func(pubKey []byte) []byte {
+		return append(pushData(pubKey), 0xac, 0x91)
+	}
+	badPubKey := append([]byte{0x05}, make([]byte, 32)...)
+	witnessBase := SCRIPT_VERIFY_P2SH | SCRIPT_VERIFY_WITNESS
+
+	// each case passes with flags and fails once flag is added
+	testCases := []struct {
+		name         string
+		scriptSig    *Script
+		scriptPubKey *Script
+		witness      [][]byte
+		flags        VerifyFlags
+		flag         VerifyFlags
+	}{
+		{"P2SH", pushes([]byte{0x00}), p2sh([]byte{0x00}), nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_P2SH},
+		{"STRICTENC", raw("00"), &Script{raw: checkSigNot(badPubKey)}, nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_STRICTENC},
+		{"STRICTENC hash type", pushes(append(badSig[:len(badSig)-1], 0x05)), &Script{raw: checkSigNot(key.PublicKey().Sec(true))}, nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_STRICTENC},
+		{"NULLDUMMY", raw("0101"), raw("0000ae"), nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_NULLDUMMY},
+		{"SIGPUSHONLY", raw("5161"), raw("51"), nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_SIGPUSHONLY},
+		{"MINIMALDATA push", raw("0101"), raw("5187"), nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_MINIMALDATA},
+		{"MINIMALDATA number", raw("020100"), raw("8b"), nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_MINIMALDATA},
+		{"DISCOURAGE_UPGRADABLE_NOPS", raw(""), raw("51b0"), nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_NOPS},
+		{"CLEANSTACK", raw("51"), raw("51"), nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_CLEANSTACK},
+		{"NULLFAIL", pushes(badSig), &Script{raw: checkSigNot(key.PublicKey().Sec(true))}, nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_NULLFAIL},
+		{"CONST_SCRIPTCODE", raw(""), raw("ab51"), nil, SCRIPT_VERIFY_NONE, SCRIPT_VERIFY_CONST_SCRIPTCODE},
+		{"WITNESS", raw(""), raw("51"), [][]byte{{0x01}}, SCRIPT_VERIFY_P2SH, SCRIPT_VERIFY_WITNESS},
+		// OP_IF 1 OP_ELSE 0 OP_ENDIF
+		{"MINIMALIF", raw(""), p2wsh(fromHex("6351670068")), [][]byte{{0x02}, fromHex("6351670068")}, witnessBase, SCRIPT_VERIFY_MINIMALIF},
+		{"WITNESS_PUBKEYTYPE", raw(""), p2wsh(checkSigNot(key.PublicKey().Sec(false))), [][]byte{{}, checkSigNot(key.PublicKey().Sec(false))}, witnessBase, SCRIPT_VERIFY_WITNESS_PUBKEYTYPE},
+		{"DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM", raw(""), raw("52020100"), nil, witnessBase, SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM},
+		{"TAPROOT", raw(""), opSuccess, [][]byte{make([]byte, 64)}, witnessBase, SCRIPT_VERIFY_TAPROOT},
+		{"DISCOURAGE_OP_SUCCESS", raw(""), opSuccess, [][]byte{{0x50}, opSuccessCB}, witnessBase | SCRIPT_VERIFY_TAPROOT, SCRIPT_VERIFY_DISCOURAGE_OP_SUCCESS},
+		{"DISCOURAGE_UPGRADABLE_TAPROOT_VERSION", raw(""), unknownLeaf, [][]byte{{0x51}, unknownLeafCB}, witnessBase | SCRIPT_VERIFY_TAPROOT, SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_TAPROOT_VERSION},
+		{"DISCOURAGE_UPGRADABLE_PUBKEYTYPE", raw(""), unknownKey, [][]byte{{0x01}, unknownKeyLeaf, unknownKeyCB}, witnessBase | SCRIPT_VERIFY_TAPROOT, SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_PUBKEYTYPE},
+	}
+
+	for _, test := range testCases {
+		valid, err := VerifyScript(test.scriptSig, test.scriptPubKey, test.witness, fixedSigHash(z), test.flags)
+		if !valid {
+			t.Errorf("%s: expected '%v' but got '%v' instead (%v)", test.name, true, valid, err)
+		}
+		valid, _ = VerifyScript(test.scriptSig, test.scriptPubKey, test.witness, fixedSigHash(z), test.flags|test.flag)
+		if valid {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, false, valid)
+		}
+	}
+}
+
+func TestVerifyScriptP2SHMultisig(t *testing.T) {
+	z := big.NewInt(0xc0de)
+	keys := []*ecc.PrivateKey{ecc.NewPrivateKey(big.NewInt(1001)), ecc.NewPrivateKey(big.NewInt(1002))}
+	// OP_1 <k0> <k1> OP_2 OP_CHECKMULTISIG
+	redeemScript := []byte{0x51}
+	for _, key := range keys {
+		redeemScript = append(redeemScript, pushData(key.PublicKey().Sec(true))...)
+	}
+	redeemScript = append(redeemScript, 0x52, 0xae)
+	scriptPubKey := &Script{raw: append(append([]byte{0xa9, 0x14}, encoding.Hash160(redeemScript)...), 0x87)}
+
+	sig := append(keys[1].Sign(z).Der(), 0x01)
+	scriptSig := &Script{raw: bytes.Join([][]byte{{0x00}, pushData(sig), pushData(redeemScript)}, nil)}
+
+	valid, err := VerifyScript(scriptSig, scriptPubKey, nil, fixedSigHash(z), STANDARD_SCRIPT_VERIFY_FLAGS)
+	if !valid {
+		t.Errorf("expected '%v' but got '%v' instead (%v)", true, valid, err)
+	}
+
+	// a signature for another message fails the multisig, which the scriptSig
+	// can't hide since the redeem script has to leave true
+	badSig := append(keys[1].Sign(big.NewInt(1)).Der(), 0x01)
+	scriptSig = &Script{raw: bytes.Join([][]byte{{0x00}, pushData(badSig), pushData(redeemScript)}, nil)}
+	if valid, _ := VerifyScript(scriptSig, scriptPubKey, nil, fixedSigHash(z), MANDATORY_SCRIPT_VERIFY_FLAGS); valid {
+		t.Errorf("expected '%v' but got '%v' instead", false, valid)
+	}
+}
+
+func TestScriptErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script string
+		flags  VerifyFlags
+		want   error
+		pos    int
+	}{
+		{"underflow", "75", SCRIPT_VERIFY_NONE, ErrStackUnderflow, 0},
+		{"altstack underflow", "516c", SCRIPT_VERIFY_NONE, ErrAltStackUnderflow, 1},
+		{"equalverify", "515288", SCRIPT_VERIFY_NONE, ErrEqualVerify, 2},
+		{"numequalverify", "5152519d", SCRIPT_VERIFY_NONE, ErrNumEqualVerify, 3},
+		{"verify", "0069", SCRIPT_VERIFY_NONE, ErrVerify, 1},
+		{"return", "516a", SCRIPT_VERIFY_NONE, ErrOpReturn, 1},
+		{"disabled", "51517e", SCRIPT_VERIFY_NONE, ErrDisabledOpcode, 2},
+		{"bad opcode", "51ff", SCRIPT_VERIFY_NONE, ErrBadOpcode, 1},
+		{"truncated push", "5102ab", SCRIPT_VERIFY_NONE, ErrBadOpcode, 1},
+		{"unbalanced endif", "5168", SCRIPT_VERIFY_NONE, ErrUnbalancedConditional, 1},
+		{"number overflow", "0501020304055193", SCRIPT_VERIFY_NONE, ErrNumOverflow, 7},
+		{"minimal push", "0101", SCRIPT_VERIFY_MINIMALDATA, ErrMinimalData, 0},
+		{"upgradable nop", "51b0", SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_NOPS, ErrDiscourageUpgradableNops, 1},
+		{"sig encoding", "01010100ac", SCRIPT_VERIFY_DERSIG, ErrSigDer, 4},
+		{"negative locktime", "4fb1", SCRIPT_VERIFY_CHECKLOCKTIMEVERIFY, ErrNegativeLockTime, 1},
+	}
+
+	for _, test := range testCases {
+		_, err := VerifyScript(&Script{raw: []byte{}}, &Script{raw: fromHex(test.script)}, nil, fixedSigHash(big.NewInt(1)), test.flags)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.want, err)
+			continue
+		}
+		var scriptErr *ScriptError
+		if !errors.As(err, &scriptErr) || scriptErr.Pos != test.pos {
+			t.Errorf("%s: expected position '%v' but got '%v' instead", test.name, test.pos, err)
+		}
+	}
+
+	// failures outside an opcode are the bare errors
+	_, err := VerifyScript(&Script{raw: []byte{}}, &Script{raw: fromHex("00")}, nil, nil, SCRIPT_VERIFY_NONE)
+	assert.ErrorIs(t, err, ErrEvalFalse)
+}
+
+func TestConditionals(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script string
+		want   error
+	}{
+		// 1 IF 0 ELSE 0 ELSE 1 ENDIF: every OP_ELSE switches branch
+		{"multiple else", "5163006700675168", nil},
+		// 1 IF 0 IF 0 ELSE 1 ENDIF ENDIF
+		{"nested", "516300630067516868", nil},
+		// 0 IF 1 IF RETURN ELSE RETURN ENDIF ELSE 1 ENDIF
+		{"nested in skipped branch", "006351636a676a68675168", nil},
+		// 0 IF <0x68> ENDIF 1: pushed data is not an OP_ENDIF
+		{"push in skipped branch", "006301686851", nil},
+		// 0 IF RETURN ENDIF 1
+		{"return in skipped branch", "00636a6851", nil},
+		// 0 IF 1 ENDIF
+		{"no branch taken", "00635168", ErrEvalFalse},
+		{"disabled in skipped branch", "00637e6851", ErrDisabledOpcode},
+		{"verif in skipped branch", "0063656851", ErrBadOpcode},
+		// 1 IF 1
+		{"missing endif", "516351", ErrUnbalancedConditional},
+		// 1 ENDIF
+		{"missing if", "5168", ErrUnbalancedConditional},
+		// 1 ELSE 1 ENDIF
+		{"else without if", "5167516851", ErrUnbalancedConditional},
+		// IF with nothing to pop
+		{"empty stack", "635168", ErrUnbalancedConditional},
+	}
+
+	for _, test := range testCases {
+		_, err := VerifyScript(&Script{raw: []byte{}}, &Script{raw: fromHex(test.script)}, nil, nil, SCRIPT_VERIFY_NONE)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.want, err)
+		}
+	}
+}
+
+func TestScriptLimits(t *testing.T) {
+	r := strings.Repeat
+	// <0> <0> <n empty keys> <n> OP_CHECKMULTISIG, 0 of n passes
+	multisig := func(n int) string {
+		return "0000" + r("00", n) + hex.EncodeToString(pushData(encodeNum(n))) + "ae"
+	}
+
+	testCases := []struct {
+		name   string
+		script string
+		want   error
+	}{
+		{"201 ops", "51" + r("61", 201), nil},
+		{"202 ops", "51" + r("61", 202), ErrOpCount},
+		// 0 IF <200 NOPs> ENDIF 1
+		{"ops in skipped branch", "0063" + r("61", 200) + "6851", ErrOpCount},
+		{"multisig keys count as ops", r("61", 181) + multisig(20), ErrOpCount},
+		{"script size", "51" + r("00", MAX_SCRIPT_SIZE), ErrScriptSize},
+		{"520 byte push", "4d0802" + r("00", 520) + "51", nil},
+		{"521 byte push", "4d0902" + r("00", 521) + "51", ErrPushSize},
+		{"1000 items", r("51", 1000), nil},
+		{"1001 items", r("51", 1001), ErrStackSize},
+		// the altstack counts too
+		{"altstack", r("51", 1000) + "6b51", ErrStackSize},
+		{"20 keys", multisig(20), nil},
+		{"21 keys", multisig(21), ErrPubKeyCount},
+	}
+
+	for _, test := range testCases {
+		_, err := VerifyScript(&Script{raw: []byte{}}, &Script{raw: fromHex(test.script)}, nil, nil, SCRIPT_VERIFY_NONE)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.want, err)
+		}
+	}
+
+	// witness items are limited to 520 bytes as well
+	witnessScript := fromHex("7551")
+	hash := sha256.Sum256(witnessScript)
+	p2wsh := &Script{raw: append([]byte{0x00, 0x20}, hash[:]...)}
+	_, err := VerifyScript(&Script{raw: []byte{}}, p2wsh, [][]byte{make([]byte, 521), witnessScript}, nil, SCRIPT_VERIFY_P2SH|SCRIPT_VERIFY_WITNESS)
+	assert.ErrorIs(t, err, ErrPushSize)
+}
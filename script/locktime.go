@@ -0,0 +1,133 @@
+package script
+
+const (
+	// LOCKTIME_THRESHOLD splits lock times into block heights (below) and
+	// unix timestamps
+	LOCKTIME_THRESHOLD = 500000000
+
+	// SEQUENCE_FINAL opts an input out of lock time checks
+	SEQUENCE_FINAL = 0xffffffff
+
+	// BIP 68 relative lock time fields of the sequence. If the disable flag
+	// is set the sequence has no relative lock time, the type flag selects
+	// units of 512 seconds instead of blocks and the mask is the value
+	SEQUENCE_LOCKTIME_DISABLE_FLAG = 1 << 31
+	SEQUENCE_LOCKTIME_TYPE_FLAG    = 1 << 22
+	SEQUENCE_LOCKTIME_MASK         = 0x0000ffff
+	SEQUENCE_LOCKTIME_GRANULARITY  = 9
+
+	// lock time operands can be 5 bytes since they go up to 2^32-1
+	maxLockTimeNumLen = 5
+)
+
+// InputContext is what the interpreter knows about the input being verified:
+// how to get the digest its signatures commit to and the fields of the
+// spending transaction the timelock opcodes compare against
+type InputContext struct {
+	SigHash    SigHasher
+	TapSigHash TapSigHasher
+
+	Version  uint32 // of the spending transaction
+	LockTime uint32 // of the spending transaction
+	Sequence uint32 // of the input
+}
+
+// peekLockTime reads the lock time operand on top of the stack without
+// popping it. It can't be negative
+func peekLockTime(stack [][]byte, flags VerifyFlags) (int64, error) {
+	if len(stack) < 1 {
+		return 0, ErrStackUnderflow
+	}
+	top := stack[len(stack)-1]
+	if len(top) > maxLockTimeNumLen {
+		return 0, ErrNumOverflow
+	}
+	if flags&SCRIPT_VERIFY_MINIMALDATA != 0 && !isMinimalNum(top) {
+		return 0, ErrMinimalData
+	}
+	n := int64(decodeNum(top))
+	if n < 0 {
+		return 0, ErrNegativeLockTime
+	}
+	return n, nil
+}
+
+// upgradableNop is what OP_NOP2 and OP_NOP3 do when their soft fork isn't
+// enforced
+func upgradableNop(stack [][]byte, flags VerifyFlags) ([][]byte, error) {
+	if flags&SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_NOPS != 0 {
+		return stack, ErrDiscourageUpgradableNops
+	}
+	return stack, nil
+}
+
+// opcodeCheckLockTimeVerify is OP_CHECKLOCKTIMEVERIFY (BIP 65). It fails
+// unless the spending tx has a lock time of the same kind (height or time) at
+// least the one on the stack and the input doesn't opt out of it with a
+// final sequence. It leaves the stack as is
+func opcodeCheckLockTimeVerify(stack [][]byte, ctx *execContext) ([][]byte, error) {
+	if ctx.flags&SCRIPT_VERIFY_CHECKLOCKTIMEVERIFY == 0 {
+		// OP_NOP2
+		return upgradableNop(stack, ctx.flags)
+	}
+	lockTime, err := peekLockTime(stack, ctx.flags)
+	if err != nil {
+		return stack, err
+	}
+	if ctx.input == nil {
+		return stack, ErrUnsatisfiedLockTime
+	}
+	txLockTime := int64(ctx.input.LockTime)
+	if (lockTime < LOCKTIME_THRESHOLD) != (txLockTime < LOCKTIME_THRESHOLD) {
+		return stack, ErrUnsatisfiedLockTime
+	}
+	if lockTime > txLockTime {
+		return stack, ErrUnsatisfiedLockTime
+	}
+	// a final sequence would let the tx be mined whatever its lock time
+	if ctx.input.Sequence == SEQUENCE_FINAL {
+		return stack, ErrUnsatisfiedLockTime
+	}
+	return stack, nil
+}
+
+// opcodeCheckSequenceVerify is OP_CHECKSEQUENCEVERIFY (BIP 112). It fails
+// unless the input's sequence is a BIP 68 relative lock time of the same
+// kind (blocks or 512 second units) at least the one on the stack. A stack
+// value with the disable flag set makes it a nop
+func opcodeCheckSequenceVerify(stack [][]byte, ctx *execContext) ([][]byte, error) {
+	if ctx.flags&SCRIPT_VERIFY_CHECKSEQUENCEVERIFY == 0 {
+		// OP_NOP3
+		return upgradableNop(stack, ctx.flags)
+	}
+	sequence, err := peekLockTime(stack, ctx.flags)
+	if err != nil {
+		return stack, err
+	}
+	if sequence&SEQUENCE_LOCKTIME_DISABLE_FLAG != 0 {
+		return stack, nil
+	}
+	if ctx.input == nil {
+		return stack, ErrUnsatisfiedLockTime
+	}
+
+	// relative lock times only apply from version 2 on
+	if ctx.input.Version < 2 {
+		return stack, ErrUnsatisfiedLockTime
+	}
+	txSequence := int64(ctx.input.Sequence)
+	if txSequence&SEQUENCE_LOCKTIME_DISABLE_FLAG != 0 {
+		return stack, ErrUnsatisfiedLockTime
+	}
+
+	mask := int64(SEQUENCE_LOCKTIME_TYPE_FLAG | SEQUENCE_LOCKTIME_MASK)
+	sequence &= mask
+	txSequence &= mask
+	if (sequence < SEQUENCE_LOCKTIME_TYPE_FLAG) != (txSequence < SEQUENCE_LOCKTIME_TYPE_FLAG) {
+		return stack, ErrUnsatisfiedLockTime
+	}
+	if sequence > txSequence {
+		return stack, ErrUnsatisfiedLockTime
+	}
+	return stack, nil
+}
@@ -0,0 +1,69 @@
+package script
+
+import (
+	"testing"
+)
+
+func TestCheckLockTimeVerify(t *testing.T) {
+	const enabled = SCRIPT_VERIFY_CHECKLOCKTIMEVERIFY
+
+	testCases := []struct {
+		name     string
+		lockTime int
+		input    InputContext
+		flags    VerifyFlags
+		want     bool
+	}{
+		{"height reached", 500, InputContext{LockTime: 500, Sequence: 0xfffffffe}, enabled, true},
+		{"height not reached", 501, InputContext{LockTime: 500, Sequence: 0xfffffffe}, enabled, false},
+		{"final sequence", 500, InputContext{LockTime: 500, Sequence: SEQUENCE_FINAL}, enabled, false},
+		{"time reached", 1600000000, InputContext{LockTime: 1600000001, Sequence: 0}, enabled, true},
+		{"height against time", 500, InputContext{LockTime: 1600000000, Sequence: 0}, enabled, false},
+		{"negative", -500, InputContext{LockTime: 1000, Sequence: 0}, enabled, false},
+		{"5 byte operand", 0xffffffff, InputContext{LockTime: 0xffffffff, Sequence: 0}, enabled, true},
+		{"nop without flag", 501, InputContext{LockTime: 500, Sequence: SEQUENCE_FINAL}, SCRIPT_VERIFY_NONE, true},
+	}
+
+	for _, test := range testCases {
+		input := test.input
+		// <lockTime> OP_CHECKLOCKTIMEVERIFY OP_DROP OP_1
+		cmds := [][]byte{encodeNum(test.lockTime), {0xb1}, {0x75}, {0x51}}
+		valid, _ := NewScript(nil).Combine(NewScript(cmds)).Evaluate(&input, test.flags)
+		if valid != test.want {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.want, valid)
+		}
+	}
+}
+
+func TestCheckSequenceVerify(t *testing.T) {
+	const enabled = SCRIPT_VERIFY_CHECKSEQUENCEVERIFY
+	const timeFlag = SEQUENCE_LOCKTIME_TYPE_FLAG
+
+	testCases := []struct {
+		name     string
+		sequence int
+		input    InputContext
+		flags    VerifyFlags
+		want     bool
+	}{
+		{"blocks reached", 144, InputContext{Version: 2, Sequence: 144}, enabled, true},
+		{"blocks not reached", 145, InputContext{Version: 2, Sequence: 144}, enabled, false},
+		{"time reached", timeFlag | 300, InputContext{Version: 2, Sequence: timeFlag | 301}, enabled, true},
+		{"blocks against time", 300, InputContext{Version: 2, Sequence: timeFlag | 301}, enabled, false},
+		{"version 1", 144, InputContext{Version: 1, Sequence: 144}, enabled, false},
+		{"input disabled", 144, InputContext{Version: 2, Sequence: SEQUENCE_LOCKTIME_DISABLE_FLAG | 144}, enabled, false},
+		{"operand disabled", SEQUENCE_LOCKTIME_DISABLE_FLAG, InputContext{Version: 1, Sequence: 0}, enabled, true},
+		{"bits outside the mask ignored", 1<<16 | 144, InputContext{Version: 2, Sequence: 144}, enabled, true},
+		{"nop without flag", 145, InputContext{Version: 2, Sequence: 144}, SCRIPT_VERIFY_NONE, true},
+	}
+
+	for _, test := range testCases {
+		input := test.input
+		// <sequence> OP_CHECKSEQUENCEVERIFY OP_DROP OP_1
+		cmds := [][]byte{encodeNum(test.sequence), {0xb2}, {0x75}, {0x51}}
+		valid, _ := NewScript(nil).Combine(NewScript(cmds)).Evaluate(&input, test.flags)
+		if valid != test.want {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.want, valid)
+		}
+	}
+}
@@ -0,0 +1,1115 @@
+package script
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"golang.org/x/crypto/ripemd160"
+)
+
+var opcodesFuncs map[byte]func([][]byte) ([][]byte, error) = map[byte]func([][]byte) ([][]byte, error){
+	0x00: opcode0,
+	0x4f: opcode1Negate,
+	0x51: opcode1,
+	0x52: opcode2,
+	0x53: opcode3,
+	0x54: opcode4,
+	0x55: opcode5,
+	0x56: opcode6,
+	0x57: opcode7,
+	0x58: opcode8,
+	0x59: opcode9,
+	0x5a: opcode10,
+	0x5b: opcode11,
+	0x5c: opcode12,
+	0x5d: opcode13,
+	0x5e: opcode14,
+	0x5f: opcode15,
+	0x60: opcode16,
+	0x50: opcodeReserved,
+	0x61: opcodeNop,
+	0x62: opcodeReserved,
+	0x69: opcodeVerify,
+	0x6a: opcodeReturn,
+	0x6d: opcode2Drop,
+	0x6e: opcode2Dup,
+	0x6f: opcode3Dup,
+	0x70: opcode2Over,
+	0x71: opcode2Rot,
+	0x72: opcode2Swap,
+	0x73: opcodeIfDup,
+	0x74: opcodeDepth,
+	0x75: opcodeDrop,
+	0x76: opcodeDup,
+	0x77: opcodeNip,
+	0x78: opcodeOver,
+	0x79: opcodePick,
+	0x7a: opcodeRoll,
+	0x7b: opcodeRot,
+	0x7c: opcodeSwap,
+	0x7d: opcodeTuck,
+	0x82: opcodeSize,
+	0x87: opcodeEqual,
+	0x88: opcodeEqualVerify,
+	0x89: opcodeReserved,
+	0x8a: opcodeReserved,
+
+	// arithmetic opcodes
+	0x8b: opcode1Add,
+	0x8c: opcode1Sub,
+	0x8f: opcodeNegate,
+	0x90: opcodeAbs,
+	0x91: opcodeNot,
+	0x92: opcode0NotEqual,
+	0x93: opcodeAdd,
+	0x94: opcodeSub,
+	0x9a: opcodeBoolAnd,
+	0x9b: opcodeBoolOr,
+	0x9c: opcodeNumEqual,
+	0x9d: opcodeNumEqualVerify,
+	0x9e: opcodeNumNotEqual,
+	0x9f: opcodeLessThan,
+	0xa0: opcodeGreaterThan,
+	0xa1: opcodeLessThanOrEqual,
+	0xa2: opcodeGreaterThanOrEqual,
+	0xa3: opcodeMin,
+	0xa4: opcodeMax,
+	0xa5: opcodeWithin,
+
+	// crypto opcodes
+	0xa6: opcodeRipemd160,
+	0xa7: opcodeSha1,
+	0xa8: opcodeSha256,
+	0xa9: opcodeHash160,
+	0xaa: opcodeHash256,
+
+	// expansion nops
+	0xb0: opcodeNop,
+	0xb3: opcodeNop,
+	0xb4: opcodeNop,
+	0xb5: opcodeNop,
+	0xb6: opcodeNop,
+	0xb7: opcodeNop,
+	0xb8: opcodeNop,
+	0xb9: opcodeNop,
+}
+
+// opcodesDisabled fail the script wherever they appear
+var opcodesDisabled map[byte]bool = map[byte]bool{
+	0x7e: true, // OP_CAT
+	0x7f: true, // OP_SUBSTR
+	0x80: true, // OP_LEFT
+	0x81: true, // OP_RIGHT
+	0x83: true, // OP_INVERT
+	0x84: true, // OP_AND
+	0x85: true, // OP_OR
+	0x86: true, // OP_XOR
+	0x8d: true, // OP_2MUL
+	0x8e: true, // OP_2DIV
+	0x95: true, // OP_MUL
+	0x96: true, // OP_DIV
+	0x97: true, // OP_MOD
+	0x98: true, // OP_LSHIFT
+	0x99: true, // OP_RSHIFT
+}
+
+var opcodesAltStack map[byte]func([][]byte, [][]byte) ([][]byte, [][]byte, error) = map[byte]func([][]byte, [][]byte) ([][]byte, [][]byte, error){
+	0x6b: opcodeToAltStack,
+	0x6c: opcodeFromAltStack,
+}
+
+// opcodesContext need more than the stacks: the input being verified and
+// the flags
+var opcodesContext map[byte]func([][]byte, *execContext) ([][]byte, error) = map[byte]func([][]byte, *execContext) ([][]byte, error){
+	0xac: opcodeChecksig,
+	0xad: opcodeChecksigVerify,
+	0xae: opcodeCheckMultisig,
+	0xaf: opcodeCheckMultisigVerify,
+	0xb1: opcodeCheckLockTimeVerify,
+	0xb2: opcodeCheckSequenceVerify,
+	0xba: opcodeChecksigAdd,
+}
+
+var opcodesNames map[byte]string = map[byte]string{
+	0x00: "OP_0",
+	0x4c: "OP_PUSHDATA1",
+	0x4d: "OP_PUSHDATA2",
+	0x4e: "OP_PUSHDATA4",
+	0x4f: "OP_1NEGATE",
+	0x50: "OP_RESERVED",
+	0x51: "OP_1",
+	0x52: "OP_2",
+	0x53: "OP_3",
+	0x54: "OP_4",
+	0x55: "OP_5",
+	0x56: "OP_6",
+	0x57: "OP_7",
+	0x58: "OP_8",
+	0x59: "OP_9",
+	0x5a: "OP_10",
+	0x5b: "OP_11",
+	0x5c: "OP_12",
+	0x5d: "OP_13",
+	0x5e: "OP_14",
+	0x5f: "OP_15",
+	0x60: "OP_16",
+	0x61: "OP_NOP",
+	0x62: "OP_VER",
+	0x63: "OP_IF",
+	0x64: "OP_NOTIF",
+	0x65: "OP_VERIF",
+	0x66: "OP_VERNOTIF",
+	0x67: "OP_ELSE",
+	0x68: "OP_ENDIF",
+	0x69: "OP_VERIFY",
+	0x6a: "OP_RETURN",
+
+	// stack
+	0x6b: "OP_TOALTSTACK",
+	0x6c: "OP_FROMALTSTACK",
+	0x6d: "OP_2DROP",
+	0x6e: "OP_2DUP",
+	0x6f: "OP_3DUP",
+	0x70: "OP_2OVER",
+	0x71: "OP_2ROT",
+	0x72: "OP_2SWAP",
+	0x73: "OP_IFDUP",
+	0x74: "OP_DEPTH",
+	0x75: "OP_DROP",
+	0x76: "OP_DUP",
+	0x77: "OP_NIP",
+	0x78: "OP_OVER",
+	0x79: "OP_PICK",
+	0x7a: "OP_ROLL",
+	0x7b: "OP_ROT",
+	0x7c: "OP_SWAP",
+	0x7d: "OP_TUCK",
+
+	// splice
+	0x7e: "OP_CAT",
+	0x7f: "OP_SUBSTR",
+	0x80: "OP_LEFT",
+	0x81: "OP_RIGHT",
+	0x82: "OP_SIZE",
+
+	// bitwise logic
+	0x83: "OP_INVERT",
+	0x84: "OP_AND",
+	0x85: "OP_OR",
+	0x86: "OP_XOR",
+	0x87: "OP_EQUAL",
+	0x88: "OP_EQUALVERIFY",
+	0x89: "OP_RESERVED1",
+	0x8a: "OP_RESERVED2",
+
+	// arithmetic
+	0x8b: "OP_1ADD",
+	0x8c: "OP_1SUB",
+	0x8d: "OP_2MUL",
+	0x8e: "OP_2DIV",
+	0x8f: "OP_NEGATE",
+	0x90: "OP_ABS",
+	0x91: "OP_NOT",
+	0x92: "OP_0NOTEQUAL",
+	0x93: "OP_ADD",
+	0x94: "OP_SUB",
+	0x95: "OP_MUL",
+	0x96: "OP_DIV",
+	0x97: "OP_MOD",
+	0x98: "OP_LSHIFT",
+	0x99: "OP_RSHIFT",
+	0x9a: "OP_BOOLAND",
+	0x9b: "OP_BOOLOR",
+	0x9c: "OP_NUMEQUAL",
+	0x9d: "OP_NUMEQUALVERIFY",
+	0x9e: "OP_NUMNOTEQUAL",
+	0x9f: "OP_LESSTHAN",
+	0xa0: "OP_GREATERTHAN",
+	0xa1: "OP_LESSTHANOREQUAL",
+	0xa2: "OP_GREATERTHANOREQUAL",
+	0xa3: "OP_MIN",
+	0xa4: "OP_MAX",
+	0xa5: "OP_WITHIN",
+
+	// crypto opcodes
+	0xa6: "OP_RIPEMD160",
+	0xa7: "OP_SHA1",
+	0xa8: "OP_SHA256",
+	0xa9: "OP_HASH160",
+	0xaa: "OP_HASH256",
+
+	0xab: "OP_CODESEPARATOR",
+	0xac: "OP_CHECKSIG",
+	0xad: "OP_CHECKSIGVERIFY",
+	0xae: "OP_CHECKMULTISIG",
+	0xaf: "OP_CHECKMULTISIGVERIFY",
+
+	// expansion
+	0xb0: "OP_NOP1",
+	0xb1: "OP_CHECKLOCKTIMEVERIFY",
+	0xb2: "OP_CHECKSEQUENCEVERIFY",
+	0xb3: "OP_NOP4",
+	0xb4: "OP_NOP5",
+	0xb5: "OP_NOP6",
+	0xb6: "OP_NOP7",
+	0xb7: "OP_NOP8",
+	0xb8: "OP_NOP9",
+	0xb9: "OP_NOP10",
+	0xba: "OP_CHECKSIGADD",
+}
+
+// opcodeAliases are other names Bitcoin Core knows some opcodes by
+var opcodeAliases = map[string]byte{
+	"OP_FALSE": 0x00,
+	"OP_TRUE":  0x51,
+	"OP_NOP2":  0xb1,
+	"OP_NOP3":  0xb2,
+}
+
+var opcodesByName = func() map[string]byte {
+	byName := map[string]byte{}
+	for op, name := range opcodesNames {
+		byName[name] = op
+	}
+	for name, op := range opcodeAliases {
+		byName[name] = op
+	}
+	return byName
+}()
+
+// OpcodeByName returns the opcode called name, with or without the OP_
+// prefix: OP_CHECKSIG and CHECKSIG are both 0xac
+func OpcodeByName(name string) (byte, bool) {
+	if !strings.HasPrefix(name, "OP_") {
+		name = "OP_" + name
+	}
+	op, ok := opcodesByName[name]
+	return op, ok
+}
+
+func encodeNum(num int) []byte {
+	if num == 0 {
+		return nil
+	}
+	absNum := abs(num)
+	negative := num < 0
+	result := []byte{}
+
+	for absNum > 0 {
+		result = append(result, byte(absNum&0xff))
+		absNum >>= 8
+	}
+
+	if result[len(result)-1]&0x80 != 0 {
+		if negative {
+			result = append(result, 0x80)
+		} else {
+			result = append(result, 0)
+		}
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+	return result
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// castToBool is false for any encoding of zero, including negative zero
+func castToBool(element []byte) bool {
+	for i, b := range element {
+		if b != 0 {
+			return !(i == len(element)-1 && b == 0x80)
+		}
+	}
+	return false
+}
+
+func decodeNum(element []byte) int {
+	if len(element) == 0 {
+		return 0
+	}
+	var result int
+
+	negative := true
+	bigEndian := encoding.Reverse(element)
+	if bigEndian[0]&0x80 != 0 {
+		result = int(bigEndian[0] & 0x7f)
+	} else {
+		negative = false
+		result = int(bigEndian[0])
+	}
+
+	for _, val := range bigEndian[1:] {
+		result <<= 8
+		result += int(val)
+	}
+
+	if negative {
+		return -result
+	}
+	return result
+}
+
+// maxScriptNumLen is the longest numeric operand the arithmetic opcodes
+// accept. Results can be longer but can't be used as operands again
+const maxScriptNumLen = 4
+
+// popNum pops a numeric operand off the stack. It fails if the stack is
+// empty or the operand is longer than 4 bytes
+func popNum(stack [][]byte) (int, [][]byte, error) {
+	if len(stack) < 1 {
+		return 0, stack, ErrStackUnderflow
+	}
+	item, stack := pop(stack)
+	if len(item) > maxScriptNumLen {
+		return 0, stack, ErrNumOverflow
+	}
+	return decodeNum(item), stack, nil
+}
+
+func boolNum(b bool) []byte {
+	if b {
+		return encodeNum(1)
+	}
+	return encodeNum(0)
+}
+
+func pop(stack [][]byte) ([]byte, [][]byte) {
+	top := stack[len(stack)-1]
+	stack = stack[:len(stack)-1]
+	return top, stack
+}
+
+func opcode0(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(0))
+	return stack, nil
+}
+
+func opcode1Negate(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(-1))
+	return stack, nil
+}
+
+func opcode1(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(1))
+	return stack, nil
+}
+
+func opcode2(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(2))
+	return stack, nil
+}
+
+func opcode3(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(3))
+	return stack, nil
+}
+
+func opcode4(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(4))
+	return stack, nil
+}
+
+func opcode5(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(5))
+	return stack, nil
+}
+
+func opcode6(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(6))
+	return stack, nil
+}
+
+func opcode7(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(7))
+	return stack, nil
+}
+
+func opcode8(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(8))
+	return stack, nil
+}
+
+func opcode9(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(9))
+	return stack, nil
+}
+
+func opcode10(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(10))
+	return stack, nil
+}
+
+func opcode11(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(11))
+	return stack, nil
+}
+
+func opcode12(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(12))
+	return stack, nil
+}
+
+func opcode13(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(13))
+	return stack, nil
+}
+
+func opcode14(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(14))
+	return stack, nil
+}
+
+func opcode15(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(15))
+	return stack, nil
+}
+
+func opcode16(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(16))
+	return stack, nil
+}
+
+func opcodeNop(stack [][]byte) ([][]byte, error) {
+	return stack, nil
+}
+
+// opcodeReserved is OP_RESERVED, OP_VER, OP_RESERVED1 and OP_RESERVED2,
+// which fail the script when they are run
+func opcodeReserved(stack [][]byte) ([][]byte, error) {
+	return stack, ErrBadOpcode
+}
+
+func opcodeVerify(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	item, stack := pop(stack)
+	if !castToBool(item) {
+		return stack, ErrVerify
+	}
+	return stack, nil
+}
+
+func opcodeReturn(stack [][]byte) ([][]byte, error) {
+	return stack, ErrOpReturn
+}
+
+func opcodeToAltStack(stack, altStack [][]byte) ([][]byte, [][]byte, error) {
+	if len(stack) < 1 {
+		return stack, altStack, ErrStackUnderflow
+	}
+	item, stack := pop(stack)
+	altStack = append(altStack, item)
+	return stack, altStack, nil
+}
+
+func opcodeFromAltStack(stack, altStack [][]byte) ([][]byte, [][]byte, error) {
+	if len(altStack) < 1 {
+		return stack, altStack, ErrAltStackUnderflow
+	}
+	item, altStack := pop(altStack)
+	stack = append(stack, item)
+	return stack, altStack, nil
+}
+
+func opcode2Drop(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	stack = stack[:len(stack)-2]
+	return stack, nil
+}
+
+func opcode2Dup(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	stack = append(stack, stack[len(stack)-2:]...)
+	return stack, nil
+}
+
+func opcode3Dup(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 3 {
+		return stack, ErrStackUnderflow
+	}
+	stack = append(stack, stack[len(stack)-3:]...)
+	return stack, nil
+}
+
+func opcode2Over(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 4 {
+		return stack, ErrStackUnderflow
+	}
+	stacklen := len(stack)
+	stack = append(stack, stack[stacklen-4:stacklen-2]...)
+	return stack, nil
+}
+
+// opcode2Rot moves the fifth and sixth items to the top
+func opcode2Rot(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 6 {
+		return stack, ErrStackUnderflow
+	}
+	stacklen := len(stack)
+	x1, x2 := stack[stacklen-6], stack[stacklen-5]
+	copy(stack[stacklen-6:], stack[stacklen-4:])
+	stack[stacklen-2], stack[stacklen-1] = x1, x2
+	return stack, nil
+}
+
+// opcode2Swap swaps the top two pairs of items
+func opcode2Swap(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 4 {
+		return stack, ErrStackUnderflow
+	}
+	stacklen := len(stack)
+	stack[stacklen-4], stack[stacklen-2] = stack[stacklen-2], stack[stacklen-4]
+	stack[stacklen-3], stack[stacklen-1] = stack[stacklen-1], stack[stacklen-3]
+	return stack, nil
+}
+
+func opcodeIfDup(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	top := stack[len(stack)-1]
+	if castToBool(top) {
+		stack = append(stack, stack[len(stack)-1])
+	}
+	return stack, nil
+}
+
+func opcodeDepth(stack [][]byte) ([][]byte, error) {
+	stack = append(stack, encodeNum(len(stack)))
+	return stack, nil
+}
+
+func opcodeDrop(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	stack = stack[:len(stack)-1]
+	return stack, nil
+}
+
+func opcodeDup(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	stack = append(stack, stack[len(stack)-1])
+	return stack, nil
+}
+
+func opcodeNip(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	stack[len(stack)-2] = stack[len(stack)-1]
+	stack = stack[:len(stack)-1]
+	return stack, nil
+}
+
+func opcodeOver(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	stack = append(stack, stack[len(stack)-2])
+	return stack, nil
+}
+
+// opcodePick copies the item n back to the top
+func opcodePick(stack [][]byte) ([][]byte, error) {
+	n, stack, err := popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	if n < 0 || n >= len(stack) {
+		return stack, ErrStackUnderflow
+	}
+	stack = append(stack, stack[len(stack)-n-1])
+	return stack, nil
+}
+
+// opcodeRoll moves the item n back to the top
+func opcodeRoll(stack [][]byte) ([][]byte, error) {
+	n, stack, err := popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	if n < 0 || n >= len(stack) {
+		return stack, ErrStackUnderflow
+	}
+	idx := len(stack) - n - 1
+	item := stack[idx]
+	copy(stack[idx:], stack[idx+1:])
+	stack[len(stack)-1] = item
+	return stack, nil
+}
+
+// opcodeRot moves the third item to the top
+func opcodeRot(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 3 {
+		return stack, ErrStackUnderflow
+	}
+	stacklen := len(stack)
+	stack[stacklen-3], stack[stacklen-2], stack[stacklen-1] = stack[stacklen-2], stack[stacklen-1], stack[stacklen-3]
+	return stack, nil
+}
+
+func opcodeSwap(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	stack[len(stack)-1], stack[len(stack)-2] = stack[len(stack)-2], stack[len(stack)-1]
+	return stack, nil
+}
+
+func opcodeTuck(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	// x1 x2 -> x2 x1 x2
+	top := stack[len(stack)-1]
+	stack = append(stack, top)
+	stack[len(stack)-2], stack[len(stack)-3] = stack[len(stack)-3], top
+	return stack, nil
+}
+
+func opcodeSize(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	stack = append(stack, encodeNum(len(stack[len(stack)-1])))
+	return stack, nil
+}
+
+func opcodeEqual(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+
+	item1, stack := pop(stack)
+	item2, stack := pop(stack)
+	stack = append(stack, boolNum(bytes.Equal(item1, item2)))
+	return stack, nil
+}
+
+func opcodeEqualVerify(stack [][]byte) ([][]byte, error) {
+	stack, err := opcodeEqual(stack)
+	if err != nil {
+		return stack, err
+	}
+	if stack, err = opcodeVerify(stack); err != nil {
+		return stack, ErrEqualVerify
+	}
+	return stack, nil
+}
+
+// unaryNumOp replaces the number on top of the stack with op(a)
+func unaryNumOp(stack [][]byte, op func(a int) int) ([][]byte, error) {
+	a, stack, err := popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	return append(stack, encodeNum(op(a))), nil
+}
+
+// binaryNumOp replaces the top two numbers with op(a, b), b being the top
+func binaryNumOp(stack [][]byte, op func(a, b int) int) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	b, stack, err := popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	a, stack, err := popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	return append(stack, encodeNum(op(a, b))), nil
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func opcode1Add(stack [][]byte) ([][]byte, error) {
+	return unaryNumOp(stack, func(a int) int { return a + 1 })
+}
+
+func opcode1Sub(stack [][]byte) ([][]byte, error) {
+	return unaryNumOp(stack, func(a int) int { return a - 1 })
+}
+
+func opcodeNegate(stack [][]byte) ([][]byte, error) {
+	return unaryNumOp(stack, func(a int) int { return -a })
+}
+
+func opcodeAbs(stack [][]byte) ([][]byte, error) {
+	return unaryNumOp(stack, abs)
+}
+
+func opcodeNot(stack [][]byte) ([][]byte, error) {
+	return unaryNumOp(stack, func(a int) int { return boolInt(a == 0) })
+}
+
+func opcode0NotEqual(stack [][]byte) ([][]byte, error) {
+	return unaryNumOp(stack, func(a int) int { return boolInt(a != 0) })
+}
+
+func opcodeAdd(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return a + b })
+}
+
+func opcodeSub(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return a - b })
+}
+
+func opcodeBoolAnd(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return boolInt(a != 0 && b != 0) })
+}
+
+func opcodeBoolOr(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return boolInt(a != 0 || b != 0) })
+}
+
+func opcodeNumEqual(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return boolInt(a == b) })
+}
+
+func opcodeNumEqualVerify(stack [][]byte) ([][]byte, error) {
+	stack, err := opcodeNumEqual(stack)
+	if err != nil {
+		return stack, err
+	}
+	if stack, err = opcodeVerify(stack); err != nil {
+		return stack, ErrNumEqualVerify
+	}
+	return stack, nil
+}
+
+func opcodeNumNotEqual(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return boolInt(a != b) })
+}
+
+func opcodeLessThan(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return boolInt(a < b) })
+}
+
+func opcodeGreaterThan(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return boolInt(a > b) })
+}
+
+func opcodeLessThanOrEqual(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return boolInt(a <= b) })
+}
+
+func opcodeGreaterThanOrEqual(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int { return boolInt(a >= b) })
+}
+
+func opcodeMin(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int {
+		if a < b {
+			return a
+		}
+		return b
+	})
+}
+
+func opcodeMax(stack [][]byte) ([][]byte, error) {
+	return binaryNumOp(stack, func(a, b int) int {
+		if a > b {
+			return a
+		}
+		return b
+	})
+}
+
+// opcodeWithin pushes 1 if min <= x < max for x min max
+func opcodeWithin(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 3 {
+		return stack, ErrStackUnderflow
+	}
+	max, stack, err := popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	min, stack, err := popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	x, stack, err := popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	return append(stack, boolNum(min <= x && x < max)), nil
+}
+
+func opcodeRipemd160(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	item, stack := pop(stack)
+	r160 := ripemd160.New()
+	r160.Write(item)
+	stack = append(stack, r160.Sum(nil))
+	return stack, nil
+}
+
+func opcodeSha1(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	item, stack := pop(stack)
+	hash := sha1.Sum(item)
+	stack = append(stack, hash[:])
+	return stack, nil
+}
+
+func opcodeSha256(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	item, stack := pop(stack)
+	hash := sha256.Sum256(item)
+	stack = append(stack, hash[:])
+	return stack, nil
+}
+
+func opcodeHash160(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	item, stack := pop(stack)
+	hash := encoding.Hash160(item)
+	stack = append(stack, hash[:])
+	return stack, nil
+}
+
+func opcodeHash256(stack [][]byte) ([][]byte, error) {
+	if len(stack) < 1 {
+		return stack, ErrStackUnderflow
+	}
+	item, stack := pop(stack)
+	hash := encoding.Hash256(item)
+	stack = append(stack, hash[:])
+	return stack, nil
+}
+
+// checkSignatureEncoding enforces the DER, low-S and hash type rules
+// selected by flags. sig includes the sighash type byte. An empty signature
+// is always allowed so scripts can fail a signature check without failing
+// the script
+func checkSignatureEncoding(sig []byte, flags VerifyFlags) error {
+	if len(sig) == 0 {
+		return nil
+	}
+	if flags&(SCRIPT_VERIFY_DERSIG|SCRIPT_VERIFY_LOW_S|SCRIPT_VERIFY_STRICTENC) != 0 {
+		parsed, err := ecc.ParseDERSignature(sig[:len(sig)-1])
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrSigDer, err)
+		}
+		if flags&SCRIPT_VERIFY_LOW_S != 0 && !parsed.IsLowS() {
+			return ErrSigHighS
+		}
+	}
+	if flags&SCRIPT_VERIFY_STRICTENC != 0 {
+		// SIGHASH_ALL, SIGHASH_NONE or SIGHASH_SINGLE, with or without
+		// SIGHASH_ANYONECANPAY
+		baseType := sig[len(sig)-1] &^ 0x80
+		if baseType < 0x01 || baseType > 0x03 {
+			return fmt.Errorf("%w: 0x%02x", ErrSigHashType, sig[len(sig)-1])
+		}
+	}
+	return nil
+}
+
+// checkPubKeyEncoding enforces the public key formats selected by flags:
+// compressed or uncompressed SEC under STRICTENC and only compressed keys in
+// segwit v0 scripts under WITNESS_PUBKEYTYPE
+func checkPubKeyEncoding(pubKey []byte, flags VerifyFlags, sigVersion SigVersion) error {
+	compressed := len(pubKey) == 33 && (pubKey[0] == 0x02 || pubKey[0] == 0x03)
+	uncompressed := len(pubKey) == 65 && pubKey[0] == 0x04
+	if flags&SCRIPT_VERIFY_STRICTENC != 0 && !compressed && !uncompressed {
+		return ErrPubKeyType
+	}
+	if flags&SCRIPT_VERIFY_WITNESS_PUBKEYTYPE != 0 && sigVersion == SIGVERSION_WITNESS_V0 && !compressed {
+		return ErrWitnessPubKeyType
+	}
+	return nil
+}
+
+// checkLegacySig reports whether signature (with its hash type byte) by
+// pubKey over scriptCode is valid, using check to verify it. Anything that
+// doesn't parse is just an invalid signature
+func checkLegacySig(ctx *execContext, signature, pubKey, scriptCode []byte, check sigChecker) bool {
+	if len(signature) == 0 {
+		return false
+	}
+	pubKeyPoint, err := ecc.ParsePubKey(pubKey)
+	if err != nil {
+		return false
+	}
+	// last byte of the signature is the hash type
+	sig, err := ecc.ParseSignature(signature[:len(signature)-1])
+	if err != nil {
+		return false
+	}
+	if ctx.input == nil || ctx.input.SigHash == nil {
+		return false
+	}
+	z, err := ctx.input.SigHash(signature[len(signature)-1], scriptCode, ctx.sigVersion)
+	if err != nil {
+		return false
+	}
+	return check(pubKeyPoint, sig, z)
+}
+
+// opcodeChecksig pushes whether the signature is valid. A failed check is not
+// an error so a script can go on, e.g. with OP_NOT, unless NULLFAIL is set
+// and the signature isn't empty
+func opcodeChecksig(stack [][]byte, ctx *execContext) ([][]byte, error) {
+	if ctx.tapscript != nil {
+		return opcodeChecksigTapscript(stack, ctx)
+	}
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	pubKey, stack := pop(stack)
+	signature, stack := pop(stack)
+
+	scriptCode, err := ctx.scriptCode(signature)
+	if err != nil {
+		return stack, err
+	}
+	if err := checkSignatureEncoding(signature, ctx.flags); err != nil {
+		return stack, err
+	}
+	if err := checkPubKeyEncoding(pubKey, ctx.flags, ctx.sigVersion); err != nil {
+		return stack, err
+	}
+	valid := checkLegacySig(ctx, signature, pubKey, scriptCode, ctx.check)
+	if !valid && ctx.flags&SCRIPT_VERIFY_NULLFAIL != 0 && len(signature) > 0 {
+		return stack, ErrSigNullFail
+	}
+	return append(stack, boolNum(valid)), nil
+}
+
+func opcodeChecksigVerify(stack [][]byte, ctx *execContext) ([][]byte, error) {
+	stack, err := opcodeChecksig(stack, ctx)
+	if err != nil {
+		return stack, err
+	}
+	if stack, err = opcodeVerify(stack); err != nil {
+		return stack, ErrCheckSigVerify
+	}
+	return stack, nil
+}
+
+// opcodeCheckMultisig checks m signatures against n keys, in the order they
+// were pushed. A key that doesn't match the current signature is skipped and
+// the check fails once there are fewer keys left than signatures. It always
+// verifies right away since which key a signature matches depends on which
+// checks fail
+func opcodeCheckMultisig(stack [][]byte, ctx *execContext) ([][]byte, error) {
+	// tapscript replaces it with OP_CHECKSIGADD
+	if ctx.tapscript != nil {
+		return stack, ErrTapscriptCheckMultisig
+	}
+	// m-of-n multisig
+	n, stack, err := ctx.popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	if n < 0 || n > MAX_PUBKEYS_PER_MULTISIG {
+		return stack, ErrPubKeyCount
+	}
+	ctx.opCount += n
+	if ctx.opCount > MAX_OPS_PER_SCRIPT {
+		return stack, ErrOpCount
+	}
+	if len(stack) < n+1 {
+		return stack, ErrStackUnderflow
+	}
+	// the last key pushed is checked first
+	pubKeys := make([][]byte, n)
+	for i := range pubKeys {
+		pubKeys[i], stack = pop(stack)
+	}
+
+	m, stack, err := ctx.popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	if m < 0 || m > n {
+		return stack, ErrSigCount
+	}
+	if len(stack) < m+1 {
+		return stack, ErrStackUnderflow
+	}
+	sigs := make([][]byte, m)
+	for i := range sigs {
+		sigs[i], stack = pop(stack)
+	}
+	// pop for bug of extra value unused, which NULLDUMMY wants empty
+	dummy, stack := pop(stack)
+	if ctx.flags&SCRIPT_VERIFY_NULLDUMMY != 0 && len(dummy) != 0 {
+		return stack, ErrSigNullDummy
+	}
+
+	scriptCode, err := ctx.scriptCode(sigs...)
+	if err != nil {
+		return stack, err
+	}
+	success := true
+	for isig, ikey := 0, 0; success && isig < m; {
+		if err := checkSignatureEncoding(sigs[isig], ctx.flags); err != nil {
+			return stack, err
+		}
+		if err := checkPubKeyEncoding(pubKeys[ikey], ctx.flags, ctx.sigVersion); err != nil {
+			return stack, err
+		}
+		if checkLegacySig(ctx, sigs[isig], pubKeys[ikey], scriptCode, verifyNow) {
+			isig++
+		}
+		ikey++
+		if m-isig > n-ikey {
+			success = false
+		}
+	}
+	if !success && ctx.flags&SCRIPT_VERIFY_NULLFAIL != 0 {
+		for _, sig := range sigs {
+			if len(sig) > 0 {
+				return stack, ErrSigNullFail
+			}
+		}
+	}
+	return append(stack, boolNum(success)), nil
+}
+
+func opcodeCheckMultisigVerify(stack [][]byte, ctx *execContext) ([][]byte, error) {
+	stack, err := opcodeCheckMultisig(stack, ctx)
+	if err != nil {
+		return stack, err
+	}
+	if stack, err = opcodeVerify(stack); err != nil {
+		return stack, ErrCheckMultisigVerify
+	}
+	return stack, nil
+}
@@ -0,0 +1,226 @@
+package script
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStackOpcodes(t *testing.T) {
+	a, b, c, d, e, f := []byte{0xa}, []byte{0xb}, []byte{0xc}, []byte{0xd}, []byte{0xe}, []byte{0xf}
+
+	testCases := []struct {
+		name  string
+		op    func([][]byte) ([][]byte, error)
+		stack [][]byte
+		want  [][]byte
+		ok    bool
+	}{
+		{"2SWAP", opcode2Swap, [][]byte{a, b, c, d}, [][]byte{c, d, a, b}, true},
+		{"2SWAP underflow", opcode2Swap, [][]byte{a, b, c}, nil, false},
+		{"2ROT", opcode2Rot, [][]byte{a, b, c, d, e, f}, [][]byte{c, d, e, f, a, b}, true},
+		{"ROT", opcodeRot, [][]byte{a, b, c}, [][]byte{b, c, a}, true},
+		{"ROLL 2", opcodeRoll, [][]byte{a, b, c, encodeNum(2)}, [][]byte{b, c, a}, true},
+		{"ROLL 0", opcodeRoll, [][]byte{a, b, encodeNum(0)}, [][]byte{a, b}, true},
+		{"ROLL too deep", opcodeRoll, [][]byte{a, b, encodeNum(2)}, nil, false},
+		{"ROLL negative", opcodeRoll, [][]byte{a, b, encodeNum(-1)}, nil, false},
+		{"PICK 1", opcodePick, [][]byte{a, b, encodeNum(1)}, [][]byte{a, b, a}, true},
+		{"PICK too deep", opcodePick, [][]byte{a, encodeNum(1)}, nil, false},
+		{"TUCK", opcodeTuck, [][]byte{a, b}, [][]byte{b, a, b}, true},
+		{"DEPTH empty", opcodeDepth, [][]byte{}, [][]byte{encodeNum(0)}, true},
+		{"SIZE empty", opcodeSize, [][]byte{}, nil, false},
+		{"IFDUP negative zero", opcodeIfDup, [][]byte{{0x80}}, [][]byte{{0x80}}, true},
+		{"EQUAL compares bytes", opcodeEqual, [][]byte{{0x01}, {0x01, 0x00}}, [][]byte{encodeNum(0)}, true},
+	}
+
+	for _, test := range testCases {
+		stack, err := test.op(test.stack)
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.ok, ok)
+			continue
+		}
+		if test.ok {
+			assert.Equal(t, test.want, stack, test.name)
+		}
+	}
+}
+
+func TestArithmeticOpcodes(t *testing.T) {
+	testCases := []struct {
+		name  string
+		op    func([][]byte) ([][]byte, error)
+		stack []int
+		want  int
+	}{
+		{"1ADD", opcode1Add, []int{-1}, 0},
+		{"1SUB", opcode1Sub, []int{0}, -1},
+		{"NEGATE", opcodeNegate, []int{5}, -5},
+		{"ABS", opcodeAbs, []int{-300}, 300},
+		{"NOT 0", opcodeNot, []int{0}, 1},
+		{"NOT 7", opcodeNot, []int{7}, 0},
+		{"0NOTEQUAL", opcode0NotEqual, []int{-7}, 1},
+		{"ADD", opcodeAdd, []int{0x7fffffff, 1}, 0x80000000},
+		{"SUB", opcodeSub, []int{3, 5}, -2},
+		{"BOOLAND", opcodeBoolAnd, []int{3, 0}, 0},
+		{"BOOLOR", opcodeBoolOr, []int{3, 0}, 1},
+		{"NUMEQUAL", opcodeNumEqual, []int{-1, -1}, 1},
+		{"NUMNOTEQUAL", opcodeNumNotEqual, []int{-1, -1}, 0},
+		{"LESSTHAN", opcodeLessThan, []int{-2, 1}, 1},
+		{"GREATERTHAN", opcodeGreaterThan, []int{-2, 1}, 0},
+		{"LESSTHANOREQUAL", opcodeLessThanOrEqual, []int{1, 1}, 1},
+		{"GREATERTHANOREQUAL", opcodeGreaterThanOrEqual, []int{0, 1}, 0},
+		{"MIN", opcodeMin, []int{4, -4}, -4},
+		{"MAX", opcodeMax, []int{4, -4}, 4},
+		{"WITHIN lower bound", opcodeWithin, []int{2, 2, 5}, 1},
+		{"WITHIN upper bound", opcodeWithin, []int{5, 2, 5}, 0},
+	}
+
+	for _, test := range testCases {
+		stack := [][]byte{}
+		for _, n := range test.stack {
+			stack = append(stack, encodeNum(n))
+		}
+		stack, err := test.op(stack)
+		if err != nil || len(stack) != 1 {
+			t.Errorf("%s: expected a single result but got '%v' (%v)", test.name, stack, err)
+			continue
+		}
+		if got := decodeNum(stack[0]); got != test.want {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.want, got)
+		}
+	}
+
+	// operands are limited to 4 bytes, results are not
+	stack, err := opcodeAdd([][]byte{encodeNum(0x7fffffff), encodeNum(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(stack[0]))
+	_, err = opcode1Add(stack)
+	assert.ErrorIs(t, err, ErrNumOverflow)
+}
+
+func TestEvaluateOpcodes(t *testing.T) {
+	testCases := []struct {
+		name string
+		cmds [][]byte
+		want bool
+	}{
+		// 2 3 ADD 5 NUMEQUAL
+		{"add", [][]byte{{0x52}, {0x53}, {0x93}, {0x55}, {0x9c}}, true},
+		// 2 3 SUB 1NEGATE NUMEQUALVERIFY 1
+		{"sub", [][]byte{{0x52}, {0x53}, {0x94}, {0x4f}, {0x9d}, {0x51}}, true},
+		// 1 2 3 ROT 1 NUMEQUAL
+		{"rot", [][]byte{{0x51}, {0x52}, {0x53}, {0x7b}, {0x51}, {0x9c}}, true},
+		// 0 NOT
+		{"not", [][]byte{{0x00}, {0x91}}, true},
+		// 2 3 MUL: disabled
+		{"mul", [][]byte{{0x52}, {0x53}, {0x95}}, false},
+		// 1 1 CAT: disabled
+		{"cat", [][]byte{{0x51}, {0x51}, {0x7e}}, false},
+		// 1 VER
+		{"ver", [][]byte{{0x51}, {0x62}}, false},
+		// 1 NOP1 NOP10
+		{"nops", [][]byte{{0x51}, {0xb0}, {0xb9}}, true},
+		// the top of the stack decides, not the bottom
+		{"false on top", [][]byte{{0x51}, {0x00}}, false},
+		{"negative zero", [][]byte{{0x00, 0x80}}, false},
+	}
+
+	for _, test := range testCases {
+		valid, _ := NewScript(nil).Combine(NewScript(test.cmds)).Evaluate(fixedSigHash(big.NewInt(1)), SCRIPT_VERIFY_NONE)
+		if valid != test.want {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.want, valid)
+		}
+	}
+}
+
+func TestEvaluateCheckSig(t *testing.T) {
+	z := encoding.FromHex("7c076ff316692a3d7eb3c3bb0f8b1488cf72e1afcd929e29307032997a838a3d")
+	keys := []*ecc.PrivateKey{ecc.NewPrivateKey(big.NewInt(1001)), ecc.NewPrivateKey(big.NewInt(1002)), ecc.NewPrivateKey(big.NewInt(1003))}
+	sigs := make([][]byte, len(keys))
+	for i, key := range keys {
+		sigs[i] = append(key.Sign(z).Der(), 0x01)
+	}
+	sec := func(i int) []byte {
+		return keys[i].PublicKey().Sec(true)
+	}
+	// OP_2 <k0> <k1> <k2> OP_3 OP_CHECKMULTISIG
+	multisig := [][]byte{{0x52}, sec(0), sec(1), sec(2), {0x53}, {0xae}}
+
+	testCases := []struct {
+		name         string
+		scriptSig    [][]byte
+		scriptPubKey [][]byte
+		want         bool
+	}{
+		{"checksig", [][]byte{sigs[0]}, [][]byte{sec(0), {0xac}}, true},
+		{"checksig wrong key", [][]byte{sigs[0]}, [][]byte{sec(1), {0xac}}, false},
+		{"checksig not", [][]byte{sigs[0]}, [][]byte{sec(1), {0xac}, {0x91}}, true},
+		{"checksigverify", [][]byte{sigs[0]}, [][]byte{sec(0), {0xad}, {0x51}}, true},
+		{"checksigverify wrong key", [][]byte{sigs[0]}, [][]byte{sec(1), {0xad}, {0x51}}, false},
+		{"multisig 0 and 2", [][]byte{{0x00}, sigs[0], sigs[2]}, multisig, true},
+		{"multisig 1 and 2", [][]byte{{0x00}, sigs[1], sigs[2]}, multisig, true},
+		{"multisig out of order", [][]byte{{0x00}, sigs[2], sigs[0]}, multisig, false},
+		{"multisig same sig twice", [][]byte{{0x00}, sigs[1], sigs[1]}, multisig, false},
+		{"multisigverify", [][]byte{{0x00}, sigs[0], sigs[1]}, append(multisig[:5:5], []byte{0xaf}, []byte{0x51}), true},
+	}
+
+	for _, test := range testCases {
+		combined := NewScript(test.scriptSig).Combine(NewScript(test.scriptPubKey))
+		valid, _ := combined.Evaluate(fixedSigHash(z), SCRIPT_VERIFY_NONE)
+		if valid != test.want {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.want, valid)
+		}
+	}
+}
+
+func TestEvaluateCodeSeparator(t *testing.T) {
+	z := big.NewInt(0xc0de)
+	key := ecc.NewPrivateKey(big.NewInt(1001))
+	sig := append(key.Sign(z).Der(), 0x01)
+	sec := key.PublicKey().Sec(true)
+	wantCode := NewScript([][]byte{sec, {0xac}}).RawSerialize()
+
+	testCases := [][][]byte{
+		// OP_CODESEPARATOR <key> OP_CHECKSIG
+		{{0xab}, sec, {0xac}},
+		// <key> OP_CHECKSIG OP_CODESEPARATOR, the separator after the
+		// signature check isn't signed either
+		{sec, {0xac}, {0xab}},
+		// OP_CODESEPARATOR <key> OP_CHECKSIG OP_CODESEPARATOR
+		{{0xab}, sec, {0xac}, {0xab}},
+	}
+
+	for _, cmds := range testCases {
+		scriptPubKey := NewScript(cmds)
+		var gotCode []byte
+		sigHash := func(hashType byte, scriptCode []byte, sigVersion SigVersion) (*big.Int, error) {
+			gotCode = scriptCode
+			return z, nil
+		}
+		valid, err := NewScript([][]byte{sig}).Combine(scriptPubKey).Evaluate(&InputContext{SigHash: sigHash}, SCRIPT_VERIFY_NONE)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+		if !bytes.Equal(gotCode, wantCode) {
+			t.Errorf("%s: expected '%x' but got '%x' instead", scriptPubKey, wantCode, gotCode)
+		}
+	}
+}
+
+func TestAltStackOpcodes(t *testing.T) {
+	stack, altStack, err := opcodeToAltStack([][]byte{{1}, {2}}, [][]byte{})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{1}}, stack)
+	assert.Equal(t, [][]byte{{2}}, altStack)
+
+	stack, altStack, err = opcodeFromAltStack(stack, altStack)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{1}, {2}}, stack)
+	assert.Equal(t, [][]byte{}, altStack)
+
+	_, _, err = opcodeFromAltStack(stack, altStack)
+	assert.ErrorIs(t, err, ErrAltStackUnderflow)
+}
@@ -0,0 +1,196 @@
+// Package script implements parsing, serialization and evaluation of Bitcoin
+// scripts.
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+// P2PKHScript takes in hashed public key and returns p2pkh scriptPubKey
+func P2PKHScript(hash []byte) *Script {
+	return &Script{
+		cmds: [][]byte{{0x76}, {0xa9}, hash, {0x88}, {0xac}},
+	}
+}
+
+// P2WPKHScript returns the segwit v0 scriptPubKey OP_0 <20 byte hash> for a
+// hashed public key
+func P2WPKHScript(hash []byte) *Script {
+	return &Script{
+		cmds: [][]byte{{0x00}, hash},
+	}
+}
+
+// P2WSHScript returns the segwit v0 scriptPubKey OP_0 <32 byte hash> for the
+// sha256 of a witness script
+func P2WSHScript(hash []byte) *Script {
+	return &Script{
+		cmds: [][]byte{{0x00}, hash},
+	}
+}
+
+// Script is a list of commands. Each command is either a single byte opcode
+// or an element to push onto the stack. A parsed script also keeps the bytes
+// it was parsed from, which is what gets serialized and run
+type Script struct {
+	cmds [][]byte
+	raw  []byte
+}
+
+// NewScript returns a script made of cmds
+func NewScript(cmds [][]byte) *Script {
+	return &Script{cmds: cmds}
+}
+
+// Cmds returns the commands of the script
+func (sc Script) Cmds() [][]byte {
+	return sc.cmds
+}
+
+// IsP2SH reports whether the script is OP_HASH160 <20 bytes> OP_EQUAL
+func (sc Script) IsP2SH() bool {
+	return sc.Type() == TX_SCRIPTHASH
+}
+
+// IsP2WPKH reports whether the script is OP_0 <20 bytes>
+func (sc Script) IsP2WPKH() bool {
+	return sc.Type() == TX_WITNESS_V0_KEYHASH
+}
+
+// IsP2WSH reports whether the script is OP_0 <32 bytes>
+func (sc Script) IsP2WSH() bool {
+	return sc.Type() == TX_WITNESS_V0_SCRIPTHASH
+}
+
+// Combine combines scripts (scriptSig + scriptPubKey) for evaluation
+func (sc Script) Combine(script *Script) *Script {
+	scriptBytes := make([][]byte, len(sc.cmds)+len(script.cmds))
+	count := 0
+	for i := len(script.cmds) - 1; i >= 0; i-- {
+		scriptBytes[count] = script.cmds[i]
+		count++
+	}
+	for i := len(sc.cmds) - 1; i >= 0; i-- {
+		scriptBytes[count] = sc.cmds[i]
+		count++
+	}
+	return &Script{cmds: scriptBytes}
+}
+
+// ParseScript parses a length prefixed script. A push that runs past the
+// end of the script is kept in the raw bytes and only fails when it is run,
+// like any other script the network accepts in an output
+func ParseScript(script io.Reader) (*Script, error) {
+	scriptLength, err := encoding.ReadVarint(script)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := encoding.ReadBytes(script, scriptLength)
+	if err != nil {
+		return nil, fmt.Errorf("parsing script failed: %v", err)
+	}
+
+	instructions, _ := parseInstructions(raw)
+	cmds := make([][]byte, len(instructions))
+	for i, ins := range instructions {
+		if ins.op > 0x00 && ins.op <= 0x4e {
+			cmds[i] = ins.data
+		} else {
+			cmds[i] = []byte{ins.op}
+		}
+	}
+	return &Script{cmds: cmds, raw: raw}, nil
+}
+
+// ParseRawScript parses a script without the length prefix
+func ParseRawScript(raw []byte) (*Script, error) {
+	length, err := encoding.EncodeVarint(len(raw))
+	if err != nil {
+		return nil, err
+	}
+	return ParseScript(bytes.NewReader(append(length, raw...)))
+}
+
+// RawSerialize serializes the script without the length prefix
+func (sc Script) RawSerialize() []byte {
+	if sc.raw != nil {
+		return sc.raw
+	}
+	return sc.rawSerializeCmds()
+}
+
+func (sc Script) rawSerializeCmds() []byte {
+	var result []byte
+
+	for _, cmd := range sc.cmds {
+		// bytes 0x01-0x4e can't stand alone as opcodes, they push data
+		if len(cmd) == 1 && (cmd[0] == 0x00 || cmd[0] > 0x4e) {
+			result = append(result, cmd[0])
+		} else {
+			// pushes longer than MAX_SCRIPT_ELEMENT_SIZE serialize fine but
+			// fail when they are run
+			result = append(result, pushData(cmd)...)
+		}
+	}
+	return result
+}
+
+// Serialize serializes the script with its length prefix
+func (sc Script) Serialize() []byte {
+	result := sc.RawSerialize()
+	resultLen := len(result)
+	// a slice length always fits in a varint
+	encodedLen, _ := encoding.EncodeVarint(resultLen)
+	return bytes.Join([][]byte{encodedLen, result}, []byte{})
+}
+
+// SigHasher returns the signature hash for hashType, the byte appended to
+// every signature, of the input being verified. scriptCode is the part of
+// the script the signature commits to and sigVersion says whether to use
+// the legacy or the BIP 143 digest
+type SigHasher func(hashType byte, scriptCode []byte, sigVersion SigVersion) (*big.Int, error)
+
+// sigChecker checks sig by pubKey over z for the signature opcodes
+type sigChecker func(pubKey *ecc.Point, sig *ecc.Signature, z *big.Int) bool
+
+func verifyNow(pubKey *ecc.Point, sig *ecc.Signature, z *big.Int) bool {
+	return pubKey.VerifySignature(*sig, z)
+}
+
+// Evaluate runs a script made with Combine as a single legacy script. input
+// describes the input being verified, which the signature and timelock
+// opcodes check against, and flags selects the optional rules to enforce.
+// Use VerifyScript to verify an input with P2SH and witness rules
+func (sc Script) Evaluate(input *InputContext, flags VerifyFlags) (bool, error) {
+	return sc.evaluate(newExecContext(input, flags, nil))
+}
+
+// EvaluateBatch runs the script assuming every OP_CHECKSIG passes and queues
+// the signatures on batch instead. A success only holds if batch.Verify()
+// succeeds afterwards and a failure may come from a script that needs a
+// signature to fail, so otherwise run Evaluate to get the exact answer
+func (sc Script) EvaluateBatch(input *InputContext, flags VerifyFlags, batch *ecc.BatchVerifier) (bool, error) {
+	return sc.evaluate(newExecContext(input, flags, batch))
+}
+
+func (sc Script) evaluate(ctx *execContext) (bool, error) {
+	// Combine keeps the commands in reverse
+	cmds := make([][]byte, len(sc.cmds))
+	for i, cmd := range sc.cmds {
+		cmds[len(cmds)-1-i] = cmd
+	}
+	stack, err := ctx.run(NewScript(cmds).RawSerialize(), [][]byte{}, SIGVERSION_BASE)
+	if err != nil {
+		return false, err
+	}
+	if len(stack) == 0 || !castToBool(stack[len(stack)-1]) {
+		return false, ErrEvalFalse
+	}
+	return true, nil
+}
@@ -0,0 +1,143 @@
+package script
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScript(t *testing.T) {
+	hexScriptPubKey, err := hex.DecodeString("6a47304402207899531a52d59a6de200179928ca900254a36b8dff8bb75f5f5d71b1cdc26125022008b422690b8461cb52c3cc30330b23d574351872b7c361e9aae3649071c1a7160121035d5c93d9ac96881f19ba1f686f15f009ded7c62efe85a872e6a19b43c15a2937")
+	if err != nil {
+		t.Error("error decoding scriptPubKey")
+	}
+	scriptBuf := bytes.NewBuffer(hexScriptPubKey)
+	scriptPubKey, err := ParseScript(scriptBuf)
+	if err != nil {
+		t.Error("error parsing script")
+	}
+
+	testCases := []struct {
+		cmd  []byte
+		want string
+	}{
+		{scriptPubKey.cmds[0], "304402207899531a52d59a6de200179928ca900254a36b8dff8bb75f5f5d71b1cdc26125022008b422690b8461cb52c3cc30330b23d574351872b7c361e9aae3649071c1a71601"},
+		{scriptPubKey.cmds[1], "035d5c93d9ac96881f19ba1f686f15f009ded7c62efe85a872e6a19b43c15a2937"},
+	}
+
+	for _, test := range testCases {
+		cmdString := hex.EncodeToString(test.cmd)
+		if cmdString != test.want {
+			t.Errorf("expected '%v' but got '%v' instead", test.want, cmdString)
+		}
+	}
+}
+
+func TestSerializeScript(t *testing.T) {
+	want := "6a47304402207899531a52d59a6de200179928ca900254a36b8dff8bb75f5f5d71b1cdc26125022008b422690b8461cb52c3cc30330b23d574351872b7c361e9aae3649071c1a7160121035d5c93d9ac96881f19ba1f686f15f009ded7c62efe85a872e6a19b43c15a2937"
+	scriptPubKey, err := hex.DecodeString(want)
+	if err != nil {
+		t.Error("error decoding target script")
+	}
+
+	script, err := ParseScript(bytes.NewBuffer(scriptPubKey))
+	if err != nil {
+		t.Error("error parsing script")
+	}
+	assert.Equal(t, want, hex.EncodeToString(script.Serialize()), "scripts serialized do not match")
+}
+
+// fixedSigHash is an input whose signature hash is z whatever the hash type
+func fixedSigHash(z *big.Int) *InputContext {
+	sigHash := func(byte, []byte, SigVersion) (*big.Int, error) {
+		return z, nil
+	}
+	return &InputContext{SigHash: sigHash}
+}
+
+func TestEvaluateSignatureFlags(t *testing.T) {
+	privKey := ecc.NewPrivateKey(big.NewInt(8675309))
+	z := encoding.FromHex("7c076ff316692a3d7eb3c3bb0f8b1488cf72e1afcd929e29307032997a838a3d")
+	sig := privKey.Sign(z)
+	highS := ecc.NewSignature(sig.R(), new(big.Int).Sub(ecc.N, sig.S()))
+
+	// R excessively padded with a zero byte
+	der := sig.Der()
+	padded := append([]byte{0x30, der[1] + 1, 0x02, der[3] + 1, 0x00}, der[4:]...)
+
+	scriptPubKey := NewScript([][]byte{privKey.PublicKey().Sec(true), {0xac}})
+
+	testCases := []struct {
+		sig   []byte
+		flags VerifyFlags
+		want  bool
+	}{
+		{sig.Der(), SCRIPT_VERIFY_NONE, true},
+		{sig.Der(), SCRIPT_VERIFY_DERSIG | SCRIPT_VERIFY_LOW_S, true},
+		{highS.Der(), SCRIPT_VERIFY_NONE, true},
+		{highS.Der(), SCRIPT_VERIFY_DERSIG, true},
+		{highS.Der(), SCRIPT_VERIFY_LOW_S, false},
+		{padded, SCRIPT_VERIFY_NONE, true},
+		{padded, SCRIPT_VERIFY_DERSIG, false},
+	}
+
+	for _, test := range testCases {
+		scriptSig := NewScript([][]byte{append(test.sig, 0x01)})
+		valid, _ := scriptSig.Combine(scriptPubKey).Evaluate(fixedSigHash(z), test.flags)
+		if valid != test.want {
+			t.Errorf("expected %v but got %v instead for flags %b", test.want, valid, test.flags)
+		}
+	}
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	privKey := ecc.NewPrivateKey(big.NewInt(8675309))
+	z := encoding.FromHex("7c076ff316692a3d7eb3c3bb0f8b1488cf72e1afcd929e29307032997a838a3d")
+	otherZ := new(big.Int).Add(z, big.NewInt(1))
+	scriptPubKey := NewScript([][]byte{privKey.PublicKey().Sec(true), {0xac}})
+	scriptSig := NewScript([][]byte{append(privKey.Sign(z).Der(), 0x01)})
+
+	testCases := []struct {
+		z    *big.Int
+		want bool
+	}{
+		{z, true},
+		{otherZ, false},
+	}
+
+	for _, test := range testCases {
+		batch := ecc.NewBatchVerifier(2, false)
+		valid, err := scriptSig.Combine(scriptPubKey).EvaluateBatch(fixedSigHash(test.z), SCRIPT_VERIFY_NONE, batch)
+		assert.NoError(t, err)
+		// the script assumes the signature is good until the batch runs
+		assert.True(t, valid)
+		assert.Equal(t, 1, batch.Len())
+		if got := batch.Verify(); got != test.want {
+			t.Errorf("expected '%v' but got '%v' instead", test.want, got)
+		}
+	}
+}
+
+func TestRawSerializeLongPush(t *testing.T) {
+	testCases := []struct {
+		length int
+		prefix string
+	}{
+		{75, "4b"},
+		{76, "4c4c"},
+		{520, "4d0802"},
+		{0x10000, "4e00000100"},
+	}
+
+	for _, test := range testCases {
+		got := NewScript([][]byte{make([]byte, test.length)}).RawSerialize()
+		if len(got) != test.length+len(test.prefix)/2 || hex.EncodeToString(got[:len(test.prefix)/2]) != test.prefix {
+			t.Errorf("%d: expected prefix '%v' but got '%x' instead", test.length, test.prefix, got[:len(test.prefix)/2])
+		}
+	}
+}
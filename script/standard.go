@@ -0,0 +1,209 @@
+package script
+
+import "fmt"
+
+// TxOutType is the standard template a scriptPubKey follows. The names
+// match Bitcoin Core's
+type TxOutType int
+
+const (
+	TX_NONSTANDARD TxOutType = iota
+	// <pubkey> OP_CHECKSIG
+	TX_PUBKEY
+	// OP_DUP OP_HASH160 <20 byte hash> OP_EQUALVERIFY OP_CHECKSIG
+	TX_PUBKEYHASH
+	// OP_HASH160 <20 byte hash> OP_EQUAL
+	TX_SCRIPTHASH
+	// OP_m <pubkey>... OP_n OP_CHECKMULTISIG
+	TX_MULTISIG
+	// OP_RETURN followed by pushes only
+	TX_NULL_DATA
+	// OP_0 <20 byte hash>
+	TX_WITNESS_V0_KEYHASH
+	// OP_0 <32 byte hash>
+	TX_WITNESS_V0_SCRIPTHASH
+	// OP_1 <32 byte x-only key>
+	TX_WITNESS_V1_TAPROOT
+	// a witness program of a version without a meaning yet
+	TX_WITNESS_UNKNOWN
+)
+
+var txOutTypeNames = map[TxOutType]string{
+	TX_NONSTANDARD:           "nonstandard",
+	TX_PUBKEY:                "pubkey",
+	TX_PUBKEYHASH:            "pubkeyhash",
+	TX_SCRIPTHASH:            "scripthash",
+	TX_MULTISIG:              "multisig",
+	TX_NULL_DATA:             "nulldata",
+	TX_WITNESS_V0_KEYHASH:    "witness_v0_keyhash",
+	TX_WITNESS_V0_SCRIPTHASH: "witness_v0_scripthash",
+	TX_WITNESS_V1_TAPROOT:    "witness_v1_taproot",
+	TX_WITNESS_UNKNOWN:       "witness_unknown",
+}
+
+func (t TxOutType) String() string {
+	if name, ok := txOutTypeNames[t]; ok {
+		return name
+	}
+	return "nonstandard"
+}
+
+// P2PKScript returns the scriptPubKey <pubkey> OP_CHECKSIG for a SEC public
+// key
+func P2PKScript(sec []byte) *Script {
+	return &Script{
+		cmds: [][]byte{sec, {0xac}},
+	}
+}
+
+// P2SHScript returns the scriptPubKey OP_HASH160 <20 byte hash> OP_EQUAL for
+// the hash160 of a redeem script
+func P2SHScript(hash []byte) *Script {
+	return &Script{
+		cmds: [][]byte{{0xa9}, hash, {0x87}},
+	}
+}
+
+// MultisigScript returns the bare m of n scriptPubKey
+// OP_m <pubkey>... OP_n OP_CHECKMULTISIG
+func MultisigScript(m int, pubKeys [][]byte) (*Script, error) {
+	n := len(pubKeys)
+	if n == 0 || n > 16 {
+		return nil, fmt.Errorf("multisig needs 1 to 16 keys, got %d", n)
+	}
+	if m < 1 || m > n {
+		return nil, fmt.Errorf("multisig needs 1 to %d signatures, got %d", n, m)
+	}
+	cmds := [][]byte{{byte(0x50 + m)}}
+	for _, pubKey := range pubKeys {
+		if !validPubKeySize(pubKey) {
+			return nil, fmt.Errorf("bad public key %x", pubKey)
+		}
+		cmds = append(cmds, pubKey)
+	}
+	cmds = append(cmds, []byte{byte(0x50 + n)}, []byte{0xae})
+	return &Script{cmds: cmds}, nil
+}
+
+// NullDataScript returns the unspendable scriptPubKey OP_RETURN <data>.
+// data is always pushed, even a single byte that reads as an opcode
+func NullDataScript(data []byte) *Script {
+	return &Script{
+		cmds: [][]byte{{0x6a}, data},
+		raw:  append([]byte{0x6a}, pushData(data)...),
+	}
+}
+
+// Type classifies the script as one of the standard templates
+func (sc Script) Type() TxOutType {
+	typ, _ := sc.Solve()
+	return typ
+}
+
+// Solve classifies the script and returns what the template embeds:
+//   - pubkey: the SEC public key
+//   - pubkeyhash, scripthash and segwit v0: the hash
+//   - taproot: the x-only output key
+//   - witness unknown: the version and the program
+//   - multisig: m, the public keys and n, with m and n as single bytes
+//
+// Null data and non-standard scripts return no solutions
+func (sc Script) Solve() (TxOutType, [][]byte) {
+	raw := sc.RawSerialize()
+
+	// p2sh comes first, it is checked the same way by consensus
+	if isP2SH(raw) {
+		return TX_SCRIPTHASH, [][]byte{raw[2:22]}
+	}
+	if version, program, ok := witnessProgram(raw); ok {
+		switch {
+		case version == 0 && len(program) == 20:
+			return TX_WITNESS_V0_KEYHASH, [][]byte{program}
+		case version == 0 && len(program) == 32:
+			return TX_WITNESS_V0_SCRIPTHASH, [][]byte{program}
+		case version == 1 && len(program) == 32:
+			return TX_WITNESS_V1_TAPROOT, [][]byte{program}
+		case version != 0:
+			return TX_WITNESS_UNKNOWN, [][]byte{{byte(version)}, program}
+		}
+		return TX_NONSTANDARD, nil
+	}
+	if len(raw) > 0 && raw[0] == 0x6a && isPushOnly(raw[1:]) {
+		return TX_NULL_DATA, nil
+	}
+
+	instructions, err := parseInstructions(raw)
+	if err != nil {
+		return TX_NONSTANDARD, nil
+	}
+	if pubKey, ok := matchP2PK(raw); ok {
+		return TX_PUBKEY, [][]byte{pubKey}
+	}
+	if len(raw) == 25 && raw[0] == 0x76 && raw[1] == 0xa9 && raw[2] == 0x14 && raw[23] == 0x88 && raw[24] == 0xac {
+		return TX_PUBKEYHASH, [][]byte{raw[3:23]}
+	}
+	if solutions, ok := matchMultisig(instructions); ok {
+		return TX_MULTISIG, solutions
+	}
+	return TX_NONSTANDARD, nil
+}
+
+func matchP2PK(raw []byte) ([]byte, bool) {
+	if len(raw) != 35 && len(raw) != 67 {
+		return nil, false
+	}
+	if int(raw[0]) != len(raw)-2 || raw[len(raw)-1] != 0xac {
+		return nil, false
+	}
+	pubKey := raw[1 : len(raw)-1]
+	return pubKey, validPubKeySize(pubKey)
+}
+
+// matchMultisig checks for OP_m <pubkey>... OP_n OP_CHECKMULTISIG with the
+// keys pushed minimally and 1 <= m <= n
+func matchMultisig(instructions []instruction) ([][]byte, bool) {
+	if len(instructions) < 4 || instructions[len(instructions)-1].op != 0xae {
+		return nil, false
+	}
+	first, last := instructions[0].op, instructions[len(instructions)-2].op
+	if first < 0x51 || first > 0x60 || last < 0x51 || last > 0x60 {
+		return nil, false
+	}
+	m, n := int(first)-0x50, int(last)-0x50
+	keys := instructions[1 : len(instructions)-2]
+	if len(keys) != n || m > n {
+		return nil, false
+	}
+	solutions := [][]byte{{byte(m)}}
+	for _, key := range keys {
+		if !validPubKeySize(key.data) || int(key.op) != len(key.data) {
+			return nil, false
+		}
+		solutions = append(solutions, key.data)
+	}
+	return append(solutions, []byte{byte(n)}), true
+}
+
+// validPubKeySize checks the length a SEC public key should have for its
+// first byte. It doesn't check that the key is on the curve
+func validPubKeySize(pubKey []byte) bool {
+	if len(pubKey) == 0 {
+		return false
+	}
+	switch pubKey[0] {
+	case 0x02, 0x03:
+		return len(pubKey) == 33
+	case 0x04, 0x06, 0x07:
+		return len(pubKey) == 65
+	}
+	return false
+}
+
+// Multisig returns m and the public keys of a bare multisig script
+func (sc Script) Multisig() (int, [][]byte, bool) {
+	typ, solutions := sc.Solve()
+	if typ != TX_MULTISIG {
+		return 0, nil, false
+	}
+	return int(solutions[0][0]), solutions[1 : len(solutions)-1], true
+}
@@ -0,0 +1,105 @@
+package script
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const genesisPubKey = "04678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5f"
+
+func TestSolve(t *testing.T) {
+	compressed := "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	testCases := []struct {
+		raw       string
+		typ       TxOutType
+		solutions []string
+	}{
+		{"41" + genesisPubKey + "ac", TX_PUBKEY, []string{genesisPubKey}},
+		{"21" + compressed + "ac", TX_PUBKEY, []string{compressed}},
+		{"76a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac", TX_PUBKEYHASH, []string{"bc3b654dca7e56b04dca18f2566cdaf02e8d9ada"}},
+		{"a914748284390f9e263a4b766a75d0633c50426eb87587", TX_SCRIPTHASH, []string{"748284390f9e263a4b766a75d0633c50426eb875"}},
+		{"5121" + compressed + "41" + genesisPubKey + "52ae", TX_MULTISIG, []string{"01", compressed, genesisPubKey, "02"}},
+		{"6a", TX_NULL_DATA, nil},
+		{"6a0b68656c6c6f20776f726c64", TX_NULL_DATA, nil},
+		{"0014751e76e8199196d454941c45d1b3a323f1433bd6", TX_WITNESS_V0_KEYHASH, []string{"751e76e8199196d454941c45d1b3a323f1433bd6"}},
+		{"00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", TX_WITNESS_V0_SCRIPTHASH, []string{"1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"}},
+		{"5120a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c", TX_WITNESS_V1_TAPROOT, []string{"a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c"}},
+		{"6002751e", TX_WITNESS_UNKNOWN, []string{"10", "751e"}},
+		// not standard
+		{"0010751e76e8199196d454941c45d1b3a323", TX_NONSTANDARD, nil},
+		{"76a94c14bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac", TX_NONSTANDARD, nil},
+		{"a94c14748284390f9e263a4b766a75d0633c50426eb87587", TX_NONSTANDARD, nil},
+		{"5221" + compressed + "51ae", TX_NONSTANDARD, nil},
+		{"5121" + compressed + "52ae", TX_NONSTANDARD, nil},
+		{"0021" + compressed + "51ae", TX_NONSTANDARD, nil},
+		{"2105" + compressed[2:] + "ac", TX_NONSTANDARD, nil},
+		{"6a76", TX_NONSTANDARD, nil},
+		{"", TX_NONSTANDARD, nil},
+	}
+
+	for _, test := range testCases {
+		typ, solutions := (&Script{raw: fromHex(test.raw)}).Solve()
+		if typ != test.typ {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.raw, test.typ, typ)
+			continue
+		}
+		var got []string
+		for _, solution := range solutions {
+			got = append(got, hex.EncodeToString(solution))
+		}
+		assert.Equal(t, test.solutions, got, test.raw)
+	}
+}
+
+func TestTemplateConstructors(t *testing.T) {
+	pubKey := fromHex(genesisPubKey)
+	hash := fromHex("bc3b654dca7e56b04dca18f2566cdaf02e8d9ada")
+	multisig, err := MultisigScript(1, [][]byte{pubKey, pubKey})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		script *Script
+		typ    TxOutType
+	}{
+		{P2PKScript(pubKey), TX_PUBKEY},
+		{P2PKHScript(hash), TX_PUBKEYHASH},
+		{P2SHScript(hash), TX_SCRIPTHASH},
+		{multisig, TX_MULTISIG},
+		{NullDataScript([]byte("hello world")), TX_NULL_DATA},
+		{NullDataScript([]byte{0x00}), TX_NULL_DATA},
+		{NullDataScript([]byte{0x76}), TX_NULL_DATA},
+		{NullDataScript([]byte{0xff}), TX_NULL_DATA},
+		{P2WPKHScript(hash), TX_WITNESS_V0_KEYHASH},
+		{P2WSHScript(bytes.Repeat([]byte{1}, 32)), TX_WITNESS_V0_SCRIPTHASH},
+		{P2TRScript(bytes.Repeat([]byte{1}, 32)), TX_WITNESS_V1_TAPROOT},
+	}
+
+	for _, test := range testCases {
+		if typ := test.script.Type(); typ != test.typ {
+			t.Errorf("expected '%v' but got '%v' instead", test.typ, typ)
+		}
+	}
+
+	// one byte data is pushed, not written as an opcode
+	for _, data := range []string{"00", "76", "ff"} {
+		want := fromHex("6a01" + data)
+		if raw := NullDataScript(fromHex(data)).RawSerialize(); !bytes.Equal(raw, want) {
+			t.Errorf("expected '%x' but got '%x' instead", want, raw)
+		}
+	}
+
+	m, pubKeys, ok := multisig.Multisig()
+	assert.True(t, ok)
+	assert.Equal(t, 1, m)
+	assert.Equal(t, [][]byte{pubKey, pubKey}, pubKeys)
+
+	_, err = MultisigScript(3, [][]byte{pubKey, pubKey})
+	assert.Error(t, err)
+	_, err = MultisigScript(1, [][]byte{hash})
+	assert.Error(t, err)
+	_, err = MultisigScript(1, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,316 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+const (
+	// TAPROOT_LEAF_TAPSCRIPT is the leaf version of BIP 342 scripts
+	TAPROOT_LEAF_TAPSCRIPT = 0xc0
+	TAPROOT_LEAF_MASK      = 0xfe
+
+	// TAPROOT_ANNEX_TAG is the first byte of the optional last witness item
+	TAPROOT_ANNEX_TAG = 0x50
+
+	taprootControlBaseSize = 33
+	taprootControlNodeSize = 32
+	taprootControlMaxNodes = 128
+
+	// every signature checked in a tapscript uses up this much of the
+	// sigops budget, which starts at 50 + the size of the witness
+	tapscriptSigopCost   = 50
+	tapscriptBudgetBonus = 50
+)
+
+// P2TRScript returns the segwit v1 scriptPubKey OP_1 <32 byte x-only key>
+func P2TRScript(outputKey []byte) *Script {
+	return &Script{
+		cmds: [][]byte{{0x51}, outputKey},
+	}
+}
+
+// IsP2TR reports whether the script is OP_1 <32 bytes>
+func (sc Script) IsP2TR() bool {
+	return sc.Type() == TX_WITNESS_V1_TAPROOT
+}
+
+// TapLeafHash is tagged_hash("TapLeaf", leafVersion || compact_size(script) || script)
+func TapLeafHash(leafVersion byte, leafScript []byte) []byte {
+	scriptLen, _ := encoding.EncodeVarint(len(leafScript))
+	hash := encoding.TaggedHash("TapLeaf", []byte{leafVersion}, scriptLen, leafScript)
+	return hash[:]
+}
+
+// TapBranchHash hashes two nodes of the script tree, smallest first
+func TapBranchHash(a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	hash := encoding.TaggedHash("TapBranch", a, b)
+	return hash[:]
+}
+
+// ControlBlock is the last witness item of a taproot script path spend. It
+// proves that the leaf script is committed to in the output key
+type ControlBlock struct {
+	leafVersion  byte
+	outputKeyOdd bool
+	internalKey  *ecc.Point
+	path         [][]byte
+}
+
+// validControlBlockSize reports whether size fits the control byte, the
+// internal key and up to 128 merkle path hashes
+func validControlBlockSize(size int) bool {
+	return size >= taprootControlBaseSize &&
+		size <= taprootControlBaseSize+taprootControlMaxNodes*taprootControlNodeSize &&
+		(size-taprootControlBaseSize)%taprootControlNodeSize == 0
+}
+
+// ParseControlBlock parses the leaf version and output key parity byte, the
+// 32 byte internal key and up to 128 merkle path hashes
+func ParseControlBlock(b []byte) (*ControlBlock, error) {
+	if !validControlBlockSize(len(b)) {
+		return nil, fmt.Errorf("bad control block length %d", len(b))
+	}
+	numNodes := (len(b) - taprootControlBaseSize) / taprootControlNodeSize
+	internalKey, err := ecc.ParseXOnlyPubKey(b[1:taprootControlBaseSize])
+	if err != nil {
+		return nil, fmt.Errorf("bad internal key: %v", err)
+	}
+	path := make([][]byte, numNodes)
+	for i := range path {
+		start := taprootControlBaseSize + i*taprootControlNodeSize
+		path[i] = b[start : start+taprootControlNodeSize]
+	}
+	return &ControlBlock{
+		leafVersion:  b[0] & TAPROOT_LEAF_MASK,
+		outputKeyOdd: b[0]&1 == 1,
+		internalKey:  internalKey,
+		path:         path,
+	}, nil
+}
+
+func (cb ControlBlock) LeafVersion() byte {
+	return cb.leafVersion
+}
+
+func (cb ControlBlock) InternalKey() *ecc.Point {
+	return cb.internalKey
+}
+
+// MerkleRoot hashes leafHash up the merkle path
+func (cb ControlBlock) MerkleRoot(leafHash []byte) []byte {
+	k := leafHash
+	for _, node := range cb.path {
+		k = TapBranchHash(k, node)
+	}
+	return k
+}
+
+// Verify reports whether leafScript is committed to in the x-only outputKey
+// and returns its leaf hash
+func (cb ControlBlock) Verify(outputKey []byte, leafScript []byte) ([]byte, bool) {
+	leafHash := TapLeafHash(cb.leafVersion, leafScript)
+	q, err := ecc.TaprootOutputKey(cb.internalKey, cb.MerkleRoot(leafHash))
+	if err != nil {
+		return nil, false
+	}
+	if !bytes.Equal(q.XOnly(), outputKey) || q.Y().Num().Bit(0) == 1 != cb.outputKeyOdd {
+		return nil, false
+	}
+	return leafHash, true
+}
+
+// TapSigHasher returns the BIP 341 signature hash for hashType of the input
+// being verified. annex is nil if the witness has none, leafHash is the
+// tapleaf hash of the script being run and nil for key path spends, and
+// codeSepPos is the opcode position of the last OP_CODESEPARATOR run or
+// 0xffffffff
+type TapSigHasher func(hashType byte, annex, leafHash []byte, codeSepPos uint32) ([]byte, error)
+
+type tapscriptContext struct {
+	annex        []byte
+	leafHash     []byte
+	sigopsBudget int
+	codeSepPos   uint32
+}
+
+// verifyTaproot checks the witness of a segwit v1 output, whose program is
+// the x-only output key. A single witness item (after taking out the annex)
+// is a key path signature, otherwise the last two items are the control
+// block and the leaf script
+func (ctx *execContext) verifyTaproot(outputKey []byte, witness [][]byte) error {
+	if len(witness) == 0 {
+		return ErrWitnessProgramWitnessEmpty
+	}
+	witnessSize := serializedWitnessSize(witness)
+	var annex []byte
+	if last := witness[len(witness)-1]; len(witness) >= 2 && len(last) > 0 && last[0] == TAPROOT_ANNEX_TAG {
+		annex = last
+		witness = witness[:len(witness)-1]
+	}
+
+	if len(witness) == 1 {
+		return ctx.checkSchnorr(witness[0], outputKey, annex, nil, 0xffffffff)
+	}
+
+	control := witness[len(witness)-1]
+	if !validControlBlockSize(len(control)) {
+		return fmt.Errorf("%w: %d bytes", ErrTaprootWrongControlSize, len(control))
+	}
+	// with the right size only a bad internal key is left to fail, which
+	// like a wrong merkle path means the output key isn't committed to
+	controlBlock, err := ParseControlBlock(control)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrWitnessProgramMismatch, err)
+	}
+	leafScript := witness[len(witness)-2]
+	leafHash, ok := controlBlock.Verify(outputKey, leafScript)
+	if !ok {
+		return fmt.Errorf("%w: control block does not commit to the output key", ErrWitnessProgramMismatch)
+	}
+	if controlBlock.LeafVersion() != TAPROOT_LEAF_TAPSCRIPT {
+		// unknown leaf versions are left for future soft forks
+		if ctx.flags&SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_TAPROOT_VERSION != 0 {
+			return fmt.Errorf("%w: 0x%02x", ErrDiscourageUpgradableTaprootVersion, controlBlock.LeafVersion())
+		}
+		return nil
+	}
+
+	ctx.tapscript = &tapscriptContext{
+		annex:        annex,
+		leafHash:     leafHash,
+		sigopsBudget: tapscriptBudgetBonus + witnessSize,
+		codeSepPos:   0xffffffff,
+	}
+	defer func() { ctx.tapscript = nil }()
+	return ctx.executeWitnessScript(leafScript, witness[:len(witness)-2], SIGVERSION_TAPSCRIPT)
+}
+
+// isOpSuccess reports whether op is one of the OP_SUCCESSx opcodes that make
+// a tapscript succeed unconditionally (BIP 342)
+func isOpSuccess(op byte) bool {
+	return op == 80 || op == 98 || (op >= 126 && op <= 129) || (op >= 131 && op <= 134) ||
+		(op >= 137 && op <= 138) || (op >= 141 && op <= 142) || (op >= 149 && op <= 153) ||
+		(op >= 187 && op <= 254)
+}
+
+// hasOpSuccess walks the raw script looking for an OP_SUCCESSx opcode. It
+// fails if a push runs past the end of the script before one is found
+func hasOpSuccess(raw []byte) (bool, error) {
+	instructions, err := parseInstructions(raw)
+	for _, ins := range instructions {
+		if isOpSuccess(ins.op) {
+			return true, nil
+		}
+	}
+	return false, err
+}
+
+// checkSchnorr checks a BIP 340 signature by the x-only pubKey over the
+// taproot signature hash. A 64 byte signature uses SIGHASH_DEFAULT, a 65
+// byte one carries its hash type, which must not be 0 then
+func (ctx *execContext) checkSchnorr(sig, pubKey, annex, leafHash []byte, codeSepPos uint32) error {
+	var hashType byte
+	switch {
+	case len(sig) == 64:
+	case len(sig) == 65 && sig[64] != 0x00:
+		hashType = sig[64]
+		sig = sig[:64]
+	default:
+		return fmt.Errorf("%w: %d bytes", ErrSchnorrSigSize, len(sig))
+	}
+	if ctx.input == nil || ctx.input.TapSigHash == nil {
+		return ErrSigCheck
+	}
+
+	point, err := ecc.ParseXOnlyPubKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSchnorrSig, err)
+	}
+	schnorrSig, err := ecc.ParseSchnorrSignature(sig)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSchnorrSig, err)
+	}
+	msg, err := ctx.input.TapSigHash(hashType, annex, leafHash, codeSepPos)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSchnorrSigHashType, err)
+	}
+	if ctx.batch != nil {
+		ctx.batch.AddSchnorr(point, *schnorrSig, msg)
+		return nil
+	}
+	if !point.VerifySchnorr(*schnorrSig, msg) {
+		return ErrSchnorrSig
+	}
+	return nil
+}
+
+// checkTapscriptSig checks a tapscript signature (BIP 342). An empty
+// signature is false, a non-empty one must be valid. Keys that are not 32
+// bytes are unknown key types and any non-empty signature passes for them
+func (ctx *execContext) checkTapscriptSig(sig, pubKey []byte) (bool, error) {
+	if len(pubKey) == 0 {
+		return false, ErrTapscriptEmptyPubKey
+	}
+	if len(sig) == 0 {
+		return false, nil
+	}
+	ctx.tapscript.sigopsBudget -= tapscriptSigopCost
+	if ctx.tapscript.sigopsBudget < 0 {
+		return false, ErrTapscriptValidationWeight
+	}
+	if len(pubKey) != 32 {
+		if ctx.flags&SCRIPT_VERIFY_DISCOURAGE_UPGRADABLE_PUBKEYTYPE != 0 {
+			return false, fmt.Errorf("%w: %d bytes", ErrDiscourageUpgradablePubKeyType, len(pubKey))
+		}
+		return true, nil
+	}
+	if err := ctx.checkSchnorr(sig, pubKey, ctx.tapscript.annex, ctx.tapscript.leafHash, ctx.tapscript.codeSepPos); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func opcodeChecksigTapscript(stack [][]byte, ctx *execContext) ([][]byte, error) {
+	if len(stack) < 2 {
+		return stack, ErrStackUnderflow
+	}
+	pubKey, stack := pop(stack)
+	sig, stack := pop(stack)
+	valid, err := ctx.checkTapscriptSig(sig, pubKey)
+	if err != nil {
+		return stack, err
+	}
+	return append(stack, boolNum(valid)), nil
+}
+
+// opcodeChecksigAdd is OP_CHECKSIGADD: pops pubkey, n and sig and pushes n+1
+// if sig is valid or n if it is empty. It only exists in tapscript
+func opcodeChecksigAdd(stack [][]byte, ctx *execContext) ([][]byte, error) {
+	if ctx.tapscript == nil {
+		return stack, ErrBadOpcode
+	}
+	if len(stack) < 3 {
+		return stack, ErrStackUnderflow
+	}
+	pubKey, stack := pop(stack)
+	n, stack, err := ctx.popNum(stack)
+	if err != nil {
+		return stack, err
+	}
+	sig, stack := pop(stack)
+	valid, err := ctx.checkTapscriptSig(sig, pubKey)
+	if err != nil {
+		return stack, err
+	}
+	if valid {
+		n++
+	}
+	return append(stack, encodeNum(n)), nil
+}
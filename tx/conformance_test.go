@@ -0,0 +1,466 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/script"
+)
+
+// The conformance tests run Bitcoin Core's script_tests.json, tx_valid.json
+// and tx_invalid.json, copied unchanged from src/test/data of the v0.21.0
+// tag:
+//
+//	script_tests.json  sha256 399914845dbd50e9af4d798990895bed525f49768517e997f1f347f21c00c670
+//	tx_valid.json      sha256 97f4b2efdcbd18680ff423db65caa8b23f9219c3e3efc293bb9a4ea112e5cc35
+//	tx_invalid.json    sha256 5e6c923c549c4ac8f8335e48576bf4c61074f9f755190d1ccb54954a32d82696
+//
+// v0.21.0 has no taproot script vectors yet, script_tests_sample.json adds
+// some in the format later versions use. Every vector must get Core's result
+// except the ones in knownFailures
+
+// knownFailures are the vectors that don't get Core's result yet, by file
+// and line, with the reason. A listed vector that passes fails the test too,
+// so the list can't go stale
+var knownFailures = map[string]string{
+	// signatures that only parse with Core's lax DER parser, which applies
+	// without DERSIG
+	"script_tests.json:1638": "multi-byte hash type without DERSIG needs lax DER parsing",
+	"tx_valid.json:18":       "extra byte stuffed in the signature needs lax DER parsing",
+	"tx_valid.json:239":      "non-standard DER signature needs lax DER parsing",
+
+	// hybrid keys (0x06 and 0x07 prefixes) are valid without STRICTENC
+	"script_tests.json:1666": "hybrid public keys aren't parsed",
+	"script_tests.json:1680": "hybrid public keys aren't parsed",
+
+	// coinbase inputs spend the null outpoint, which can't be faked
+	"tx_valid.json:84": "coinbase input",
+	"tx_valid.json:89": "coinbase input",
+
+	// txs Core rejects in CheckTransaction, before running any script
+	"tx_invalid.json:38": "negative output value isn't checked",
+	"tx_invalid.json:50": "duplicate inputs aren't checked",
+	"tx_invalid.json:55": "coinbase scriptSig size isn't checked",
+	"tx_invalid.json:60": "coinbase scriptSig size isn't checked",
+	"tx_invalid.json:64": "null prevout outside a coinbase isn't checked",
+	"tx_invalid.json:67": "null prevout outside a coinbase isn't checked",
+}
+
+func TestScriptTests(t *testing.T) {
+	for _, file := range []string{"script_tests.json", "script_tests_sample.json"} {
+		t.Run(file, func(t *testing.T) {
+			runVectors(t, file, runScriptTest)
+		})
+	}
+}
+
+func TestTxValid(t *testing.T) {
+	runVectors(t, "tx_valid.json", func(fields []json.RawMessage) (bool, error) {
+		return runTxTest(fields, true)
+	})
+}
+
+func TestTxInvalid(t *testing.T) {
+	runVectors(t, "tx_invalid.json", func(fields []json.RawMessage) (bool, error) {
+		return runTxTest(fields, false)
+	})
+}
+
+// runVectors runs every vector of file through run, which reports whether
+// the vector got the expected result. Single element entries are comments
+func runVectors(t *testing.T, file string, run func([]json.RawMessage) (bool, error)) {
+	data, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatalf("error reading %s: %v", file, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		t.Fatalf("error decoding %s: %v", file, err)
+	}
+
+	passed, total := 0, 0
+	for dec.More() {
+		// the vector starts after the separator and the spaces before it
+		start := int(dec.InputOffset())
+		start += len(data[start:]) - len(bytes.TrimLeft(data[start:], ", \t\r\n"))
+		name := fmt.Sprintf("%s:%d", file, bytes.Count(data[:start], []byte("\n"))+1)
+
+		var entry []json.RawMessage
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("error decoding %s: %v", name, err)
+		}
+		if len(entry) == 1 {
+			continue
+		}
+		total++
+		ok, err := run(entry)
+		reason, known := knownFailures[name]
+		switch {
+		case ok && known:
+			t.Errorf("%s passes now, remove it from knownFailures", name)
+		case ok:
+			passed++
+		case known:
+			t.Logf("known failure %s: %s", name, reason)
+		case err != nil:
+			t.Errorf("%s failed: %v", name, err)
+		default:
+			t.Errorf("%s failed", name)
+		}
+	}
+	t.Logf("%s: %d of %d vectors pass", file, passed, total)
+}
+
+// runScriptTest runs a script_tests.json vector:
+// [[wit..., amount]?, scriptSig, scriptPubKey, flags, expected_scripterror, comments...]
+// in a spending tx built like Bitcoin Core does
+func runScriptTest(fields []json.RawMessage) (bool, error) {
+	var witness [][]byte
+	var amount uint64
+	var tapOutput []byte
+	if len(fields) > 0 && strings.HasPrefix(string(fields[0]), "[") {
+		var wit []interface{}
+		if err := json.Unmarshal(fields[0], &wit); err != nil || len(wit) == 0 {
+			return false, fmt.Errorf("bad witness: %v", err)
+		}
+		for _, item := range wit[:len(wit)-1] {
+			element, ok := item.(string)
+			if !ok {
+				return false, errors.New("bad witness element")
+			}
+			switch {
+			case strings.HasPrefix(element, "#SCRIPT#"):
+				raw, err := parseTestScript(strings.TrimPrefix(element, "#SCRIPT#"))
+				if err != nil {
+					return false, err
+				}
+				witness = append(witness, raw)
+			case element == "#CONTROLBLOCK#":
+				if len(witness) == 0 {
+					return false, errors.New("control block without a script")
+				}
+				controlBlock, outputKey, err := testTaprootLeaf(witness[len(witness)-1])
+				if err != nil {
+					return false, err
+				}
+				witness = append(witness, controlBlock)
+				tapOutput = outputKey
+			default:
+				b, err := hex.DecodeString(element)
+				if err != nil {
+					return false, err
+				}
+				witness = append(witness, b)
+			}
+		}
+		btc, ok := wit[len(wit)-1].(float64)
+		if !ok {
+			return false, errors.New("bad witness amount")
+		}
+		amount = uint64(math.Round(btc * 1e8))
+		fields = fields[1:]
+	}
+	if len(fields) < 4 {
+		return false, errors.New("bad test")
+	}
+	var scriptSigAsm, scriptPubKeyAsm, flagNames, expected string
+	for i, s := range []*string{&scriptSigAsm, &scriptPubKeyAsm, &flagNames, &expected} {
+		if err := json.Unmarshal(fields[i], s); err != nil {
+			return false, err
+		}
+	}
+	if tapOutput != nil {
+		scriptPubKeyAsm = strings.Replace(scriptPubKeyAsm, "#TAPROOTOUTPUT#", "0x"+hex.EncodeToString(tapOutput), 1)
+	}
+
+	scriptSig, err := parseTestScriptSig(scriptSigAsm)
+	if err != nil {
+		return false, err
+	}
+	scriptPubKey, err := parseTestScriptSig(scriptPubKeyAsm)
+	if err != nil {
+		return false, err
+	}
+	flags, err := script.ParseVerifyFlags(flagNames)
+	if err != nil {
+		return false, err
+	}
+	// CLEANSTACK only makes sense with P2SH and WITNESS
+	if flags&script.SCRIPT_VERIFY_CLEANSTACK != 0 {
+		flags |= script.SCRIPT_VERIFY_P2SH | script.SCRIPT_VERIFY_WITNESS
+	}
+
+	// the crediting tx pays amount to scriptPubKey and the spending tx
+	// spends it to an empty script
+	credit := NewTx(1, []TxIn{*NewTxIn([32]byte{}, 0xffffffff, script.NewScript([][]byte{{0x00}, {0x00}}), 0xffffffff)},
+		[]TxOut{*NewTxOut(amount, scriptPubKey)}, 0, false)
+	var creditId [32]byte
+	copy(creditId[:], credit.ID())
+	txCache[hex.EncodeToString(creditId[:])] = credit
+	spend := NewTx(1, []TxIn{*NewTxIn(creditId, 0, scriptSig, 0xffffffff)}, []TxOut{*NewTxOut(amount, script.NewScript(nil))}, 0, false)
+	spend.txIns[0].SetWitness(witness)
+
+	valid, err := spend.VerifyInputFlags(0, flags)
+	if valid != (expected == "OK") {
+		return false, err
+	}
+	// failures must be for the expected reason
+	if want := scriptErrorNames[expected]; want != nil && !errors.Is(err, want) {
+		return false, fmt.Errorf("expected %s: %v", expected, err)
+	}
+	return true, nil
+}
+
+// scriptErrorNames are the script errors Core's vectors expect. Errors
+// missing here, like UNKNOWN_ERROR, only need the script to fail
+var scriptErrorNames = map[string]error{
+	"EVAL_FALSE":                            script.ErrEvalFalse,
+	"OP_RETURN":                             script.ErrOpReturn,
+	"SCRIPT_SIZE":                           script.ErrScriptSize,
+	"PUSH_SIZE":                             script.ErrPushSize,
+	"OP_COUNT":                              script.ErrOpCount,
+	"STACK_SIZE":                            script.ErrStackSize,
+	"SIG_COUNT":                             script.ErrSigCount,
+	"PUBKEY_COUNT":                          script.ErrPubKeyCount,
+	"VERIFY":                                script.ErrVerify,
+	"EQUALVERIFY":                           script.ErrEqualVerify,
+	"CHECKMULTISIGVERIFY":                   script.ErrCheckMultisigVerify,
+	"CHECKSIGVERIFY":                        script.ErrCheckSigVerify,
+	"NUMEQUALVERIFY":                        script.ErrNumEqualVerify,
+	"BAD_OPCODE":                            script.ErrBadOpcode,
+	"DISABLED_OPCODE":                       script.ErrDisabledOpcode,
+	"INVALID_STACK_OPERATION":               script.ErrStackUnderflow,
+	"INVALID_ALTSTACK_OPERATION":            script.ErrAltStackUnderflow,
+	"UNBALANCED_CONDITIONAL":                script.ErrUnbalancedConditional,
+	"NEGATIVE_LOCKTIME":                     script.ErrNegativeLockTime,
+	"UNSATISFIED_LOCKTIME":                  script.ErrUnsatisfiedLockTime,
+	"SIG_HASHTYPE":                          script.ErrSigHashType,
+	"SIG_DER":                               script.ErrSigDer,
+	"MINIMALDATA":                           script.ErrMinimalData,
+	"SIG_PUSHONLY":                          script.ErrSigPushOnly,
+	"SIG_HIGH_S":                            script.ErrSigHighS,
+	"SIG_NULLDUMMY":                         script.ErrSigNullDummy,
+	"PUBKEYTYPE":                            script.ErrPubKeyType,
+	"CLEANSTACK":                            script.ErrCleanStack,
+	"MINIMALIF":                             script.ErrMinimalIf,
+	"NULLFAIL":                              script.ErrSigNullFail,
+	"DISCOURAGE_UPGRADABLE_NOPS":            script.ErrDiscourageUpgradableNops,
+	"DISCOURAGE_UPGRADABLE_WITNESS_PROGRAM": script.ErrDiscourageUpgradableWitnessProgram,
+	"WITNESS_PROGRAM_WRONG_LENGTH":          script.ErrWitnessProgramWrongLength,
+	"WITNESS_PROGRAM_WITNESS_EMPTY":         script.ErrWitnessProgramWitnessEmpty,
+	"WITNESS_PROGRAM_MISMATCH":              script.ErrWitnessProgramMismatch,
+	"WITNESS_MALLEATED":                     script.ErrWitnessMalleated,
+	"WITNESS_MALLEATED_P2SH":                script.ErrWitnessMalleatedP2SH,
+	"WITNESS_UNEXPECTED":                    script.ErrWitnessUnexpected,
+	"WITNESS_PUBKEYTYPE":                    script.ErrWitnessPubKeyType,
+	"OP_CODESEPARATOR":                      script.ErrOpCodeSeparator,
+	"SIG_FINDANDDELETE":                     script.ErrSigFindAndDelete,
+	"SCHNORR_SIG":                           script.ErrSchnorrSig,
+	"SCHNORR_SIG_SIZE":                      script.ErrSchnorrSigSize,
+	"SCHNORR_SIG_HASHTYPE":                  script.ErrSchnorrSigHashType,
+	"TAPROOT_WRONG_CONTROL_SIZE":            script.ErrTaprootWrongControlSize,
+	"TAPSCRIPT_VALIDATION_WEIGHT":           script.ErrTapscriptValidationWeight,
+	"TAPSCRIPT_CHECKMULTISIG":               script.ErrTapscriptCheckMultisig,
+	"DISCOURAGE_OP_SUCCESS":                 script.ErrDiscourageOpSuccess,
+	"DISCOURAGE_UPGRADABLE_TAPROOT_VERSION": script.ErrDiscourageUpgradableTaprootVersion,
+	"DISCOURAGE_UPGRADABLE_PUBKEYTYPE":      script.ErrDiscourageUpgradablePubKeyType,
+}
+
+// runTxTest runs a tx_valid.json or tx_invalid.json vector:
+// [[[prevout hash, prevout index, prevout scriptPubKey, amount?], ...], tx, flags]
+// Valid txs must pass every input with flags applied and invalid txs must
+// fail one of them
+func runTxTest(fields []json.RawMessage, valid bool) (bool, error) {
+	if len(fields) < 3 {
+		return false, errors.New("bad test")
+	}
+	var prevouts [][]interface{}
+	var txHex, flagNames string
+	if err := json.Unmarshal(fields[0], &prevouts); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(fields[1], &txHex); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(fields[2], &flagNames); err != nil {
+		return false, err
+	}
+
+	flags, err := script.ParseVerifyFlags(flagNames)
+	if err != nil {
+		return false, err
+	}
+
+	// fake previous txs with the outputs being spent
+	prevTxs := map[string]*Tx{}
+	for _, prevout := range prevouts {
+		if len(prevout) < 3 {
+			return false, errors.New("bad prevout")
+		}
+		hash, _ := prevout[0].(string)
+		index, _ := prevout[1].(float64)
+		scriptAsm, _ := prevout[2].(string)
+		// the fake previous tx has every output up to index
+		if index < 0 || index > math.MaxUint16 {
+			return false, fmt.Errorf("unsupported prevout index %v", index)
+		}
+		var amount uint64
+		if len(prevout) > 3 {
+			value, _ := prevout[3].(float64)
+			amount = uint64(value)
+		}
+		scriptPubKey, err := parseTestScriptSig(scriptAsm)
+		if err != nil {
+			return false, err
+		}
+		prev, ok := prevTxs[hash]
+		if !ok {
+			prev = NewTx(1, nil, nil, 0, false)
+			prevTxs[hash] = prev
+		}
+		for len(prev.txOuts) <= int(index) {
+			prev.txOuts = append(prev.txOuts, *NewTxOut(0, script.NewScript(nil)))
+		}
+		prev.txOuts[int(index)] = *NewTxOut(amount, scriptPubKey)
+	}
+	for hash, prev := range prevTxs {
+		txCache[hash] = prev
+	}
+
+	raw, err := hex.DecodeString(txHex)
+	if err != nil {
+		return false, err
+	}
+	tx, err := ParseTx(raw)
+	if err != nil {
+		return !valid, err
+	}
+	for i := range tx.txIns {
+		ok, err := tx.VerifyInputFlags(uint32(i), flags)
+		if !ok {
+			return !valid, err
+		}
+	}
+	return valid, nil
+}
+
+func parseTestScriptSig(asm string) (*script.Script, error) {
+	raw, err := parseTestScript(asm)
+	if err != nil {
+		return nil, err
+	}
+	return script.ParseRawScript(raw)
+}
+
+// parseTestScript parses the script notation of Core's test vectors: numbers
+// are pushed as script numbers, 0x<hex> is inserted as raw bytes, 'text' is
+// pushed and anything else is an opcode name, with or without OP_
+func parseTestScript(asm string) ([]byte, error) {
+	var result []byte
+	for _, word := range strings.Fields(asm) {
+		switch {
+		case isTestNumber(word):
+			n, err := strconv.ParseInt(word, 10, 64)
+			if err != nil || n < -0xffffffff || n > 0xffffffff {
+				return nil, fmt.Errorf("number out of range: %s", word)
+			}
+			result = append(result, pushTestNumber(n)...)
+		case strings.HasPrefix(word, "0x") && len(word) > 2:
+			b, err := hex.DecodeString(word[2:])
+			if err != nil {
+				return nil, fmt.Errorf("bad hex: %s", word)
+			}
+			result = append(result, b...)
+		case len(word) >= 2 && word[0] == '\'' && word[len(word)-1] == '\'':
+			result = append(result, pushTestData([]byte(word[1:len(word)-1]))...)
+		default:
+			op, ok := script.OpcodeByName(word)
+			if !ok {
+				return nil, fmt.Errorf("unknown opcode: %s", word)
+			}
+			result = append(result, op)
+		}
+	}
+	return result, nil
+}
+
+func isTestNumber(word string) bool {
+	digits := strings.TrimPrefix(word, "-")
+	if digits == "" {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pushTestNumber pushes n with OP_0, OP_1NEGATE and OP_1-OP_16 when it can
+func pushTestNumber(n int64) []byte {
+	switch {
+	case n == 0:
+		return []byte{0x00}
+	case n == -1 || (n >= 1 && n <= 16):
+		return []byte{byte(0x50 + n)}
+	}
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	var num []byte
+	for ; abs > 0; abs >>= 8 {
+		num = append(num, byte(abs&0xff))
+	}
+	if num[len(num)-1]&0x80 != 0 {
+		num = append(num, 0x00)
+	}
+	if n < 0 {
+		num[len(num)-1] |= 0x80
+	}
+	return pushTestData(num)
+}
+
+func pushTestData(data []byte) []byte {
+	n := len(data)
+	var result []byte
+	switch {
+	case n < 0x4c:
+		result = []byte{byte(n)}
+	case n <= 0xff:
+		result = []byte{0x4c, byte(n)}
+	case n <= 0xffff:
+		result = []byte{0x4d, 0, 0}
+		binary.LittleEndian.PutUint16(result[1:], uint16(n))
+	default:
+		result = []byte{0x4e, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(result[1:], uint32(n))
+	}
+	return append(result, data...)
+}
+
+// testTaprootLeaf builds a taproot output with leafScript as its only leaf
+// and returns the control block to spend it and the x-only output key
+func testTaprootLeaf(leafScript []byte) ([]byte, []byte, error) {
+	internalKey := ecc.NewPrivateKey(big.NewInt(0x7ab)).PublicKey()
+	outputKey, err := ecc.TaprootOutputKey(internalKey, script.TapLeafHash(script.TAPROOT_LEAF_TAPSCRIPT, leafScript))
+	if err != nil {
+		return nil, nil, err
+	}
+	parity := byte(outputKey.Y().Num().Bit(0))
+	controlBlock := append([]byte{script.TAPROOT_LEAF_TAPSCRIPT | parity}, internalKey.XOnly()...)
+	return controlBlock, outputKey.XOnly(), nil
+}
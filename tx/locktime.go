@@ -0,0 +1,73 @@
+package tx
+
+import (
+	"fmt"
+
+	"github.com/miguelhun/programmingbitcoin-go/script"
+)
+
+// IsFinal reports whether the tx can be mined in a block at blockHeight with
+// timestamp blockTime. The lock time is a height if it is below
+// LOCKTIME_THRESHOLD and a unix time otherwise, and it is ignored if every
+// input has a final sequence
+func (tx Tx) IsFinal(blockHeight, blockTime uint32) bool {
+	if tx.locktime == 0 {
+		return true
+	}
+	limit := blockTime
+	if tx.locktime < script.LOCKTIME_THRESHOLD {
+		limit = blockHeight
+	}
+	if tx.locktime < limit {
+		return true
+	}
+	for _, txIn := range tx.txIns {
+		if txIn.sequence != script.SEQUENCE_FINAL {
+			return false
+		}
+	}
+	return true
+}
+
+// SequenceLocks returns the BIP 68 relative lock times of a version 2 or
+// later tx as the last block height and median time past at which it can't
+// be mined yet, -1 meaning no lock. prevHeights are the heights of the
+// blocks that mined the outputs the inputs spend and prevTimes the median
+// time past of the block before each of them
+func (tx Tx) SequenceLocks(prevHeights, prevTimes []uint32) (int64, int64, error) {
+	if len(prevHeights) != len(tx.txIns) || len(prevTimes) != len(tx.txIns) {
+		return 0, 0, fmt.Errorf("got %d heights and %d times for %d inputs", len(prevHeights), len(prevTimes), len(tx.txIns))
+	}
+	minHeight, minTime := int64(-1), int64(-1)
+	if tx.version < 2 {
+		return minHeight, minTime, nil
+	}
+
+	for i, txIn := range tx.txIns {
+		if txIn.sequence&script.SEQUENCE_LOCKTIME_DISABLE_FLAG != 0 {
+			continue
+		}
+		value := int64(txIn.sequence & script.SEQUENCE_LOCKTIME_MASK)
+		if txIn.sequence&script.SEQUENCE_LOCKTIME_TYPE_FLAG != 0 {
+			// units of 512 seconds, counted from the median time past of the
+			// block before the one the output was mined in
+			lockTime := int64(prevTimes[i]) + value<<script.SEQUENCE_LOCKTIME_GRANULARITY - 1
+			if lockTime > minTime {
+				minTime = lockTime
+			}
+		} else {
+			lockHeight := int64(prevHeights[i]) + value - 1
+			if lockHeight > minHeight {
+				minHeight = lockHeight
+			}
+		}
+	}
+	return minHeight, minTime, nil
+}
+
+// SequenceLocksSatisfied reports whether the locks SequenceLocks returned
+// let the tx be mined in the block at blockHeight, whose previous block has
+// median time past prevBlockTime
+func SequenceLocksSatisfied(minHeight, minTime int64, blockHeight, prevBlockTime uint32) bool {
+	return minHeight < int64(blockHeight) && minTime < int64(prevBlockTime)
+}
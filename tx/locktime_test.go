@@ -0,0 +1,74 @@
+package tx
+
+import (
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/script"
+)
+
+func TestIsFinal(t *testing.T) {
+	testCases := []struct {
+		locktime    uint32
+		sequence    uint32
+		blockHeight uint32
+		blockTime   uint32
+		want        bool
+	}{
+		{0, 0, 100, 1600000000, true},
+		{100, 0, 100, 1600000000, false},
+		{100, 0, 101, 1600000000, true},
+		{100, script.SEQUENCE_FINAL, 100, 1600000000, true},
+		{1600000000, 0, 700000, 1600000000, false},
+		{1600000000, 0, 700000, 1600000001, true},
+	}
+
+	for _, test := range testCases {
+		tx := NewTx(1, []TxIn{*NewTxIn([32]byte{}, 0, nil, test.sequence)}, nil, test.locktime, false)
+		if got := tx.IsFinal(test.blockHeight, test.blockTime); got != test.want {
+			t.Errorf("expected '%v' but got '%v' instead for locktime %d", test.want, got, test.locktime)
+		}
+	}
+}
+
+func TestSequenceLocks(t *testing.T) {
+	const timeFlag = script.SEQUENCE_LOCKTIME_TYPE_FLAG
+	txIns := []TxIn{
+		*NewTxIn([32]byte{}, 0, nil, 10),
+		*NewTxIn([32]byte{}, 1, nil, timeFlag|2),
+		*NewTxIn([32]byte{}, 2, nil, script.SEQUENCE_LOCKTIME_DISABLE_FLAG|50000),
+	}
+	prevHeights := []uint32{1000, 1005, 1200}
+	prevTimes := []uint32{1600000000, 1600003000, 1600100000}
+
+	tx := NewTx(2, txIns, nil, 0, false)
+	minHeight, minTime, err := tx.SequenceLocks(prevHeights, prevTimes)
+	if err != nil {
+		t.Fatalf("error getting sequence locks: %v", err)
+	}
+	if minHeight != 1009 {
+		t.Errorf("expected '%v' but got '%v' instead", 1009, minHeight)
+	}
+	if minTime != 1600003000+2*512-1 {
+		t.Errorf("expected '%v' but got '%v' instead", 1600003000+2*512-1, minTime)
+	}
+	if SequenceLocksSatisfied(minHeight, minTime, 1009, 1600100000) {
+		t.Errorf("expected height 1009 to be locked")
+	}
+	if !SequenceLocksSatisfied(minHeight, minTime, 1010, 1600100000) {
+		t.Errorf("expected height 1010 to be unlocked")
+	}
+	if SequenceLocksSatisfied(minHeight, minTime, 1010, 1600003000+2*512-1) {
+		t.Errorf("expected time %d to be locked", 1600003000+2*512-1)
+	}
+
+	// version 1 txs have no relative lock times
+	tx = NewTx(1, txIns, nil, 0, false)
+	minHeight, minTime, _ = tx.SequenceLocks(prevHeights, prevTimes)
+	if minHeight != -1 || minTime != -1 {
+		t.Errorf("expected no locks but got '%v' and '%v'", minHeight, minTime)
+	}
+
+	if _, _, err := tx.SequenceLocks(prevHeights[:1], prevTimes); err == nil {
+		t.Errorf("expected error for missing heights")
+	}
+}
@@ -0,0 +1,99 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+// segwitHashes caches the parts of the BIP 143 digest that are the same for
+// every input, so signing n inputs does not hash the whole tx n times
+type segwitHashes struct {
+	once         sync.Once
+	hashPrevouts [32]byte
+	hashSequence [32]byte
+	hashOutputs  [32]byte
+}
+
+func (tx Tx) segwitHashes() *segwitHashes {
+	cache := tx.segwitCache
+	if cache == nil {
+		cache = &segwitHashes{}
+	}
+	cache.once.Do(func() {
+		var prevouts, sequences, outputs []byte
+		for _, txIn := range tx.txIns {
+			prevouts = append(prevouts, txIn.outpoint()...)
+			sequence := make([]byte, 4)
+			binary.LittleEndian.PutUint32(sequence, txIn.sequence)
+			sequences = append(sequences, sequence...)
+		}
+		for _, txOut := range tx.txOuts {
+			outputs = append(outputs, txOut.Serialize()...)
+		}
+		cache.hashPrevouts = encoding.Hash256(prevouts)
+		cache.hashSequence = encoding.Hash256(sequences)
+		cache.hashOutputs = encoding.Hash256(outputs)
+	})
+	return cache
+}
+
+// SigHashBIP143 gets the segwit v0 signature hash (BIP 143) for the input at
+// inputIdx. scriptCode is the raw script being satisfied (the P2PKH script of
+// the key hash for P2WPKH, the witness script for P2WSH), amount is the
+// value of the output being spent and hashType is any legacy hash type
+func (tx Tx) SigHashBIP143(inputIdx uint32, scriptCode []byte, amount uint64, hashType uint32) (*big.Int, error) {
+	if int(inputIdx) >= len(tx.txIns) {
+		return nil, fmt.Errorf("input %d out of range", inputIdx)
+	}
+	txIn := tx.txIns[inputIdx]
+	hashes := tx.segwitHashes()
+	baseType := hashType & 0x1f
+	anyoneCanPay := hashType&SIGHASH_ANYONECANPAY != 0
+
+	// the parts of the tx the hash type leaves out are hashed as zeros
+	var hashPrevouts, hashSequence, hashOutputs [32]byte
+	if !anyoneCanPay {
+		hashPrevouts = hashes.hashPrevouts
+	}
+	if !anyoneCanPay && baseType != SIGHASH_SINGLE && baseType != SIGHASH_NONE {
+		hashSequence = hashes.hashSequence
+	}
+	if baseType != SIGHASH_SINGLE && baseType != SIGHASH_NONE {
+		hashOutputs = hashes.hashOutputs
+	} else if baseType == SIGHASH_SINGLE && int(inputIdx) < len(tx.txOuts) {
+		hashOutputs = encoding.Hash256(tx.txOuts[inputIdx].Serialize())
+	}
+
+	version := make([]byte, 4)
+	binary.LittleEndian.PutUint32(version, tx.version)
+
+	scriptCodeLen, err := encoding.EncodeVarint(len(scriptCode))
+	if err != nil {
+		return nil, err
+	}
+
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, amount)
+
+	sequence := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sequence, txIn.sequence)
+
+	locktime := make([]byte, 4)
+	binary.LittleEndian.PutUint32(locktime, tx.locktime)
+
+	hashTypeBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(hashTypeBytes, hashType)
+
+	preimage := bytes.Join([][]byte{
+		version, hashPrevouts[:], hashSequence[:], txIn.outpoint(),
+		scriptCodeLen, scriptCode, value, sequence, hashOutputs[:], locktime, hashTypeBytes,
+	}, []byte{})
+
+	signatureHash := encoding.Hash256(preimage)
+	return new(big.Int).SetBytes(signatureHash[:]), nil
+}
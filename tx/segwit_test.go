@@ -0,0 +1,192 @@
+package tx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/miguelhun/programmingbitcoin-go/script"
+	"github.com/stretchr/testify/assert"
+)
+
+// examples from BIP 143
+func TestSigHashBIP143(t *testing.T) {
+	testCases := []struct {
+		tx           string
+		inputIdx     uint32
+		scriptCode   string
+		amount       uint64
+		hashPrevouts string
+		hashSequence string
+		hashOutputs  string
+		sigHash      string
+	}{
+		{
+			// native P2WPKH
+			tx:           "0100000002fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f0000000000eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac11000000",
+			inputIdx:     1,
+			scriptCode:   "76a9141d0f172a0ecb48aee1be1f2687d2963ae33f71a188ac",
+			amount:       600000000,
+			hashPrevouts: "96b827c8483d4e9b96712b6713a7b68d6e8003a781feba36c31143470b4efd37",
+			hashSequence: "52b0a642eea2fb7ae638c36f6252b6750293dbe574a806984b8e4d8548339a3b",
+			hashOutputs:  "863ef3e1a92afbfdb97f31ad0fc7683ee943e9abcf2501590ff8f6551f47e5e5",
+			sigHash:      "c37af31116d1b27caf68aae9e3ac82f1477929014d5b917657d0eb49478cb670",
+		},
+		{
+			// P2SH-P2WPKH
+			tx:           "0100000001db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a54770100000000feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac92040000",
+			inputIdx:     0,
+			scriptCode:   "76a91479091972186c449eb1ded22b78e40d009bdf008988ac",
+			amount:       1000000000,
+			hashPrevouts: "b0287b4a252ac05af83d2dcef00ba313af78a3e9c329afa216eb3aa2a7b4613a",
+			hashSequence: "18606b350cd8bf565266bc352f0caddcf01e8fa789dd8a15386327cf8cabe198",
+			hashOutputs:  "de984f44532e2173ca0d64314fcefe6d30da6f8cf27bafa706da61df8a226c83",
+			sigHash:      "64f3b0f4dd2bb3aa1ce8566d220cc74dda9df97d8490cc81d89d735c92e59fb6",
+		},
+	}
+
+	for _, test := range testCases {
+		txBytes, _ := hex.DecodeString(test.tx)
+		tx, err := ParseTx(txBytes)
+		if err != nil {
+			t.Fatalf("error parsing tx: %v", err)
+		}
+		hashes := tx.segwitHashes()
+		assert.Equal(t, test.hashPrevouts, hex.EncodeToString(hashes.hashPrevouts[:]))
+		assert.Equal(t, test.hashSequence, hex.EncodeToString(hashes.hashSequence[:]))
+		assert.Equal(t, test.hashOutputs, hex.EncodeToString(hashes.hashOutputs[:]))
+
+		scriptCode, _ := hex.DecodeString(test.scriptCode)
+		z, err := tx.SigHashBIP143(test.inputIdx, scriptCode, test.amount, SIGHASH_ALL)
+		if err != nil {
+			t.Fatalf("error getting sighash: %v", err)
+		}
+		got := hex.EncodeToString(z.FillBytes(make([]byte, 32)))
+		if got != test.sigHash {
+			t.Errorf("expected '%v' but got '%v' instead", test.sigHash, got)
+		}
+	}
+}
+
+// fakePrevTx puts a transaction paying value to scriptPubKey in the cache so
+// inputs spending it can be signed and verified offline
+func fakePrevTx(value uint64, scriptPubKey *script.Script) [32]byte {
+	prev := NewTx(1, []TxIn{*NewTxIn([32]byte{}, 0xffffffff, nil, 0xffffffff)}, []TxOut{*NewTxOut(value, scriptPubKey)}, 0, false)
+	var id [32]byte
+	copy(id[:], prev.ID())
+	txCache[hex.EncodeToString(id[:])] = prev
+	return id
+}
+
+func TestSignInputSegwit(t *testing.T) {
+	privKey := ecc.NewPrivateKey(big.NewInt(8675309))
+	pubKeyHash := encoding.Hash160(privKey.PublicKey().Sec(true))
+	redeemScript := script.P2WPKHScript(pubKeyHash)
+
+	testCases := []struct {
+		name         string
+		scriptPubKey *script.Script
+		witness      bool
+	}{
+		{"p2pkh", script.P2PKHScript(pubKeyHash), false},
+		{"p2wpkh", script.P2WPKHScript(pubKeyHash), true},
+		{"p2sh-p2wpkh", script.NewScript([][]byte{{0xa9}, encoding.Hash160(redeemScript.RawSerialize()), {0x87}}), true},
+	}
+
+	for _, test := range testCases {
+		prevTxId := fakePrevTx(50000, test.scriptPubKey)
+		txIn := NewTxIn(prevTxId, 0, nil, 0xffffffff)
+		txOut := NewTxOut(40000, script.P2WPKHScript(pubKeyHash))
+		tx := NewTx(2, []TxIn{*txIn}, []TxOut{*txOut}, 0, false)
+
+		valid, err := tx.SignInput(0, privKey, SIGHASH_ALL)
+		if err != nil {
+			t.Fatalf("%v: error signing input: %v", test.name, err)
+		}
+		if !valid {
+			t.Errorf("%v: expected signed input to verify", test.name)
+		}
+		assert.Equal(t, test.witness, tx.HasWitness(), test.name)
+
+		valid, err = tx.Verify()
+		if err != nil || !valid {
+			t.Errorf("%v: expected tx to verify, got '%v' '%v'", test.name, valid, err)
+		}
+
+		// the segwit digest commits to the amount, so a different value
+		// breaks the signature
+		if test.witness {
+			txCache[hex.EncodeToString(prevTxId[:])].txOuts[0].value = 50001
+			valid, _ := tx.VerifyInput(0)
+			assert.False(t, valid, test.name)
+		}
+	}
+}
+
+func TestVerifyP2WSH(t *testing.T) {
+	privKey := ecc.NewPrivateKey(big.NewInt(31337))
+	witnessScript := script.NewScript([][]byte{privKey.PublicKey().Sec(true), {0xac}})
+	rawWitnessScript := witnessScript.RawSerialize()
+	scriptHash := sha256.Sum256(rawWitnessScript)
+
+	prevTxId := fakePrevTx(70000, script.P2WSHScript(scriptHash[:]))
+	tx := NewTx(2, []TxIn{*NewTxIn(prevTxId, 0, nil, 0xfffffffe)}, []TxOut{*NewTxOut(60000, witnessScript)}, 0, false)
+
+	z, err := tx.SigHashBIP143(0, rawWitnessScript, 70000, SIGHASH_ALL)
+	if err != nil {
+		t.Fatalf("error getting sighash: %v", err)
+	}
+	sig := append(privKey.Sign(z).Der(), SIGHASH_ALL)
+
+	testCases := []struct {
+		witness [][]byte
+		valid   bool
+	}{
+		{[][]byte{sig, rawWitnessScript}, true},
+		{[][]byte{sig, append(rawWitnessScript, 0x51)}, false},
+		{[][]byte{}, false},
+	}
+	for _, test := range testCases {
+		tx.txIns[0].SetWitness(test.witness)
+		valid, _ := tx.VerifyInput(0)
+		if valid != test.valid {
+			t.Errorf("expected '%v' but got '%v' instead", test.valid, valid)
+		}
+	}
+}
+
+// P2SH-P2WSH example from BIP 143, signed with every hash type
+func TestSigHashBIP143Types(t *testing.T) {
+	txBytes, _ := hex.DecodeString("010000000136641869ca081e70f394c6948e8af409e18b619df2ed74aa106c1ca29787b96e0100000000ffffffff0200e9a435000000001976a914389ffce9cd9ae88dcc0631e88a821ffdbe9bfe2688acc0832f05000000001976a9147480a33f950689af511e6e84c138dbbd3c3ee41588ac00000000")
+	tx, err := ParseTx(txBytes)
+	if err != nil {
+		t.Fatalf("error parsing tx: %v", err)
+	}
+	witnessScript, _ := hex.DecodeString("56210307b8ae49ac90a048e9b53357a2354b3334e9c8bee813ecb98e99a7e07e8c3ba32103b28f0c28bfab54554ae8c658ac5c3e0ce6e79ad336331f78c428dd43eea8449b21034b8113d703413d57761b8b9781957b8c0ac1dfe69f492580ca4195f50376ba4a21033400f6afecb833092a9a21cfdf1ed1376e58c5d1f47de74683123987e967a8f42103a6d48b1131e94ba04d9737d61acdaa1322008af9602b3b14862c07a1789aac162102d8b661b0b3302ee2f162b09e07a55ad5dfbe673a9f01d9f0c19617681024306b56ae")
+
+	testCases := []struct {
+		hashType uint32
+		want     string
+	}{
+		{SIGHASH_ALL, "185c0be5263dce5b4bb50a047973c1b6272bfbd0103a89444597dc40b248ee7c"},
+		{SIGHASH_NONE, "e9733bc60ea13c95c6527066bb975a2ff29a925e80aa14c213f686cbae5d2f36"},
+		{SIGHASH_SINGLE, "1e1f1c303dc025bd664acb72e583e933fae4cff9148bf78c157d1e8f78530aea"},
+		{SIGHASH_ALL | SIGHASH_ANYONECANPAY, "2a67f03e63a6a422125878b40b82da593be8d4efaafe88ee528af6e5a9955c6e"},
+		{SIGHASH_NONE | SIGHASH_ANYONECANPAY, "781ba15f3779d5542ce8ecb5c18716733a5ee42a6f51488ec96154934e2c890a"},
+		{SIGHASH_SINGLE | SIGHASH_ANYONECANPAY, "511e8e52ed574121fc1b654970395502128263f62662e076dc6baf05c2e6a99b"},
+	}
+
+	for _, test := range testCases {
+		z, err := tx.SigHashBIP143(0, witnessScript, 987654321, test.hashType)
+		if err != nil {
+			t.Fatalf("error getting sighash: %v", err)
+		}
+		got := hex.EncodeToString(z.FillBytes(make([]byte, 32)))
+		if got != test.want {
+			t.Errorf("hash type %#x: expected '%v' but got '%v' instead", test.hashType, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package tx
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/miguelhun/programmingbitcoin-go/script"
+)
+
+// SIGHASH_DEFAULT signs like SIGHASH_ALL and is left out of 64 byte taproot
+// signatures
+const SIGHASH_DEFAULT = 0x00
+
+// taprootHashes caches the single sha256 hashes BIP 341 shares between every
+// input. Unlike BIP 143 it commits to the amounts and scriptPubKeys of all
+// the outputs being spent, so building it needs every previous tx
+type taprootHashes struct {
+	mu            sync.Mutex
+	done          bool
+	shaPrevouts   [32]byte
+	shaAmounts    [32]byte
+	shaScriptPKs  [32]byte
+	shaSequences  [32]byte
+	shaOutputs    [32]byte
+	amounts       []uint64
+	scriptPubKeys []*script.Script
+}
+
+// taprootHashes fills the cache the first time it gets every previous tx.
+// Errors aren't cached, so a failed fetch is tried again on the next call
+func (tx Tx) taprootHashes() (*taprootHashes, error) {
+	cache := tx.taprootCache
+	if cache == nil {
+		cache = &taprootHashes{}
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.done {
+		return cache, nil
+	}
+
+	var prevouts, amounts, scriptPubKeys, sequences, outputs []byte
+	prevAmounts := make([]uint64, 0, len(tx.txIns))
+	prevScriptPubKeys := make([]*script.Script, 0, len(tx.txIns))
+	for _, txIn := range tx.txIns {
+		prevOut, err := txIn.prevTxOut(tx.testnet)
+		if err != nil {
+			return nil, err
+		}
+		prevAmounts = append(prevAmounts, prevOut.value)
+		prevScriptPubKeys = append(prevScriptPubKeys, prevOut.scriptPubKey)
+
+		prevouts = append(prevouts, txIn.outpoint()...)
+		amount := make([]byte, 8)
+		binary.LittleEndian.PutUint64(amount, prevOut.value)
+		amounts = append(amounts, amount...)
+		scriptPubKeys = append(scriptPubKeys, prevOut.scriptPubKey.Serialize()...)
+		sequence := make([]byte, 4)
+		binary.LittleEndian.PutUint32(sequence, txIn.sequence)
+		sequences = append(sequences, sequence...)
+	}
+	for _, txOut := range tx.txOuts {
+		outputs = append(outputs, txOut.Serialize()...)
+	}
+	cache.amounts = prevAmounts
+	cache.scriptPubKeys = prevScriptPubKeys
+	cache.shaPrevouts = sha256.Sum256(prevouts)
+	cache.shaAmounts = sha256.Sum256(amounts)
+	cache.shaScriptPKs = sha256.Sum256(scriptPubKeys)
+	cache.shaSequences = sha256.Sum256(sequences)
+	cache.shaOutputs = sha256.Sum256(outputs)
+	cache.done = true
+	return cache, nil
+}
+
+func validTaprootHashType(hashType byte) bool {
+	return hashType <= SIGHASH_SINGLE || (hashType >= 0x81 && hashType <= 0x83)
+}
+
+// SigHashTaproot gets the BIP 341 signature hash for the input at inputIdx.
+// annex is the annex of the input without its length or nil if there is
+// none. leafHash is the tapleaf hash of the script being run for script
+// path spends and nil for key path spends, and codeSepPos is the opcode
+// position of the last OP_CODESEPARATOR run or 0xffffffff
+func (tx Tx) SigHashTaproot(inputIdx uint32, hashType byte, annex, leafHash []byte, codeSepPos uint32) ([]byte, error) {
+	if int(inputIdx) >= len(tx.txIns) {
+		return nil, fmt.Errorf("input %d out of range", inputIdx)
+	}
+	if !validTaprootHashType(hashType) {
+		return nil, fmt.Errorf("invalid taproot hash type 0x%02x", hashType)
+	}
+	baseType := hashType & 0x03
+	anyoneCanPay := hashType&SIGHASH_ANYONECANPAY != 0
+	if baseType == SIGHASH_SINGLE && int(inputIdx) >= len(tx.txOuts) {
+		return nil, errors.New("SIGHASH_SINGLE without a matching output")
+	}
+
+	hashes, err := tx.taprootHashes()
+	if err != nil {
+		return nil, err
+	}
+	txIn := tx.txIns[inputIdx]
+
+	// epoch 0 and the control byte
+	msg := []byte{0x00, hashType}
+	msg = appendUint32(msg, tx.version)
+	msg = appendUint32(msg, tx.locktime)
+	if !anyoneCanPay {
+		msg = append(msg, hashes.shaPrevouts[:]...)
+		msg = append(msg, hashes.shaAmounts[:]...)
+		msg = append(msg, hashes.shaScriptPKs[:]...)
+		msg = append(msg, hashes.shaSequences[:]...)
+	}
+	if baseType != SIGHASH_NONE && baseType != SIGHASH_SINGLE {
+		msg = append(msg, hashes.shaOutputs[:]...)
+	}
+
+	// spend type is ext_flag * 2 + annex_present
+	var spendType byte
+	if leafHash != nil {
+		spendType = 2
+	}
+	if annex != nil {
+		spendType |= 1
+	}
+	msg = append(msg, spendType)
+
+	if anyoneCanPay {
+		msg = append(msg, txIn.outpoint()...)
+		msg = appendUint64(msg, hashes.amounts[inputIdx])
+		msg = append(msg, hashes.scriptPubKeys[inputIdx].Serialize()...)
+		msg = appendUint32(msg, txIn.sequence)
+	} else {
+		msg = appendUint32(msg, inputIdx)
+	}
+	if annex != nil {
+		annexLen, err := encoding.EncodeVarint(len(annex))
+		if err != nil {
+			return nil, err
+		}
+		shaAnnex := sha256.Sum256(append(annexLen, annex...))
+		msg = append(msg, shaAnnex[:]...)
+	}
+	if baseType == SIGHASH_SINGLE {
+		shaOutput := sha256.Sum256(tx.txOuts[inputIdx].Serialize())
+		msg = append(msg, shaOutput[:]...)
+	}
+	if leafHash != nil {
+		// key_version 0
+		msg = append(msg, leafHash...)
+		msg = append(msg, 0x00)
+		msg = appendUint32(msg, codeSepPos)
+	}
+
+	hash := encoding.TaggedHash("TapSighash", msg)
+	return hash[:], nil
+}
+
+// SignInputTaproot signs a key path spend of the P2TR output at inputIdx.
+// privKey is the internal key and merkleRoot the root of the script tree,
+// nil if the output has no script path
+func (tx *Tx) SignInputTaproot(inputIdx uint32, privKey *ecc.PrivateKey, merkleRoot []byte, hashType byte) (bool, error) {
+	msg, err := tx.SigHashTaproot(inputIdx, hashType, nil, nil, 0xffffffff)
+	if err != nil {
+		return false, err
+	}
+	txIn := &tx.txIns[inputIdx]
+	tweaked, err := privKey.TaprootTweak(merkleRoot)
+	if err != nil {
+		return false, err
+	}
+	sig, err := tweaked.SignSchnorr(msg)
+	if err != nil {
+		return false, err
+	}
+	sigBytes := sig.Serialize()
+	if hashType != SIGHASH_DEFAULT {
+		sigBytes = append(sigBytes, hashType)
+	}
+	txIn.scriptSig = script.NewScript(nil)
+	txIn.witness = [][]byte{sigBytes}
+	return tx.VerifyInput(inputIdx)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, v)
+	return append(b, buf...)
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, v)
+	return append(b, buf...)
+}
@@ -0,0 +1,173 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/script"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignInputTaproot(t *testing.T) {
+	privKey := ecc.NewPrivateKey(big.NewInt(8675309))
+	outputKey, err := ecc.TaprootOutputKey(privKey.PublicKey(), nil)
+	if err != nil {
+		t.Fatalf("error tweaking key: %v", err)
+	}
+	p2tr := script.P2TRScript(outputKey.XOnly())
+
+	hashTypes := []byte{SIGHASH_DEFAULT, SIGHASH_ALL, SIGHASH_NONE, SIGHASH_SINGLE | SIGHASH_ANYONECANPAY}
+	for _, hashType := range hashTypes {
+		prevTxId := fakePrevTx(50000, p2tr)
+		tx := NewTx(2, []TxIn{*NewTxIn(prevTxId, 0, nil, 0xffffffff)}, []TxOut{*NewTxOut(40000, p2tr)}, 0, false)
+
+		valid, err := tx.SignInputTaproot(0, privKey, nil, hashType)
+		if err != nil {
+			t.Fatalf("error signing input: %v", err)
+		}
+		if !valid {
+			t.Errorf("expected hash type 0x%02x signature to verify", hashType)
+		}
+
+		sig := tx.txIns[0].Witness()[0]
+		expectedLen := 65
+		if hashType == SIGHASH_DEFAULT {
+			expectedLen = 64
+		}
+		if len(sig) != expectedLen {
+			t.Errorf("expected '%v' but got '%v' instead", expectedLen, len(sig))
+		}
+
+		// a 65 byte signature can't use SIGHASH_DEFAULT explicitly
+		if hashType == SIGHASH_DEFAULT {
+			tx.txIns[0].SetWitness([][]byte{append(sig, SIGHASH_DEFAULT)})
+			if valid, _ := tx.VerifyInput(0); valid {
+				t.Errorf("expected explicit SIGHASH_DEFAULT to fail")
+			}
+		}
+
+		// the signature commits to the outputs unless it is SIGHASH_NONE
+		other := NewTx(2, []TxIn{*NewTxIn(prevTxId, 0, nil, 0xffffffff)}, []TxOut{*NewTxOut(45000, p2tr)}, 0, false)
+		other.txIns[0].SetWitness([][]byte{sig})
+		if valid, _ := other.VerifyInput(0); valid != (hashType == SIGHASH_NONE) {
+			t.Errorf("expected hash type 0x%02x signature over other outputs to be '%v'", hashType, hashType == SIGHASH_NONE)
+		}
+	}
+
+	// signing an input the tx doesn't have fails
+	outOfRange := NewTx(2, []TxIn{*NewTxIn(fakePrevTx(50002, p2tr), 0, nil, 0xffffffff)}, []TxOut{*NewTxOut(40000, p2tr)}, 0, false)
+	_, err = outOfRange.SignInputTaproot(1, privKey, nil, SIGHASH_DEFAULT)
+	assert.Error(t, err)
+
+	// signing with the untweaked key fails
+	prevTxId := fakePrevTx(50001, p2tr)
+	tx := NewTx(2, []TxIn{*NewTxIn(prevTxId, 0, nil, 0xffffffff)}, []TxOut{*NewTxOut(40000, p2tr)}, 0, false)
+	msg, err := tx.SigHashTaproot(0, SIGHASH_DEFAULT, nil, nil, 0xffffffff)
+	if err != nil {
+		t.Fatalf("error getting sighash: %v", err)
+	}
+	sig, _ := privKey.SignSchnorr(msg)
+	tx.txIns[0].SetWitness([][]byte{sig.Serialize()})
+	if valid, _ := tx.VerifyInput(0); valid {
+		t.Errorf("expected untweaked signature to fail")
+	}
+}
+
+func TestSigHashTaprootRetriesFetch(t *testing.T) {
+	p2tr := script.P2TRScript(ecc.NewPrivateKey(big.NewInt(4242)).PublicKey().XOnly())
+	prevTxId := fakePrevTx(50000, p2tr)
+	tx := NewTx(2, []TxIn{*NewTxIn(prevTxId, 0, nil, 0xffffffff), *NewTxIn(prevTxId, 1, nil, 0xffffffff)}, []TxOut{*NewTxOut(40000, p2tr)}, 0, false)
+
+	// the previous tx has no output 1 yet
+	_, err := tx.SigHashTaproot(0, SIGHASH_DEFAULT, nil, nil, 0xffffffff)
+	assert.Error(t, err)
+
+	// once it can be fetched the digest is computed instead of the first
+	// error being returned again
+	prev := txCache[hex.EncodeToString(prevTxId[:])]
+	prev.txOuts = append(prev.txOuts, *NewTxOut(10000, p2tr))
+	msg, err := tx.SigHashTaproot(0, SIGHASH_DEFAULT, nil, nil, 0xffffffff)
+	assert.NoError(t, err)
+	assert.Len(t, msg, 32)
+}
+
+func TestVerifyTaprootScriptPath(t *testing.T) {
+	internalKey := ecc.NewPrivateKey(big.NewInt(1111))
+	key1 := ecc.NewPrivateKey(big.NewInt(2222))
+	key2 := ecc.NewPrivateKey(big.NewInt(3333))
+
+	// leaf 0: <key1> OP_CHECKSIG <key2> OP_CHECKSIGADD OP_2 OP_EQUAL
+	// leaf 1: OP_SUCCESS80
+	leaf0 := script.NewScript([][]byte{key1.PublicKey().XOnly(), {0xac}, key2.PublicKey().XOnly(), {0xba}, {0x52}, {0x87}}).RawSerialize()
+	leaf1 := []byte{0x50}
+	leafHash0 := script.TapLeafHash(script.TAPROOT_LEAF_TAPSCRIPT, leaf0)
+	leafHash1 := script.TapLeafHash(script.TAPROOT_LEAF_TAPSCRIPT, leaf1)
+	merkleRoot := script.TapBranchHash(leafHash0, leafHash1)
+
+	outputKey, err := ecc.TaprootOutputKey(internalKey.PublicKey(), merkleRoot)
+	if err != nil {
+		t.Fatalf("error tweaking key: %v", err)
+	}
+	parity := byte(outputKey.Y().Num().Bit(0))
+	p2tr := script.P2TRScript(outputKey.XOnly())
+
+	controlBlock := func(leafVersion byte, sibling []byte) []byte {
+		cb := append([]byte{leafVersion | parity}, internalKey.PublicKey().XOnly()...)
+		return append(cb, sibling...)
+	}
+
+	prevTxId := fakePrevTx(90000, p2tr)
+	tx := NewTx(2, []TxIn{*NewTxIn(prevTxId, 0, nil, 0xffffffff)}, []TxOut{*NewTxOut(80000, p2tr)}, 0, false)
+
+	msg, err := tx.SigHashTaproot(0, SIGHASH_DEFAULT, nil, leafHash0, 0xffffffff)
+	if err != nil {
+		t.Fatalf("error getting sighash: %v", err)
+	}
+	sig1, _ := key1.SignSchnorr(msg)
+	sig2, _ := key2.SignSchnorr(msg)
+	badControlBlock := controlBlock(script.TAPROOT_LEAF_TAPSCRIPT, leafHash1)
+	badControlBlock[0] ^= 1
+	// an internal key above the field size isn't on the curve
+	badInternalKey := append([]byte{script.TAPROOT_LEAF_TAPSCRIPT | parity}, bytes.Repeat([]byte{0xff}, 32)...)
+	badInternalKey = append(badInternalKey, leafHash0...)
+
+	// the stack is consumed from the end, so sig2 is pushed first
+	testCases := []struct {
+		name    string
+		witness [][]byte
+		valid   bool
+		err     error // checked when set
+	}{
+		{"2-of-2", [][]byte{sig2.Serialize(), sig1.Serialize(), leaf0, controlBlock(script.TAPROOT_LEAF_TAPSCRIPT, leafHash1)}, true, nil},
+		{"1-of-2", [][]byte{{}, sig1.Serialize(), leaf0, controlBlock(script.TAPROOT_LEAF_TAPSCRIPT, leafHash1)}, false, nil},
+		{"swapped sigs", [][]byte{sig1.Serialize(), sig2.Serialize(), leaf0, controlBlock(script.TAPROOT_LEAF_TAPSCRIPT, leafHash1)}, false, nil},
+		{"with annex", [][]byte{sig2.Serialize(), sig1.Serialize(), leaf0, controlBlock(script.TAPROOT_LEAF_TAPSCRIPT, leafHash1), {0x50, 0x01}}, false, nil},
+		{"op success", [][]byte{leaf1, controlBlock(script.TAPROOT_LEAF_TAPSCRIPT, leafHash0)}, true, nil},
+		{"unknown leaf version", [][]byte{leaf1, controlBlock(0xc2, leafHash0)}, false, nil},
+		{"wrong parity", [][]byte{sig2.Serialize(), sig1.Serialize(), leaf0, badControlBlock}, false, script.ErrWitnessProgramMismatch},
+		{"wrong sibling", [][]byte{leaf1, controlBlock(script.TAPROOT_LEAF_TAPSCRIPT, leafHash1)}, false, script.ErrWitnessProgramMismatch},
+		{"bad internal key", [][]byte{leaf1, badInternalKey}, false, script.ErrWitnessProgramMismatch},
+		{"short control block", [][]byte{leaf1, controlBlock(script.TAPROOT_LEAF_TAPSCRIPT, leafHash0)[:40]}, false, script.ErrTaprootWrongControlSize},
+	}
+	for _, test := range testCases {
+		tx.txIns[0].SetWitness(test.witness)
+		valid, err := tx.VerifyInput(0)
+		if valid != test.valid {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.valid, valid)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: expected '%v' but got '%v' instead", test.name, test.err, err)
+		}
+	}
+
+	// the same spend checked through a batch
+	tx.txIns[0].SetWitness(testCases[0].witness)
+	valid, err := tx.Verify()
+	if err != nil || !valid {
+		t.Errorf("expected '%v' but got '%v' instead (%v)", true, valid, err)
+	}
+}
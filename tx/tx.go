@@ -0,0 +1,779 @@
+// Package tx implements parsing, serialization, signing and verification of
+// Bitcoin transactions.
+package tx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/miguelhun/programmingbitcoin-go/script"
+)
+
+const (
+	SIGHASH_ALL          = 1
+	SIGHASH_NONE         = 2
+	SIGHASH_SINGLE       = 3
+	SIGHASH_ANYONECANPAY = 0x80
+)
+
+// Tx is a transaction
+type Tx struct {
+	version  uint32
+	txIns    []TxIn
+	txOuts   []TxOut
+	locktime uint32
+	testnet  bool
+
+	segwitCache  *segwitHashes
+	taprootCache *taprootHashes
+}
+
+// NewTx returns a transaction. testnet selects the network used to look up
+// the previous transactions referenced by the inputs
+func NewTx(version uint32, txIns []TxIn, txOuts []TxOut, locktime uint32, testnet bool) *Tx {
+	return &Tx{version: version, txIns: txIns, txOuts: txOuts, locktime: locktime, testnet: testnet, segwitCache: &segwitHashes{}, taprootCache: &taprootHashes{}}
+}
+
+func (tx Tx) Version() uint32 {
+	return tx.version
+}
+
+func (tx Tx) TxIns() []TxIn {
+	return tx.txIns
+}
+
+func (tx Tx) TxOuts() []TxOut {
+	return tx.txOuts
+}
+
+func (tx Tx) Locktime() uint32 {
+	return tx.locktime
+}
+
+func (tx Tx) Testnet() bool {
+	return tx.testnet
+}
+
+// SetTestnet sets the network used to look up previous transactions
+func (tx *Tx) SetTestnet(testnet bool) {
+	tx.testnet = testnet
+}
+
+// ID is the txid, 2 sha256 of the transaction serialized without witness data
+func (tx Tx) ID() []byte {
+	hash := encoding.Hash256(tx.SerializeLegacy())
+	return encoding.Reverse(hash[:])
+}
+
+// WitnessID is the wtxid (BIP 141), 2 sha256 of the transaction serialized
+// with witness data. It is the same as ID when no input has a witness
+func (tx Tx) WitnessID() []byte {
+	hash := encoding.Hash256(tx.Serialize())
+	return encoding.Reverse(hash[:])
+}
+
+// HasWitness reports whether any input has witness data, in which case the
+// transaction is serialized with the segwit marker and flag
+func (tx Tx) HasWitness() bool {
+	for _, txIn := range tx.txIns {
+		if len(txIn.witness) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTx parses a serialized transaction, with or without the segwit marker,
+// flag and witness data (BIP 144)
+func ParseTx(s []byte) (*Tx, error) {
+	sbuf := bytes.NewBuffer(s)
+	buf := make([]byte, 4)
+	// read first four bytes for version
+	_, err := io.ReadFull(sbuf, buf)
+	if err != nil {
+		return nil, fmt.Errorf("error reading version: %v", err)
+	}
+	// version from buf is in little endian
+	version := binary.LittleEndian.Uint32(buf)
+
+	// a 0x00 marker where the number of inputs goes is followed by the flag,
+	// which has to be 0x01
+	segwit := false
+	if sbuf.Len() >= 2 && sbuf.Bytes()[0] == 0x00 {
+		if sbuf.Bytes()[1] != 0x01 {
+			return nil, fmt.Errorf("unknown segwit flag %#x", sbuf.Bytes()[1])
+		}
+		sbuf.Next(2)
+		segwit = true
+	}
+
+	// get number of inputs
+	numInputs, err := encoding.ReadVarint(sbuf)
+	if err != nil {
+		return nil, fmt.Errorf("error reading input varint: %v", err)
+	}
+
+	// parse inputs and append them to input list
+	var inputs []TxIn
+	for i := 0; i < numInputs; i++ {
+		txIn, err := parseTxIn(sbuf)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, *txIn)
+	}
+
+	// get number of outputs
+	numOutputs, err := encoding.ReadVarint(sbuf)
+	if err != nil {
+		return nil, fmt.Errorf("error reading output varint: %v", err)
+	}
+
+	// parse outputs and append them to output list
+	var outputs []TxOut
+	for i := 0; i < numOutputs; i++ {
+		txOut, err := parseTxOut(sbuf)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, *txOut)
+	}
+
+	// one witness stack per input
+	if segwit {
+		hasWitness := false
+		for i := range inputs {
+			witness, err := parseWitness(sbuf)
+			if err != nil {
+				return nil, err
+			}
+			inputs[i].witness = witness
+			hasWitness = hasWitness || len(witness) > 0
+		}
+		if !hasWitness {
+			return nil, errors.New("segwit marker with no witness data")
+		}
+	}
+
+	// read 4 bytes for locktime
+	_, err = io.ReadFull(sbuf, buf)
+	if err != nil {
+		return nil, fmt.Errorf("error reading locktime: %v", err)
+	}
+	// locktime is in little endian
+	locktime := binary.LittleEndian.Uint32(buf)
+
+	return &Tx{version: version, txIns: inputs, txOuts: outputs, locktime: locktime, segwitCache: &segwitHashes{}, taprootCache: &taprootHashes{}}, nil
+}
+
+// parseWitness reads the number of stack items followed by each item
+func parseWitness(r io.Reader) ([][]byte, error) {
+	numItems, err := encoding.ReadVarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading witness varint: %v", err)
+	}
+	if numItems < 0 || numItems > encoding.MAX_SIZE {
+		return nil, fmt.Errorf("witness item count %d out of range 0 to %d", numItems, encoding.MAX_SIZE)
+	}
+	var witness [][]byte
+	for i := 0; i < numItems; i++ {
+		itemLen, err := encoding.ReadVarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("error reading witness item length: %v", err)
+		}
+		item, err := encoding.ReadBytes(r, itemLen)
+		if err != nil {
+			return nil, fmt.Errorf("error reading witness item: %v", err)
+		}
+		witness = append(witness, item)
+	}
+	return witness, nil
+}
+
+// Serialize serializes the transaction, including the segwit marker, flag and
+// witness data if any input has a witness
+func (tx Tx) Serialize() []byte {
+	return tx.serialize(tx.HasWitness())
+}
+
+// SerializeLegacy serializes the transaction without witness data, the format
+// hashed for the txid
+func (tx Tx) SerializeLegacy() []byte {
+	return tx.serialize(false)
+}
+
+func (tx Tx) serialize(withWitness bool) []byte {
+	version := make([]byte, 4)
+	binary.LittleEndian.PutUint32(version, tx.version)
+
+	var markerFlag []byte
+	if withWitness {
+		markerFlag = []byte{0x00, 0x01}
+	}
+
+	txInsLen, _ := encoding.EncodeVarint(len(tx.txIns))
+
+	var txIns []byte
+	for _, txIn := range tx.txIns {
+		txIns = append(txIns, txIn.Serialize()...)
+	}
+
+	txOutsLen, _ := encoding.EncodeVarint(len(tx.txOuts))
+
+	var txOuts []byte
+	for _, txOut := range tx.txOuts {
+		txOuts = append(txOuts, txOut.Serialize()...)
+	}
+
+	var witnesses []byte
+	if withWitness {
+		for _, txIn := range tx.txIns {
+			witnesses = append(witnesses, serializeWitness(txIn.witness)...)
+		}
+	}
+
+	locktime := make([]byte, 4)
+	binary.LittleEndian.PutUint32(locktime, tx.locktime)
+
+	return bytes.Join([][]byte{version, markerFlag, txInsLen, txIns, txOutsLen, txOuts, witnesses, locktime}, []byte{})
+}
+
+func serializeWitness(witness [][]byte) []byte {
+	result, _ := encoding.EncodeVarint(len(witness))
+	for _, item := range witness {
+		itemLen, _ := encoding.EncodeVarint(len(item))
+		result = append(result, itemLen...)
+		result = append(result, item...)
+	}
+	return result
+}
+
+// IsCoinbase reports whether tx is a coinbase transaction
+func (tx Tx) IsCoinbase() bool {
+	if len(tx.txIns) != 1 {
+		return false
+	}
+	prevTx := binary.BigEndian.Uint32(tx.txIns[0].prevTxId[:])
+	return prevTx == 0 && tx.txIns[0].prevTxIdx == 0xffffffff
+}
+
+// CoinbaseHeight returns the block height in the coinbase scriptSig (BIP 34)
+func (tx Tx) CoinbaseHeight() uint32 {
+	if !tx.IsCoinbase() {
+		return 0
+	}
+	scriptSig := tx.txIns[0].scriptSig
+	height := make([]byte, 4)
+	copy(height, scriptSig.Cmds()[0])
+	return binary.LittleEndian.Uint32(height)
+}
+
+// SigHash gets the legacy signature hash for input at inputIdx and hashType,
+// which is SIGHASH_ALL, SIGHASH_NONE or SIGHASH_SINGLE optionally combined
+// with SIGHASH_ANYONECANPAY
+func (tx Tx) SigHash(inputIdx uint32, hashType uint32) (*big.Int, error) {
+	if int(inputIdx) >= len(tx.txIns) {
+		return nil, fmt.Errorf("input %d out of range", inputIdx)
+	}
+	scriptPubKey, err := tx.txIns[inputIdx].ScriptPubKey(tx.testnet)
+	if err != nil {
+		return nil, err
+	}
+	return tx.legacySigHash(inputIdx, scriptPubKey.RawSerialize(), hashType), nil
+}
+
+// legacySigHash serializes a copy of the tx with scriptCode, without its
+// OP_CODESEPARATORs, in place of the scriptSig being signed and empty
+// scriptSigs everywhere else, trimmed according to hashType
+func (tx Tx) legacySigHash(inputIdx uint32, scriptCode []byte, hashType uint32) *big.Int {
+	scriptCode = script.RemoveCodeSeparators(scriptCode)
+	baseType := hashType & 0x1f
+	anyoneCanPay := hashType&SIGHASH_ANYONECANPAY != 0
+
+	// SIGHASH_SINGLE without a matching output signs the uint256 1, a bug
+	// kept for consensus. Core stores it little endian, so the digest
+	// checked is 0x01 followed by 31 zero bytes
+	if baseType == SIGHASH_SINGLE && int(inputIdx) >= len(tx.txOuts) {
+		return new(big.Int).Lsh(big.NewInt(1), 248)
+	}
+
+	version := make([]byte, 4)
+	binary.LittleEndian.PutUint32(version, tx.version)
+
+	scriptCodeLen, _ := encoding.EncodeVarint(len(scriptCode))
+	sequence := make([]byte, 4)
+
+	// replace scriptSigs being signed with scriptCode
+	var txInput []byte
+	numInputs := 0
+	for i, txIn := range tx.txIns {
+		if anyoneCanPay && i != int(inputIdx) {
+			continue
+		}
+		numInputs++
+		txInput = append(txInput, txIn.outpoint()...)
+		if i == int(inputIdx) {
+			txInput = append(txInput, scriptCodeLen...)
+			txInput = append(txInput, scriptCode...)
+		} else {
+			txInput = append(txInput, 0x00)
+		}
+		// NONE and SINGLE let the other inputs change their sequence
+		if i != int(inputIdx) && (baseType == SIGHASH_NONE || baseType == SIGHASH_SINGLE) {
+			binary.LittleEndian.PutUint32(sequence, 0)
+		} else {
+			binary.LittleEndian.PutUint32(sequence, txIn.sequence)
+		}
+		txInput = append(txInput, sequence...)
+	}
+	txInsLen, _ := encoding.EncodeVarint(numInputs)
+
+	var txOutput []byte
+	numOutputs := 0
+	switch baseType {
+	case SIGHASH_NONE:
+	case SIGHASH_SINGLE:
+		// outputs before the one at inputIdx are blanked to value -1 and an
+		// empty script
+		numOutputs = int(inputIdx) + 1
+		for i := 0; i < int(inputIdx); i++ {
+			txOutput = append(txOutput, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00)
+		}
+		txOutput = append(txOutput, tx.txOuts[inputIdx].Serialize()...)
+	default:
+		numOutputs = len(tx.txOuts)
+		for _, txOut := range tx.txOuts {
+			txOutput = append(txOutput, txOut.Serialize()...)
+		}
+	}
+	txOutsLen, _ := encoding.EncodeVarint(numOutputs)
+
+	locktime := make([]byte, 4)
+	binary.LittleEndian.PutUint32(locktime, tx.locktime)
+
+	hashTypeBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(hashTypeBytes, hashType)
+
+	modifiedTxBytes := bytes.Join([][]byte{version, txInsLen, txInput, txOutsLen, txOutput, locktime, hashTypeBytes}, []byte{})
+
+	signatureHash := encoding.Hash256(modifiedTxBytes)
+
+	return new(big.Int).SetBytes(signatureHash[:])
+}
+
+// SignInput needs index of input to sign and signs it with private key passed.
+// P2WPKH outputs, natively or nested in P2SH, get a witness signed with the
+// BIP 143 digest and anything else is signed as P2PKH. hashType is appended
+// to the signature and selects what it commits to
+func (tx *Tx) SignInput(inputIdx uint32, privKey *ecc.PrivateKey, hashType uint32) (bool, error) {
+	if int(inputIdx) >= len(tx.txIns) {
+		return false, fmt.Errorf("input %d out of range", inputIdx)
+	}
+	txIn := &tx.txIns[inputIdx]
+	scriptPubKey, err := txIn.ScriptPubKey(tx.testnet)
+	if err != nil {
+		return false, err
+	}
+
+	sec := privKey.PublicKey().Sec(true)
+	pubKeyHash := encoding.Hash160(sec)
+	redeemScript := script.P2WPKHScript(pubKeyHash)
+	nested := scriptPubKey.IsP2SH() && bytes.Equal(encoding.Hash160(redeemScript.RawSerialize()), scriptPubKey.Cmds()[1])
+
+	// get the signature hash (z)
+	var z *big.Int
+	if scriptPubKey.IsP2WPKH() || nested {
+		amount, err := txIn.Value(tx.testnet)
+		if err != nil {
+			return false, err
+		}
+		z, err = tx.SigHashBIP143(inputIdx, script.P2PKHScript(pubKeyHash).RawSerialize(), amount, hashType)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		z, err = tx.SigHash(inputIdx, hashType)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// sign z with private key
+	sig := privKey.Sign(z).Der()
+
+	// signature is the der signature + hash type
+	sig = append(sig, byte(hashType))
+
+	switch {
+	case scriptPubKey.IsP2WPKH():
+		txIn.scriptSig = script.NewScript(nil)
+		txIn.witness = [][]byte{sig, sec}
+	case nested:
+		txIn.scriptSig = script.NewScript([][]byte{redeemScript.RawSerialize()})
+		txIn.witness = [][]byte{sig, sec}
+	default:
+		txIn.scriptSig = script.NewScript([][]byte{sig, sec})
+	}
+
+	// verify tx input signed is valid
+	return tx.VerifyInput(inputIdx)
+}
+
+// verifyFlags are the script rules inputs are verified with
+const verifyFlags = script.MANDATORY_SCRIPT_VERIFY_FLAGS
+
+// VerifyInput verifies the input at inputIdx against the scriptPubKey it
+// spends under the consensus rules, running P2SH redeem scripts, segwit v0
+// witnesses with the BIP 143 digest and taproot witnesses with BIP 341/342
+func (tx Tx) VerifyInput(inputIdx uint32) (bool, error) {
+	return tx.evalInput(inputIdx, verifyFlags, nil)
+}
+
+// VerifyInputFlags verifies the input at inputIdx enforcing flags, e.g.
+// script.STANDARD_SCRIPT_VERIFY_FLAGS to check relay policy
+func (tx Tx) VerifyInputFlags(inputIdx uint32, flags script.VerifyFlags) (bool, error) {
+	return tx.evalInput(inputIdx, flags, nil)
+}
+
+// evalInput runs the scripts of the input at inputIdx. With a batch the
+// signatures are queued on it instead of checked right away
+func (tx Tx) evalInput(inputIdx uint32, flags script.VerifyFlags, batch *ecc.BatchVerifier) (bool, error) {
+	if int(inputIdx) >= len(tx.txIns) {
+		return false, fmt.Errorf("input %d out of range", inputIdx)
+	}
+	txIn := tx.txIns[inputIdx]
+	prevOut, err := txIn.prevTxOut(tx.testnet)
+	if err != nil {
+		return false, err
+	}
+	input := tx.inputContext(inputIdx, prevOut.value)
+	var valid bool
+	if batch != nil {
+		valid, err = script.VerifyScriptBatch(txIn.scriptSig, prevOut.scriptPubKey, txIn.witness, input, flags, batch)
+	} else {
+		valid, err = script.VerifyScript(txIn.scriptSig, prevOut.scriptPubKey, txIn.witness, input, flags)
+	}
+	if err != nil {
+		return false, fmt.Errorf("error evaluating script: %w", err)
+	}
+	return valid, nil
+}
+
+// inputContext returns what the interpreter needs to know about the input at
+// inputIdx, spending an output of amount: the signature hashes it commits to
+// and the fields the timelock opcodes check
+func (tx Tx) inputContext(inputIdx uint32, amount uint64) *script.InputContext {
+	return &script.InputContext{
+		SigHash: func(hashType byte, scriptCode []byte, sigVersion script.SigVersion) (*big.Int, error) {
+			if sigVersion == script.SIGVERSION_WITNESS_V0 {
+				return tx.SigHashBIP143(inputIdx, scriptCode, amount, uint32(hashType))
+			}
+			return tx.legacySigHash(inputIdx, scriptCode, uint32(hashType)), nil
+		},
+		TapSigHash: func(hashType byte, annex, leafHash []byte, codeSepPos uint32) ([]byte, error) {
+			return tx.SigHashTaproot(inputIdx, hashType, annex, leafHash, codeSepPos)
+		},
+		Version:  tx.version,
+		LockTime: tx.locktime,
+		Sequence: tx.txIns[inputIdx].sequence,
+	}
+}
+
+// Verify checks the fee and every input of the transaction. Signatures are
+// checked together on a BatchVerifier
+func (tx Tx) Verify() (bool, error) {
+	batch := ecc.NewBatchVerifier(0, false)
+	valid, err := tx.VerifyBatch(batch)
+	if err == nil && valid && batch.Verify() {
+		return true, nil
+	}
+	// the batch pass takes every signature as valid, so a script that needs
+	// one to fail (e.g. CHECKSIG followed by NOT) fails there. Whatever failed,
+	// evaluate the inputs again checking each signature right away
+	return tx.verifyInputs(nil)
+}
+
+// VerifyBatch checks the fee and evaluates every input, queueing the
+// signatures on batch instead of checking them. The transaction is only valid
+// if batch.Verify() succeeds too, and a failure isn't final either because
+// every queued signature is taken as valid: run Verify for the exact answer
+func (tx Tx) VerifyBatch(batch *ecc.BatchVerifier) (bool, error) {
+	return tx.verifyInputs(batch)
+}
+
+func (tx Tx) verifyInputs(batch *ecc.BatchVerifier) (bool, error) {
+	// this is not here but while verifying a transaction, it should also
+	// check for double spends (check if the tx is in the UTXO set)
+
+	fee, err := tx.Fee()
+	if err != nil {
+		return false, err
+	}
+	if fee < 0 {
+		return false, nil
+	}
+	for i := range tx.txIns {
+		valid, err := tx.evalInput(uint32(i), verifyFlags, batch)
+		if err != nil || !valid {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Fee = sum(inputs) - sum(outputs)
+func (tx Tx) Fee() (int64, error) {
+	var inputSum, outputSum uint64
+
+	for _, input := range tx.txIns {
+		value, err := input.Value(tx.testnet)
+		if err != nil {
+			return 0, err
+		}
+		inputSum += value
+	}
+
+	for _, output := range tx.txOuts {
+		outputSum += output.value
+	}
+	return int64(inputSum) - int64(outputSum), nil
+}
+
+// TxIn is a transaction input
+type TxIn struct {
+	prevTxId  [32]byte       // hash of previous referenced transaction
+	prevTxIdx uint32         // index of output from referenced transaction
+	scriptSig *script.Script // script to unlock utxo and spend
+	sequence  uint32
+	witness   [][]byte // witness stack, empty for non segwit inputs
+}
+
+// NewTxIn returns an input spending output prevTxIdx of prevTx. A nil
+// scriptSig is replaced by an empty script
+func NewTxIn(prevTx [32]byte, prevTxIdx uint32, scriptSig *script.Script, sequence uint32) *TxIn {
+	var s *script.Script
+	if scriptSig == nil {
+		s = script.NewScript(nil)
+	} else {
+		s = scriptSig
+	}
+
+	return &TxIn{prevTxId: prevTx, prevTxIdx: prevTxIdx, scriptSig: s, sequence: sequence}
+}
+
+func (tx TxIn) PrevTxID() [32]byte {
+	return tx.prevTxId
+}
+
+func (tx TxIn) PrevTxIdx() uint32 {
+	return tx.prevTxIdx
+}
+
+func (tx TxIn) ScriptSig() *script.Script {
+	return tx.scriptSig
+}
+
+func (tx TxIn) Sequence() uint32 {
+	return tx.sequence
+}
+
+func (tx TxIn) Witness() [][]byte {
+	return tx.witness
+}
+
+// SetWitness sets the witness stack of the input
+func (tx *TxIn) SetWitness(witness [][]byte) {
+	tx.witness = witness
+}
+
+func parseTxIn(txHex io.Reader) (*TxIn, error) {
+	// read 32 bytes - transactionId of previous tx
+	var tx [32]byte
+	_, err := io.ReadFull(txHex, tx[:])
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tx input: %v", err)
+	}
+
+	// reversing because incoming prev tx hash is in little endian
+	prevTx := encoding.ReverseByteArr32(tx)
+
+	// 4 bytes for index of previous tx - utxo being spent
+	txIdxbuf := make([]byte, 4)
+	_, err = io.ReadFull(txHex, txIdxbuf)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tx input: %v", err)
+	}
+	txIdx := binary.LittleEndian.Uint32(txIdxbuf)
+
+	// parses scriptSig
+	scriptSig, err := script.ParseScript(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing scriptSig: %v", err)
+	}
+
+	// 4 bytes for sequence
+	sequencebuf := make([]byte, 4)
+	_, err = io.ReadFull(txHex, sequencebuf)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tx input: %v", err)
+	}
+	sequence := binary.LittleEndian.Uint32(sequencebuf)
+
+	return &TxIn{prevTxId: prevTx, prevTxIdx: txIdx, scriptSig: scriptSig, sequence: sequence}, nil
+}
+
+// Serialize serializes the input
+func (tx TxIn) Serialize() []byte {
+	scriptSig := tx.scriptSig.Serialize()
+
+	sequence := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sequence, tx.sequence)
+
+	return bytes.Join([][]byte{tx.outpoint(), scriptSig, sequence}, []byte{})
+}
+
+// outpoint is the serialized previous tx hash and output index
+func (tx TxIn) outpoint() []byte {
+	prevTxId := encoding.ReverseByteArr32(tx.prevTxId)
+
+	prevTxIdx := make([]byte, 4)
+	binary.LittleEndian.PutUint32(prevTxIdx, tx.prevTxIdx)
+
+	return append(prevTxId[:], prevTxIdx...)
+}
+
+// FetchTx fetches the previous transaction referenced by the input
+func (tx TxIn) FetchTx(testnet bool) (*Tx, error) {
+	return Fetch(hex.EncodeToString(tx.prevTxId[:]), testnet)
+}
+
+func (tx TxIn) prevTxOut(testnet bool) (*TxOut, error) {
+	t, err := tx.FetchTx(testnet)
+	if err != nil {
+		return nil, err
+	}
+	if int(tx.prevTxIdx) >= len(t.txOuts) {
+		return nil, fmt.Errorf("output %d not found in %x", tx.prevTxIdx, tx.prevTxId)
+	}
+	return &t.txOuts[tx.prevTxIdx], nil
+}
+
+// Value gets amount of utxo being spent
+func (tx TxIn) Value(testnet bool) (uint64, error) {
+	txOut, err := tx.prevTxOut(testnet)
+	if err != nil {
+		return 0, err
+	}
+	return txOut.value, nil
+}
+
+// ScriptPubKey gets scriptPubKey of the previous tx being referenced in the input
+func (tx TxIn) ScriptPubKey(testnet bool) (*script.Script, error) {
+	txOut, err := tx.prevTxOut(testnet)
+	if err != nil {
+		return nil, err
+	}
+	return txOut.scriptPubKey, nil
+}
+
+// TxOut is a transaction output
+type TxOut struct {
+	value        uint64         // amount in satoshis being transferred
+	scriptPubKey *script.Script // locking script
+}
+
+// NewTxOut returns an output locking value satoshis to scriptPubKey
+func NewTxOut(value uint64, scriptPubKey *script.Script) *TxOut {
+	return &TxOut{value: value, scriptPubKey: scriptPubKey}
+}
+
+func (tx TxOut) Value() uint64 {
+	return tx.value
+}
+
+func (tx TxOut) ScriptPubKey() *script.Script {
+	return tx.scriptPubKey
+}
+
+func parseTxOut(txHex io.Reader) (*TxOut, error) {
+	// parse amount (# is in satoshis) - amount is in little endian stored in 8 bytes
+	amountbuf := make([]byte, 8)
+	_, err := io.ReadFull(txHex, amountbuf)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing tx output: %v", err)
+	}
+	amount := binary.LittleEndian.Uint64(amountbuf)
+
+	scriptPubKey, err := script.ParseScript(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing scriptPubKey: %v", err)
+	}
+
+	return &TxOut{value: amount, scriptPubKey: scriptPubKey}, nil
+}
+
+// Serialize serializes the output
+func (tx TxOut) Serialize() []byte {
+	amount := make([]byte, 8)
+	binary.LittleEndian.PutUint64(amount, tx.value)
+
+	script := tx.scriptPubKey.Serialize()
+	return bytes.Join([][]byte{amount, script}, []byte{})
+}
+
+var txCache map[string]*Tx = map[string]*Tx{}
+
+// Fetch gets a transaction by id from blockstream.info, caching the result
+func Fetch(txId string, testnet bool) (*Tx, error) {
+	// get correct url
+	url := "https://blockstream.info/api/"
+	if testnet {
+		url = "https://blockstream.info/testnet/api/"
+	}
+
+	// if tx is not in cache, fetch it
+	_, ok := txCache[txId]
+	if !ok {
+		url += "tx/" + txId + "/hex"
+		resp, err := http.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		decodedBody, err := hex.DecodeString(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("error getting transaction: %v", err)
+		}
+		if len(decodedBody) < 10 {
+			return nil, errors.New("error getting transaction: response too short")
+		}
+
+		tx, err := ParseTx(decodedBody)
+		if err != nil {
+			return nil, err
+		}
+
+		tid := hex.EncodeToString(tx.ID())
+		if tid != txId {
+			return nil, fmt.Errorf("transaction ids do not match: %v and %v", tid, txId)
+		}
+
+		txCache[txId] = tx
+	}
+	txCache[txId].testnet = testnet
+	return txCache[txId], nil
+}
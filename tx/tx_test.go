@@ -0,0 +1,473 @@
+package tx
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/url"
+	"testing"
+
+	"github.com/miguelhun/programmingbitcoin-go/ecc"
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+	"github.com/miguelhun/programmingbitcoin-go/script"
+	"github.com/stretchr/testify/assert"
+)
+
+// skipIfOffline skips tests that need to fetch transactions from
+// blockstream.info when there is no network access
+func skipIfOffline(t *testing.T, err error) {
+	t.Helper()
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		t.Skipf("skipping, could not fetch transaction: %v", err)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	txHex, err := hex.DecodeString("0100000001813f79011acb80925dfe69b3def355fe914bd1d96a3f5f71bf8303c6a989c7d1000000006b483045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed01210349fc4e631e3624a545de3f89f5d8684c7b8138bd94bdd531d2e213bf016b278afeffffff02a135ef01000000001976a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac99c39800000000001976a9141c4bc762dd5423e332166702cb75f40df79fea1288ac19430600")
+	if err != nil {
+		t.Errorf("error decoding tx hex: %v\n", err)
+	}
+	tx, err := ParseTx(txHex)
+	if err != nil {
+		t.Fatalf("error parsing tx: %v", err)
+	}
+	assert.Equal(t, uint32(1), tx.version)
+}
+
+func TestParseTxInput(t *testing.T) {
+	txHex, err := hex.DecodeString("0100000001813f79011acb80925dfe69b3def355fe914bd1d96a3f5f71bf8303c6a989c7d1000000006b483045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed01210349fc4e631e3624a545de3f89f5d8684c7b8138bd94bdd531d2e213bf016b278afeffffff02a135ef01000000001976a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac99c39800000000001976a9141c4bc762dd5423e332166702cb75f40df79fea1288ac19430600")
+	if err != nil {
+		t.Errorf("error decoding tx hex: %v\n", err)
+	}
+
+	tx, err := ParseTx(txHex)
+	if err != nil {
+		t.Fatalf("error parsing tx: %v", err)
+	}
+
+	assert.Equal(t, 1, len(tx.txIns), "unexpected TxIns length")
+
+	want, _ := hex.DecodeString("d1c789a9c60383bf715f3f6ad9d14b91fe55f3deb369fe5d9280cb1a01793f81")
+	assert.Equal(t, want, tx.txIns[0].prevTxId[:], "tx in bytes do not match")
+	assert.Equal(t, uint32(0), tx.txIns[0].prevTxIdx, "tx idxs do not match")
+
+	want, _ = hex.DecodeString("6b483045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed01210349fc4e631e3624a545de3f89f5d8684c7b8138bd94bdd531d2e213bf016b278a")
+	assert.Equal(t, want, tx.txIns[0].scriptSig.Serialize(), "scriptSig does not match")
+	assert.Equal(t, uint32(0xfffffffe), tx.txIns[0].sequence, "scriptSig does not match")
+}
+
+func TestParseTxOutput(t *testing.T) {
+	txHex, err := hex.DecodeString("0100000001813f79011acb80925dfe69b3def355fe914bd1d96a3f5f71bf8303c6a989c7d1000000006b483045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed01210349fc4e631e3624a545de3f89f5d8684c7b8138bd94bdd531d2e213bf016b278afeffffff02a135ef01000000001976a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac99c39800000000001976a9141c4bc762dd5423e332166702cb75f40df79fea1288ac19430600")
+	if err != nil {
+		t.Errorf("error decoding tx hex: %v\n", err)
+	}
+
+	tx, err := ParseTx(txHex)
+	if err != nil {
+		t.Fatalf("error parsing tx: %v", err)
+	}
+
+	assert.Equal(t, 2, len(tx.txOuts), "unexpected TxOuts length")
+	var want uint64 = 32454049
+	assert.Equal(t, want, tx.txOuts[0].value, "txOut amount does not match")
+
+	pubKeyWant, _ := hex.DecodeString("1976a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac")
+	assert.Equal(t, pubKeyWant, tx.txOuts[0].scriptPubKey.Serialize(), "public key do not match")
+
+	want = 10011545
+	assert.Equal(t, want, tx.txOuts[1].value, "txOut amount does not match")
+
+	pubKeyWant, _ = hex.DecodeString("1976a9141c4bc762dd5423e332166702cb75f40df79fea1288ac")
+	assert.Equal(t, pubKeyWant, tx.txOuts[1].scriptPubKey.Serialize(), "public key do not match")
+}
+
+func TestParseLocktime(t *testing.T) {
+	txHex, err := hex.DecodeString("0100000001813f79011acb80925dfe69b3def355fe914bd1d96a3f5f71bf8303c6a989c7d1000000006b483045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed01210349fc4e631e3624a545de3f89f5d8684c7b8138bd94bdd531d2e213bf016b278afeffffff02a135ef01000000001976a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac99c39800000000001976a9141c4bc762dd5423e332166702cb75f40df79fea1288ac19430600")
+	if err != nil {
+		t.Errorf("error decoding tx hex: %v\n", err)
+	}
+	tx, err := ParseTx(txHex)
+	if err != nil {
+		t.Fatalf("error parsing tx: %v", err)
+	}
+	assert.Equal(t, uint32(410393), tx.locktime)
+}
+
+func TestSerialize(t *testing.T) {
+	txHex, err := hex.DecodeString("0100000001813f79011acb80925dfe69b3def355fe914bd1d96a3f5f71bf8303c6a989c7d1000000006b483045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed01210349fc4e631e3624a545de3f89f5d8684c7b8138bd94bdd531d2e213bf016b278afeffffff02a135ef01000000001976a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac99c39800000000001976a9141c4bc762dd5423e332166702cb75f40df79fea1288ac19430600")
+	if err != nil {
+		t.Errorf("error decoding tx hex: %v\n", err)
+	}
+
+	tx, err := ParseTx(txHex)
+	if err != nil {
+		t.Fatalf("error parsing tx: %v", err)
+	}
+	assert.Equal(t, txHex, tx.Serialize(), "hex value of serialize does not match")
+}
+
+func TestParseBadScriptLength(t *testing.T) {
+	// version, one input and its outpoint, up to the scriptSig length
+	prefix := "01000000" + "01" + "813f79011acb80925dfe69b3def355fe914bd1d96a3f5f71bf8303c6a989c7d1" + "00000000"
+	scriptSig := "483045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed01210349fc4e631e3624a545de3f89f5d8684c7b8138bd94bdd531d2e213bf016b278a"
+
+	cases := []string{
+		// a length that doesn't fit in an int
+		prefix + "ffffffffffffffffff" + scriptSig,
+		// 4 GB
+		prefix + "feffffffff" + scriptSig,
+		// just over MAX_SIZE
+		prefix + "fe01000002" + scriptSig,
+		// truncated
+		prefix + "6b" + scriptSig[:100],
+	}
+	for _, test := range cases {
+		txBytes, _ := hex.DecodeString(test)
+		if _, err := ParseTx(txBytes); err == nil {
+			t.Errorf("expected error parsing '%v'", test)
+		}
+	}
+}
+
+func TestParseSegwit(t *testing.T) {
+	legacyHex := "02000000" +
+		"01" + "0fa8b5d2b2a7acf4b1e4b3a6e3e33d7c6a6e1f5d0ea5d6b0d8b3f7e4a1e2c3d4" + "01000000" + "00" + "fdffffff" +
+		"01" + "a086010000000000" + "160014" + "1d0f172a0ecb48aee1be1f2687d2963ae33f71a1" +
+		"00000000"
+	witness := "02" +
+		"47" + "304402203609e17b84f6a7d30c80bfa610b5b4542f32a8a0d5447a12fb1344d2ed3e0102203f5f5a5e1d2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f80910a0b0c0d01" +
+		"21" + "025476c2e83188368da1ff3e292e7acafcdb3566bb0ad253f62fc70f07aeeb6357"
+	segwitHex := legacyHex[:8] + "0001" + legacyHex[8:len(legacyHex)-8] + witness + legacyHex[len(legacyHex)-8:]
+
+	txBytes, _ := hex.DecodeString(segwitHex)
+	tx, err := ParseTx(txBytes)
+	if err != nil {
+		t.Fatalf("error parsing tx: %v", err)
+	}
+
+	assert.True(t, tx.HasWitness())
+	assert.Equal(t, 2, len(tx.txIns[0].witness), "unexpected witness length")
+	assert.Equal(t, 71, len(tx.txIns[0].witness[0]))
+	assert.Equal(t, uint64(100000), tx.txOuts[0].value)
+	assert.Equal(t, txBytes, tx.Serialize(), "segwit serialization does not match")
+
+	legacyBytes, _ := hex.DecodeString(legacyHex)
+	assert.Equal(t, legacyBytes, tx.SerializeLegacy(), "legacy serialization does not match")
+
+	txid := encoding.Hash256(legacyBytes)
+	assert.Equal(t, encoding.Reverse(txid[:]), tx.ID(), "txid does not match")
+	wtxid := encoding.Hash256(txBytes)
+	assert.Equal(t, encoding.Reverse(wtxid[:]), tx.WitnessID(), "wtxid does not match")
+
+	// without a witness the tx serializes as legacy and both ids agree
+	tx.txIns[0].SetWitness(nil)
+	assert.False(t, tx.HasWitness())
+	assert.Equal(t, legacyBytes, tx.Serialize())
+	assert.Equal(t, tx.ID(), tx.WitnessID())
+
+	invalid := []string{
+		// marker and flag but every witness empty
+		legacyHex[:8] + "0001" + legacyHex[8:len(legacyHex)-8] + "00" + legacyHex[len(legacyHex)-8:],
+		// unknown flag
+		legacyHex[:8] + "0002" + legacyHex[8:],
+		// witness cut short
+		segwitHex[:len(segwitHex)-40],
+		// witness item length that doesn't fit in an int
+		legacyHex[:8] + "0001" + legacyHex[8:len(legacyHex)-8] + "01" + "ffffffffffffffffff" + legacyHex[len(legacyHex)-8:],
+		// 4 GB witness item
+		legacyHex[:8] + "0001" + legacyHex[8:len(legacyHex)-8] + "01" + "feffffffff" + legacyHex[len(legacyHex)-8:],
+		// witness item count that doesn't fit in an int
+		legacyHex[:8] + "0001" + legacyHex[8:len(legacyHex)-8] + "ffffffffffffffffff" + legacyHex[len(legacyHex)-8:],
+		// witness item count over MAX_SIZE
+		legacyHex[:8] + "0001" + legacyHex[8:len(legacyHex)-8] + "fe01000002" + legacyHex[len(legacyHex)-8:],
+	}
+	for _, test := range invalid {
+		txBytes, _ := hex.DecodeString(test)
+		if _, err := ParseTx(txBytes); err == nil {
+			t.Errorf("expected error parsing '%v'", test)
+		}
+	}
+}
+
+func TestTxInputValue(t *testing.T) {
+	var txHashHex [32]byte
+	tx, err := hex.DecodeString("d1c789a9c60383bf715f3f6ad9d14b91fe55f3deb369fe5d9280cb1a01793f81")
+	if err != nil {
+		t.Errorf("error decoding tx hash: %v\n", err)
+	}
+	copy(txHashHex[:], tx)
+
+	var idx uint32 = 0
+	var want uint64 = 42505594
+
+	txIn := NewTxIn(txHashHex, idx, nil, uint32(0xfffffffe))
+	value, err := txIn.Value(false)
+	skipIfOffline(t, err)
+	assert.NoError(t, err)
+	assert.Equal(t, want, value)
+}
+
+func TestInputPubKey(t *testing.T) {
+	var txHashHex [32]byte
+	tx, err := hex.DecodeString("d1c789a9c60383bf715f3f6ad9d14b91fe55f3deb369fe5d9280cb1a01793f81")
+	if err != nil {
+		t.Errorf("error decoding tx hash: %v\n", err)
+	}
+	copy(txHashHex[:], tx)
+
+	var idx uint32 = 0
+	txIn := NewTxIn(txHashHex, idx, nil, uint32(0xfffffffe))
+
+	want, err := hex.DecodeString("1976a914a802fc56c704ce87c42d7c92eb75e7896bdc41ae88ac")
+	if err != nil {
+		t.Errorf("error decoding expected value: %v\n", err)
+	}
+	scriptPubKey, err := txIn.ScriptPubKey(false)
+	skipIfOffline(t, err)
+	if err != nil {
+		t.Fatalf("error getting scriptPubKey: %v", err)
+	}
+	assert.Equal(t, want, scriptPubKey.Serialize(), "scriptPubKey do not match")
+}
+
+func TestFee(t *testing.T) {
+	testCases := []struct {
+		rawTx string
+		want  int64
+	}{
+		{"0100000001813f79011acb80925dfe69b3def355fe914bd1d96a3f5f71bf8303c6a989c7d1000000006b483045022100ed81ff192e75a3fd2304004dcadb746fa5e24c5031ccfcf21320b0277457c98f02207a986d955c6e0cb35d446a89d3f56100f4d7f67801c31967743a9c8e10615bed01210349fc4e631e3624a545de3f89f5d8684c7b8138bd94bdd531d2e213bf016b278afeffffff02a135ef01000000001976a914bc3b654dca7e56b04dca18f2566cdaf02e8d9ada88ac99c39800000000001976a9141c4bc762dd5423e332166702cb75f40df79fea1288ac19430600", 40000},
+		{"010000000456919960ac691763688d3d3bcea9ad6ecaf875df5339e148a1fc61c6ed7a069e010000006a47304402204585bcdef85e6b1c6af5c2669d4830ff86e42dd205c0e089bc2a821657e951c002201024a10366077f87d6bce1f7100ad8cfa8a064b39d4e8fe4ea13a7b71aa8180f012102f0da57e85eec2934a82a585ea337ce2f4998b50ae699dd79f5880e253dafafb7feffffffeb8f51f4038dc17e6313cf831d4f02281c2a468bde0fafd37f1bf882729e7fd3000000006a47304402207899531a52d59a6de200179928ca900254a36b8dff8bb75f5f5d71b1cdc26125022008b422690b8461cb52c3cc30330b23d574351872b7c361e9aae3649071c1a7160121035d5c93d9ac96881f19ba1f686f15f009ded7c62efe85a872e6a19b43c15a2937feffffff567bf40595119d1bb8a3037c356efd56170b64cbcc160fb028fa10704b45d775000000006a47304402204c7c7818424c7f7911da6cddc59655a70af1cb5eaf17c69dadbfc74ffa0b662f02207599e08bc8023693ad4e9527dc42c34210f7a7d1d1ddfc8492b654a11e7620a0012102158b46fbdff65d0172b7989aec8850aa0dae49abfb84c81ae6e5b251a58ace5cfeffffffd63a5e6c16e620f86f375925b21cabaf736c779f88fd04dcad51d26690f7f345010000006a47304402200633ea0d3314bea0d95b3cd8dadb2ef79ea8331ffe1e61f762c0f6daea0fabde022029f23b3e9c30f080446150b23852028751635dcee2be669c2a1686a4b5edf304012103ffd6f4a67e94aba353a00882e563ff2722eb4cff0ad6006e86ee20dfe7520d55feffffff0251430f00000000001976a914ab0c0b2e98b1ab6dbf67d4750b0a56244948a87988ac005a6202000000001976a9143c82d7df364eb6c75be8c80df2b3eda8db57397088ac46430600", 140500},
+	}
+
+	for _, test := range testCases {
+		txHex, err := hex.DecodeString(test.rawTx)
+		if err != nil {
+			t.Errorf("error decoding tx hex: %v\n", err)
+		}
+
+		tx, err := ParseTx(txHex)
+		if err != nil {
+			t.Fatalf("error parsing tx: %v", err)
+		}
+		fee, err := tx.Fee()
+		skipIfOffline(t, err)
+		if err != nil {
+			t.Fatalf("error getting fee: %v", err)
+		}
+		if fee != test.want {
+			t.Errorf("expected %v but got %v instead", test.want, fee)
+		}
+	}
+}
+
+func TestSigHash(t *testing.T) {
+	tx, err := Fetch("452c629d67e41baec3ac6f04fe744b4b9617f8f859c63b3002f8684e7a4fee03", false)
+	skipIfOffline(t, err)
+	if err != nil {
+		t.Fatalf("error fetching transaction: %v", err)
+	}
+
+	want := encoding.FromHex("27e0c5994dec7824e56dec6b2fcb342eb7cdb0d0957c2fce9882f715e85d81a6")
+	z, err := tx.SigHash(0, SIGHASH_ALL)
+	skipIfOffline(t, err)
+	assert.NoError(t, err)
+	assert.Equal(t, want, z, "signature hash does not match")
+}
+
+func TestLegacySigHashTypes(t *testing.T) {
+	scriptCode := []byte{0x51}
+	txIns := []TxIn{
+		*NewTxIn([32]byte{1}, 0, nil, 0xffffffff),
+		*NewTxIn([32]byte{2}, 1, nil, 0xfffffffe),
+	}
+	txOuts := []TxOut{
+		*NewTxOut(1000, script.NewScript([][]byte{{0x51}})),
+		*NewTxOut(2000, script.NewScript([][]byte{{0x52}})),
+	}
+	tx := NewTx(1, txIns, txOuts, 0, false)
+
+	// same tx with the other input's sequence changed, an extra input and a
+	// different first output
+	changedIns := []TxIn{txIns[0], txIns[1], *NewTxIn([32]byte{3}, 2, nil, 0)}
+	changedIns[0].sequence = 7
+	changedOuts := []TxOut{*NewTxOut(999, script.NewScript(nil)), txOuts[1]}
+	otherInputs := NewTx(1, changedIns, txOuts, 0, false)
+	otherOutputs := NewTx(1, txIns, changedOuts, 0, false)
+
+	testCases := []struct {
+		hashType         uint32
+		sameOtherInputs  bool
+		sameOtherOutputs bool
+	}{
+		{SIGHASH_ALL, false, false},
+		{SIGHASH_NONE, false, true},
+		{SIGHASH_SINGLE, false, true},
+		{SIGHASH_ALL | SIGHASH_ANYONECANPAY, true, false},
+		{SIGHASH_NONE | SIGHASH_ANYONECANPAY, true, true},
+		{SIGHASH_SINGLE | SIGHASH_ANYONECANPAY, true, true},
+	}
+
+	for _, test := range testCases {
+		z := tx.legacySigHash(1, scriptCode, test.hashType)
+		if got := z.Cmp(otherInputs.legacySigHash(1, scriptCode, test.hashType)) == 0; got != test.sameOtherInputs {
+			t.Errorf("hash type %#x: expected other inputs to change digest: %v", test.hashType, !test.sameOtherInputs)
+		}
+		if got := z.Cmp(otherOutputs.legacySigHash(1, scriptCode, test.hashType)) == 0; got != test.sameOtherOutputs {
+			t.Errorf("hash type %#x: expected other outputs to change digest: %v", test.hashType, !test.sameOtherOutputs)
+		}
+	}
+
+	// SIGHASH_SINGLE without a matching output signs the uint256 1
+	single := NewTx(1, txIns, txOuts[:1], 0, false)
+	one := make([]byte, 32)
+	one[0] = 0x01
+	assert.Equal(t, new(big.Int).SetBytes(one), single.legacySigHash(1, scriptCode, SIGHASH_SINGLE))
+}
+
+func TestSignInputHashTypes(t *testing.T) {
+	privKey := ecc.NewPrivateKey(big.NewInt(271828))
+	pubKeyHash := encoding.Hash160(privKey.PublicKey().Sec(true))
+	hashTypes := []uint32{
+		SIGHASH_ALL, SIGHASH_NONE, SIGHASH_SINGLE,
+		SIGHASH_ALL | SIGHASH_ANYONECANPAY, SIGHASH_NONE | SIGHASH_ANYONECANPAY, SIGHASH_SINGLE | SIGHASH_ANYONECANPAY,
+	}
+
+	for _, scriptPubKey := range []*script.Script{script.P2PKHScript(pubKeyHash), script.P2WPKHScript(pubKeyHash)} {
+		for _, hashType := range hashTypes {
+			prevTxId := fakePrevTx(10000, scriptPubKey)
+			txIn := NewTxIn(prevTxId, 0, nil, 0xffffffff)
+			tx := NewTx(1, []TxIn{*txIn}, []TxOut{*NewTxOut(9000, scriptPubKey)}, 0, false)
+			valid, err := tx.SignInput(0, privKey, hashType)
+			if err != nil || !valid {
+				t.Errorf("hash type %#x: expected signed input to verify, got '%v' '%v'", hashType, valid, err)
+			}
+
+			// the hash type byte of the signature picks the digest
+			// checked, so signing with one type and relabelling it fails
+			sig := tx.txIns[0].witness
+			if len(sig) == 0 {
+				sig = tx.txIns[0].scriptSig.Cmds()
+			}
+			sig[0][len(sig[0])-1] ^= SIGHASH_ANYONECANPAY
+			valid, _ = tx.VerifyInput(0)
+			assert.False(t, valid)
+		}
+	}
+
+	// signing an input the tx doesn't have fails
+	tx := NewTx(1, []TxIn{*NewTxIn(fakePrevTx(10000, script.P2PKHScript(pubKeyHash)), 0, nil, 0xffffffff)}, nil, 0, false)
+	_, err := tx.SignInput(1, privKey, SIGHASH_ALL)
+	assert.Error(t, err)
+}
+
+func TestVerifyTrailingCodeSeparator(t *testing.T) {
+	privKey := ecc.NewPrivateKey(big.NewInt(161803))
+	sec := privKey.PublicKey().Sec(true)
+	// <pubkey> OP_CHECKSIG OP_CODESEPARATOR, which signs <pubkey> OP_CHECKSIG
+	scriptPubKey := script.NewScript([][]byte{sec, {0xac}, {0xab}})
+	scriptCode := script.NewScript([][]byte{sec, {0xac}}).RawSerialize()
+
+	prevTxId := fakePrevTx(10000, scriptPubKey)
+	txIn := NewTxIn(prevTxId, 0, nil, 0xffffffff)
+	tx := NewTx(1, []TxIn{*txIn}, []TxOut{*NewTxOut(9000, scriptPubKey)}, 0, false)
+	z := tx.legacySigHash(0, scriptCode, SIGHASH_ALL)
+	zPubKey, err := tx.SigHash(0, SIGHASH_ALL)
+	assert.NoError(t, err)
+	assert.Equal(t, z, zPubKey)
+
+	sig := append(privKey.Sign(z).Der(), SIGHASH_ALL)
+	tx.txIns[0].scriptSig = script.NewScript([][]byte{sig})
+	valid, err := tx.VerifyInput(0)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestVerifyFailingCheckSig(t *testing.T) {
+	privKey := ecc.NewPrivateKey(big.NewInt(314159))
+	sec := privKey.PublicKey().Sec(true)
+	checkSig := script.NewScript([][]byte{sec, {0xac}})
+	checkSigNot := script.NewScript([][]byte{sec, {0xac}, {0x91}})
+
+	testCases := []struct {
+		scriptPubKey *script.Script
+		goodSig      bool
+		valid        bool
+	}{
+		{checkSig, true, true},
+		{checkSig, false, false},
+		// the batch pass takes the bad signature as valid and fails, the
+		// transaction is only right after checking it for real
+		{checkSigNot, false, true},
+		{checkSigNot, true, false},
+	}
+
+	for _, test := range testCases {
+		prevTxId := fakePrevTx(10000, test.scriptPubKey)
+		txIn := NewTxIn(prevTxId, 0, nil, 0xffffffff)
+		tx := NewTx(1, []TxIn{*txIn}, []TxOut{*NewTxOut(9000, checkSig)}, 0, false)
+		z, err := tx.SigHash(0, SIGHASH_ALL)
+		assert.NoError(t, err)
+		if !test.goodSig {
+			z.Add(z, big.NewInt(1))
+		}
+		sig := append(privKey.Sign(z).Der(), SIGHASH_ALL)
+		tx.txIns[0].scriptSig = script.NewScript([][]byte{sig})
+
+		valid, _ := tx.Verify()
+		if valid != test.valid {
+			t.Errorf("%s with good signature %v: expected '%v' but got '%v' instead", test.scriptPubKey, test.goodSig, test.valid, valid)
+		}
+	}
+}
+
+func TestIsCoinbase(t *testing.T) {
+	rawTx, err := hex.DecodeString("01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff5e03d71b07254d696e656420627920416e74506f6f6c20626a31312f4542312f4144362f43205914293101fabe6d6d678e2c8c34afc36896e7d9402824ed38e856676ee94bfdb0c6c4bcd8b2e5666a0400000000000000c7270000a5e00e00ffffffff01faf20b58000000001976a914338c84849423992471bffb1a54a8d9b1d69dc28a88ac00000000")
+	if err != nil {
+		t.Error("error decoding raw tx")
+	}
+	tx, err := ParseTx(rawTx)
+	if err != nil {
+		t.Fatalf("error parsing tx: %v", err)
+	}
+	assert.True(t, tx.IsCoinbase(), "isCoinbase should be true")
+}
+
+// func TestVerifyP2PKH(t *testing.T) {
+// 	testCases := []struct {
+// 		txId    string
+// 		testnet bool
+// 		want    bool
+// 	}{
+// 		{"452c629d67e41baec3ac6f04fe744b4b9617f8f859c63b3002f8684e7a4fee03", false, true},
+// 		{"5418099cc755cb9dd3ebc6cf1a7888ad53a1a3beb5a025bce89eb1bf7f1650a2", true, true},
+// 	}
+
+// 	for _, test := range testCases {
+// 		tx, err := Fetch(test.txId, test.testnet)
+// 		if err != nil {
+// 			t.Error("error fetching transaction")
+// 		}
+// 		verified, _ := tx.Verify()
+// 		if verified != test.want {
+// 			t.Errorf("expected %v but got %v instead", test.want, verified)
+// 		}
+// 	}
+// }
+
+// func TestSignInput(t *testing.T) {
+// 	privKey := ecc.NewPrivateKey(big.NewInt(8675309))
+// 	txHex, err := hex.DecodeString("010000000199a24308080ab26e6fb65c4eccfadf76749bb5bfa8cb08f291320b3c21e56f0d0d00000000ffffffff02408af701000000001976a914d52ad7ca9b3d096a38e752c2018e6fbc40cdf26f88ac80969800000000001976a914507b27411ccf7f16f10297de6cef3f291623eddf88ac00000000")
+// 	if err != nil {
+// 		t.Errorf("error decoding tx hex: %v\n", err)
+// 	}
+// 	tx, _ := ParseTx(txHex)
+// 	tx.testnet = true
+// 	valid, _ := tx.SignInput(0, privKey, SIGHASH_ALL)
+// 	assert.Equal(t, true, valid)
+
+// 	// want := "010000000199a24308080ab26e6fb65c4eccfadf76749bb5bfa8cb08f291320b3c21e56f0d0d0000006b4830450221008ed46aa2cf12d6d81065bfabe903670165b538f65ee9a3385e6327d80c66d3b502203124f804410527497329ec4715e18558082d489b218677bd029e7fa306a72236012103935581e52c354cd2f484fe8ed83af7a3097005b2f9c60bff71d35bd795f54b67ffffffff02408af701000000001976a914d52ad7ca9b3d096a38e752c2018e6fbc40cdf26f88ac80969800000000001976a914507b27411ccf7f16f10297de6cef3f291623eddf88ac00000000"
+// 	// txString := hex.EncodeToString(tx.Serialize())
+
+// 	// assert.Equal(t, want, txString, "hex transactions do not match")
+// }
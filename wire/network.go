@@ -0,0 +1,110 @@
+// Package wire implements the envelope used by messages of the Bitcoin
+// peer-to-peer protocol.
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/miguelhun/programmingbitcoin-go/encoding"
+)
+
+// NetworkEnvelope wraps the payload of a network message
+type NetworkEnvelope struct {
+	magic   [4]byte
+	command [12]byte
+	payload []byte
+}
+
+var (
+	MAINNET_NETWORK_MAGIC = [4]byte{0xf9, 0xbe, 0xb4, 0xd9}
+	TESTNET_NETWORK_MAGIC = [4]byte{0x0b, 0x11, 0x09, 0x07}
+)
+
+// NewNetworkEnvelope returns an envelope with the magic of the selected network
+func NewNetworkEnvelope(command [12]byte, payload []byte, testnet bool) *NetworkEnvelope {
+	if testnet {
+		return &NetworkEnvelope{magic: TESTNET_NETWORK_MAGIC, command: command, payload: payload}
+	}
+	return &NetworkEnvelope{magic: MAINNET_NETWORK_MAGIC, command: command, payload: payload}
+}
+
+// ParseNetworkEnvelope parses a serialized envelope, checking the network
+// magic and the payload checksum
+func ParseNetworkEnvelope(b []byte, testnet bool) (*NetworkEnvelope, error) {
+	netbuffer := bytes.NewBuffer(b)
+
+	var buf [4]byte
+	_, err := io.ReadFull(netbuffer, buf[:])
+	if err != nil {
+		return nil, fmt.Errorf("error getting network magic: %v", err)
+	}
+	if testnet {
+		if buf != TESTNET_NETWORK_MAGIC {
+			return nil, errors.New("testnet network bytes do not match")
+		}
+	} else {
+		if buf != MAINNET_NETWORK_MAGIC {
+			return nil, errors.New("network bytes do not match")
+		}
+	}
+
+	var command [12]byte
+	_, err = io.ReadFull(netbuffer, command[:])
+	if err != nil {
+		return nil, fmt.Errorf("error getting command: %v", err)
+	}
+
+	// next 4 bytes to read payload length
+	_, err = io.ReadFull(netbuffer, buf[:])
+	if err != nil {
+		return nil, fmt.Errorf("error getting payload length: %v", err)
+	}
+	payloadLength := binary.LittleEndian.Uint32(buf[:])
+
+	// next 4 bytes are payload checksum
+	_, err = io.ReadFull(netbuffer, buf[:])
+	if err != nil {
+		return nil, fmt.Errorf("error getting payload checksum: %v", err)
+	}
+
+	payload := make([]byte, payloadLength)
+	_, err = io.ReadFull(netbuffer, payload)
+	if err != nil {
+		return nil, fmt.Errorf("error getting payload: %v", err)
+	}
+
+	payloadHash := encoding.Hash256(payload)
+	if !bytes.Equal(payloadHash[:4], buf[:]) {
+		return nil, errors.New("payload checksum does not match")
+	}
+
+	return NewNetworkEnvelope(command, payload, testnet), nil
+}
+
+// Command returns the command name without the zero padding
+func (n NetworkEnvelope) Command() string {
+	return string(bytes.TrimRight(n.command[:], "\x00"))
+}
+
+func (n NetworkEnvelope) Payload() []byte {
+	return n.payload
+}
+
+func (n NetworkEnvelope) Magic() [4]byte {
+	return n.magic
+}
+
+// Serialize serializes the envelope
+func (n NetworkEnvelope) Serialize() []byte {
+	payloadLength := make([]byte, 4)
+	binary.LittleEndian.PutUint32(payloadLength, uint32(len(n.payload)))
+
+	// first 4 bytes of hash is the checksum
+	hash := encoding.Hash256(n.payload)
+
+	return bytes.Join([][]byte{n.magic[:], n.command[:], payloadLength, hash[:4], n.payload}, []byte{})
+}